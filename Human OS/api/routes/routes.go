@@ -4,16 +4,20 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 
+	"humanos-api/internal/config"
 	"humanos-api/internal/database"
 	"humanos-api/internal/handlers"
 	"humanos-api/internal/middleware"
 	"humanos-api/internal/services"
 )
 
-// Setup configures all routes and middleware for the API
-func Setup(db *database.DB) *gin.Engine {
+// Setup configures all routes and middleware for the API. The offload queue
+// is created by the caller, which owns its lifecycle.
+func Setup(db *database.DB, cfg *config.Config, offloadService *services.OffloadService) *gin.Engine {
 	// Create Gin router
 	router := gin.New()
 
@@ -23,18 +27,52 @@ func Setup(db *database.DB) *gin.Engine {
 	router.Use(middleware.CORS())
 
 	// Create services
-	cognitiveService := services.NewCognitiveStateService(db)
+	ruminationGuard := services.NewRuminationGuard(db, services.RuminationPolicy{
+		MaxRunning: map[string]int{
+			"low":    cfg.MaxRunningLowPredictions,
+			"medium": cfg.MaxRunningMediumPredictions,
+			"deep":   cfg.MaxRunningDeepPredictions,
+		},
+		Budgets: map[string]time.Duration{
+			"low":    time.Duration(cfg.LowBudgetMinutes) * time.Minute,
+			"medium": time.Duration(cfg.MediumBudgetMinutes) * time.Minute,
+			"deep":   time.Duration(cfg.DeepBudgetMinutes) * time.Minute,
+		},
+		RestartThreshold: cfg.RuminationRestartThreshold,
+		Window:           time.Duration(cfg.RuminationWindowDays) * 24 * time.Hour,
+	})
+	emotionService := services.NewEmotionService(db, time.Duration(cfg.EmotionHalfLifeHours)*time.Hour)
+	assistService := services.NewAssistService(db, offloadService)
+	cognitiveService := services.NewCognitiveStateService(db, ruminationGuard, emotionService, assistService)
+	taskClassifier := services.NewTaskClassifier(db)
+	archiver := services.NewArchiver(db, cfg.AutoArchive)
+	lessonService := services.NewLessonService(db)
+	reportService := services.NewReportService(db, emotionService)
+	triggerService := services.NewTriggerService(db, emotionService)
+	// TimeZone was validated when the config was loaded
+	location, _ := time.LoadLocation(cfg.TimeZone)
+	recommendationService := services.NewRecommendationService(db, cognitiveService, emotionService, reportService, location)
 
 	// Create handlers
-	focusHandler := handlers.NewFocusHandler(db, cognitiveService)
-	loopHandler := handlers.NewLoopHandler(db)
-	threadHandler := handlers.NewThreadHandler(db)
-	ingestHandler := handlers.NewIngestHandler(db)
+	focusHandler := handlers.NewFocusHandler(db, cognitiveService, archiver, lessonService)
+	loopHandler := handlers.NewLoopHandler(db, archiver, lessonService)
+	threadHandler := handlers.NewThreadHandler(db, lessonService)
+	ingestHandler := handlers.NewIngestHandler(db, taskClassifier)
 	archiveHandler := handlers.NewArchiveHandler(db)
-	predictHandler := handlers.NewPredictHandler(db)
-	emotionHandler := handlers.NewEmotionHandler(db)
-	aiHandler := handlers.NewAIHandler(db)
-	modeHandler := handlers.NewModeHandler(db)
+	lessonHandler := handlers.NewLessonHandler(lessonService)
+	predictHandler := handlers.NewPredictHandler(db, ruminationGuard)
+	scenarioHandler := handlers.NewScenarioHandler(db)
+	emotionHandler := handlers.NewEmotionHandler(db, emotionService, triggerService, recommendationService)
+	triggerHandler := handlers.NewTriggerHandler(db, triggerService)
+	aiHandler := handlers.NewAIHandler(db, offloadService,
+		services.NewPromptService(db),
+		services.NewStateSummaryService(db, offloadService.Provider(), time.Duration(cfg.AITimeoutSeconds)*time.Second),
+		assistService,
+	)
+	modeHandler := handlers.NewModeHandler(db, services.NewResetService(db, cfg.SnapshotRetention))
+	reportHandler := handlers.NewReportHandler(reportService, ruminationGuard, triggerService)
+	recommendationHandler := handlers.NewRecommendationHandler(recommendationService)
+	telosHandler := handlers.NewTelosHandler(db, services.NewTelosService(db))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -58,6 +96,9 @@ func Setup(db *database.DB) *gin.Engine {
 
 			// POST /api/v1/focus/lock - Lock focus to prevent context switching
 			focus.POST("/lock", focusHandler.LockFocus)
+
+			// POST /api/v1/focus/complete - Complete the current focus session
+			focus.POST("/complete", focusHandler.CompleteFocus)
 		}
 
 		// Dashboard endpoint - cognitive status overview
@@ -110,6 +151,18 @@ func Setup(db *database.DB) *gin.Engine {
 
 			// POST /api/v1/ingest/idea - Capture a new idea
 			ingest.POST("/idea", ingestHandler.IngestIdea)
+
+			// GET /api/v1/ingest/rules - List task classification rules
+			ingest.GET("/rules", ingestHandler.ListRules)
+
+			// POST /api/v1/ingest/rules - Create a task classification rule
+			ingest.POST("/rules", ingestHandler.CreateRule)
+
+			// PUT /api/v1/ingest/rules/:id - Replace a task classification rule
+			ingest.PUT("/rules/:id", ingestHandler.UpdateRule)
+
+			// DELETE /api/v1/ingest/rules/:id - Delete a task classification rule
+			ingest.DELETE("/rules/:id", ingestHandler.DeleteRule)
 		}
 
 		// ===========================================
@@ -120,6 +173,28 @@ func Setup(db *database.DB) *gin.Engine {
 		{
 			// POST /api/v1/archive/commit - Commit something to the archive
 			archive.POST("/commit", archiveHandler.CommitArchive)
+
+			// GET /api/v1/archive/search?q= - Full-text search across archived lessons
+			archive.GET("/search", archiveHandler.SearchArchives)
+
+			// GET /api/v1/archive/pending-lessons - Auto-archived work awaiting a lesson
+			archive.GET("/pending-lessons", archiveHandler.PendingLessons)
+
+			// GET /api/v1/archive/:id - Read back a single archive entry
+			archive.GET("/:id", archiveHandler.GetArchive)
+
+			// POST /api/v1/archive/:id/lesson - Record a lesson on an archive entry
+			archive.POST("/:id/lesson", archiveHandler.RecordLesson)
+		}
+
+		// ===========================================
+		// LESSONS
+		// Past lessons resurface when starting related work
+		// ===========================================
+		lessons := v1.Group("/lessons")
+		{
+			// POST /api/v1/lessons/:id/rate - Rate whether a resurfaced lesson was useful
+			lessons.POST("/:id/rate", lessonHandler.RateLesson)
 		}
 
 		// ===========================================
@@ -133,6 +208,30 @@ func Setup(db *database.DB) *gin.Engine {
 
 			// DELETE /api/v1/predict/stop - Stop predictions on a topic
 			predict.DELETE("/stop", predictHandler.StopPrediction)
+
+			// GET /api/v1/predict/:id - Get a prediction with its forecasts
+			predict.GET("/:id", predictHandler.GetPrediction)
+
+			// POST /api/v1/predict/:id/forecasts - Add a probability forecast
+			predict.POST("/:id/forecasts", predictHandler.AddForecast)
+
+			// POST /api/v1/predict/:id/resolve - Record the actual outcome
+			predict.POST("/:id/resolve", predictHandler.ResolvePrediction)
+
+			// GET /api/v1/predict/:id/tree - Scenario tree with expected value (JSON or DOT)
+			predict.GET("/:id/tree", scenarioHandler.GetTree)
+
+			// POST /api/v1/predict/:id/branches - Add a scenario branch
+			predict.POST("/:id/branches", scenarioHandler.AddBranch)
+
+			// DELETE /api/v1/predict/:id/branches/:branch_id - Prune a branch and its subtree
+			predict.DELETE("/:id/branches/:branch_id", scenarioHandler.PruneBranch)
+		}
+
+		predictions := v1.Group("/predictions")
+		{
+			// GET /api/v1/predictions/due - Predictions past their horizon awaiting resolution
+			predictions.GET("/due", predictHandler.DuePredictions)
 		}
 
 		// ===========================================
@@ -146,6 +245,77 @@ func Setup(db *database.DB) *gin.Engine {
 
 			// POST /api/v1/emotion/decompress - Start a decompression session
 			emotion.POST("/decompress", emotionHandler.Decompress)
+
+			// GET /api/v1/emotion/decompress/:id - Decompression session with check-ins
+			emotion.GET("/decompress/:id", emotionHandler.GetDecompression)
+
+			// POST /api/v1/emotion/decompress/:id/end - End a session early
+			emotion.POST("/decompress/:id/end", emotionHandler.EndDecompression)
+
+			// POST /api/v1/emotion/decompress/:id/complete - Complete a session
+			emotion.POST("/decompress/:id/complete", emotionHandler.CompleteDecompression)
+
+			// POST /api/v1/emotion/decompress/:id/cancel - Cancel a session
+			emotion.POST("/decompress/:id/cancel", emotionHandler.CancelDecompression)
+
+			// GET /api/v1/emotion/taxonomy - List known emotions and their load weights
+			emotion.GET("/taxonomy", emotionHandler.GetTaxonomy)
+
+			// PUT /api/v1/emotion/taxonomy/:label - Add or update an emotion
+			emotion.PUT("/taxonomy/:label", emotionHandler.PutEmotion)
+
+			// DELETE /api/v1/emotion/taxonomy/:label - Remove an emotion
+			emotion.DELETE("/taxonomy/:label", emotionHandler.DeleteEmotion)
+
+			// GET /api/v1/emotion/history - Tagged emotions over a time range
+			emotion.GET("/history", emotionHandler.History)
+
+			// GET /api/v1/emotion/trends - Emotion frequencies and correlations
+			emotion.GET("/trends", emotionHandler.Trends)
+		}
+
+		triggers := v1.Group("/triggers")
+		{
+			// GET /api/v1/triggers - Detected people, projects and contexts
+			triggers.GET("", triggerHandler.ListTriggers)
+
+			// POST /api/v1/triggers - Register a trigger and its aliases
+			triggers.POST("", triggerHandler.CreateTrigger)
+
+			// POST /api/v1/triggers/backfill - Detect triggers for untagged emotions
+			triggers.POST("/backfill", triggerHandler.Backfill)
+
+			// PUT /api/v1/triggers/:id - Rename, reclassify or merge a trigger
+			triggers.PUT("/:id", triggerHandler.UpdateTrigger)
+
+			// DELETE /api/v1/triggers/:id - Remove a trigger
+			triggers.DELETE("/:id", triggerHandler.DeleteTrigger)
+		}
+
+		// ===========================================
+		// RECOMMENDATIONS
+		// What to do next, given everything above
+		// ===========================================
+		recommendations := v1.Group("/recommendations")
+		{
+			// GET /api/v1/recommendations - Suggested next action
+			recommendations.GET("", recommendationHandler.GetRecommendations)
+		}
+
+		// ===========================================
+		// TELOS / PERSONAL CONTEXT
+		// The problems, mission and goals that work traces back to
+		// ===========================================
+		telos := v1.Group("/telos")
+		{
+			// POST /api/v1/telos/import - Replace the context with a Telos markdown file
+			telos.POST("/import", telosHandler.Import)
+
+			// GET /api/v1/telos - List imported items (?section=goals for one section)
+			telos.GET("", telosHandler.ListItems)
+
+			// GET /api/v1/telos/:id - An item and the chain up to the problems it serves
+			telos.GET("/:id", telosHandler.GetItem)
 		}
 
 		// ===========================================
@@ -157,8 +327,87 @@ func Setup(db *database.DB) *gin.Engine {
 			// POST /api/v1/ai/offload - Offload a task to AI
 			ai.POST("/offload", aiHandler.Offload)
 
-			// POST /api/v1/ai/assist-for-execution - Get AI assistance for execution
+			// POST /api/v1/ai/assist-for-execution - Start an assist session broken into steps
 			ai.POST("/assist-for-execution", aiHandler.AssistForExecution)
+
+			// GET /api/v1/ai/assist/sessions - List assist sessions with their steps
+			ai.GET("/assist/sessions", aiHandler.ListAssistSessions)
+
+			// GET /api/v1/ai/assist/sessions/:id - An assist session's steps and progress
+			ai.GET("/assist/sessions/:id", aiHandler.GetAssistSession)
+
+			// POST /api/v1/ai/assist/sessions/:id/plan - Take a session's steps from its finished breakdown
+			ai.POST("/assist/sessions/:id/plan", aiHandler.PlanAssistSession)
+
+			// POST /api/v1/ai/assist/sessions/:id/steps/:position/done - Check off a step
+			ai.POST("/assist/sessions/:id/steps/:position/done", aiHandler.CompleteAssistStep)
+
+			// POST /api/v1/ai/assist/sessions/:id/steps/:position/stuck - Ask for help with a step
+			ai.POST("/assist/sessions/:id/steps/:position/stuck", aiHandler.StuckAssistStep)
+
+			// POST /api/v1/ai/summarize-state - Prioritized briefing on all open work
+			ai.POST("/summarize-state", aiHandler.SummarizeState)
+
+			// GET /api/v1/ai/offloads - List queued, running and finished offloads
+			ai.GET("/offloads", aiHandler.ListOffloads)
+
+			// GET /api/v1/ai/offloads/:id - Offload status, attempts and result
+			ai.GET("/offloads/:id", aiHandler.GetOffload)
+
+			// POST /api/v1/ai/offloads/:id/cancel - Cancel a pending or running offload
+			ai.POST("/offloads/:id/cancel", aiHandler.CancelOffload)
+
+			// POST /api/v1/ai/offloads/:id/approve - Accept a result awaiting review
+			ai.POST("/offloads/:id/approve", aiHandler.ApproveOffload)
+
+			// POST /api/v1/ai/offloads/:id/edit - Accept a result awaiting review with corrections
+			ai.POST("/offloads/:id/edit", aiHandler.EditOffload)
+
+			// POST /api/v1/ai/offloads/:id/reject - Re-queue a result with reviewer feedback
+			ai.POST("/offloads/:id/reject", aiHandler.RejectOffload)
+
+			// GET /api/v1/ai/reviews - Audit trail of who accepted or rejected what
+			ai.GET("/reviews", aiHandler.ListReviews)
+
+			// GET /api/v1/ai/templates - Current prompt template of every task type
+			ai.GET("/templates", aiHandler.ListTemplates)
+
+			// GET /api/v1/ai/templates/:task_type - A task type's template (?version= for an earlier one)
+			ai.GET("/templates/:task_type", aiHandler.GetTemplate)
+
+			// GET /api/v1/ai/templates/:task_type/versions - Every version of a template
+			ai.GET("/templates/:task_type/versions", aiHandler.ListTemplateVersions)
+
+			// PUT /api/v1/ai/templates/:task_type - Save a new template version
+			ai.PUT("/templates/:task_type", aiHandler.PutTemplate)
+
+			// DELETE /api/v1/ai/templates/:task_type - Remove a template and its versions
+			ai.DELETE("/templates/:task_type", aiHandler.DeleteTemplate)
+		}
+
+		// ===========================================
+		// REPORTS
+		// Periodic reviews assembled from recorded state
+		// ===========================================
+		reports := v1.Group("/reports")
+		{
+			// GET /api/v1/reports/retro - Weekly/monthly retrospective (JSON or Markdown)
+			reports.GET("/retro", reportHandler.Retro)
+
+			// GET /api/v1/reports/calibration - Forecast Brier scores and calibration curve
+			reports.GET("/calibration", reportHandler.Calibration)
+
+			// GET /api/v1/reports/rumination - Stopped topics that keep getting restarted
+			reports.GET("/rumination", reportHandler.Rumination)
+
+			// GET /api/v1/reports/decompression - Which decompression methods reduce load
+			reports.GET("/decompression", reportHandler.Decompression)
+
+			// GET /api/v1/reports/triggers - Triggers, loops and threads behind high-load emotions
+			reports.GET("/triggers", reportHandler.Triggers)
+
+			// GET /api/v1/reports/alignment - Work per Telos goal, and work linked to no goal
+			reports.GET("/alignment", reportHandler.Alignment)
 		}
 
 		// ===========================================
@@ -170,8 +419,14 @@ func Setup(db *database.DB) *gin.Engine {
 			// POST /api/v1/mode/reset-soft - Soft reset (preserve history)
 			mode.POST("/reset-soft", modeHandler.SoftReset)
 
-			// POST /api/v1/mode/reset-hard - Hard reset (wipe everything)
+			// POST /api/v1/mode/reset-hard - Hard reset (wipe everything, needs a confirmation token)
 			mode.POST("/reset-hard", modeHandler.HardReset)
+
+			// GET /api/v1/mode/snapshots - List the snapshots taken before resets
+			mode.GET("/snapshots", modeHandler.ListSnapshots)
+
+			// POST /api/v1/mode/restore/:id - Restore all state from a snapshot
+			mode.POST("/restore/:id", modeHandler.RestoreSnapshot)
 		}
 	}
 
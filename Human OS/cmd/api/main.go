@@ -21,8 +21,11 @@ import (
 	"time"
 
 	"humanos-api/api/routes"
+	"humanos-api/internal/ai"
 	"humanos-api/internal/config"
 	"humanos-api/internal/database"
+	"humanos-api/internal/services"
+	"humanos-api/internal/telos"
 )
 
 func main() {
@@ -43,8 +46,59 @@ func main() {
 		}
 	}()
 
+	// Import the personal context work is linked to and AI prompts are given
+	if cfg.TelosPath != "" {
+		doc, err := telos.ParseFile(cfg.TelosPath)
+		if err != nil {
+			log.Fatalf("Failed to read Telos file: %v", err)
+		}
+		if err := services.NewTelosService(db).Import(doc, time.Now().UTC()); err != nil {
+			log.Fatalf("Failed to import Telos file: %v", err)
+		}
+		log.Printf("Imported %d Telos item(s), %d goal(s), from %s", len(doc.Items), len(doc.Section("GOALS")), cfg.TelosPath)
+	}
+
+	// Start the AI offload queue
+	aiConfig := ai.Config{
+		Provider: cfg.AIProvider,
+		BaseURL:  cfg.AIBaseURL,
+		APIKey:   cfg.AIAPIKey,
+		Model:    cfg.AIModel,
+		Timeout:  time.Duration(cfg.AITimeoutSeconds) * time.Second,
+	}
+	aiProvider, err := ai.New(aiConfig)
+	if err != nil {
+		log.Fatalf("Failed to create AI provider: %v", err)
+	}
+	offloadPolicy := services.OffloadPolicy{
+		Timeout:         time.Duration(cfg.AITimeoutSeconds) * time.Second,
+		Concurrency:     cfg.AIConcurrency,
+		TypeConcurrency: cfg.AITypeConcurrency,
+		MaxAttempts:     cfg.AIMaxAttempts,
+		RetryBackoff:    time.Duration(cfg.AIRetryBackoffSeconds) * time.Second,
+		PollInterval:    time.Second,
+		RequireQuorum:   cfg.AIQuorumRequired,
+	}
+	offloadPolicy.Quorum, err = ai.NewQuorum(cfg.AIQuorumProviders, cfg.AIQuorumStrategy, cfg.AIQuorumJudge, aiConfig)
+	if err != nil {
+		log.Fatalf("Failed to configure AI quorum: %v", err)
+	}
+	if cfg.AIQuorumRequired && offloadPolicy.Quorum == nil {
+		log.Fatalf("AI_QUORUM_REQUIRED is set but AI_QUORUM_PROVIDERS lists fewer than two providers")
+	}
+	offloadService := services.NewOffloadService(db, aiProvider, offloadPolicy)
+	offloadService.Start()
+
 	// Setup router
-	router := routes.Setup(db)
+	router := routes.Setup(db, cfg, offloadService)
+
+	// Start background prediction checks
+	predictionScheduler := services.NewPredictionScheduler(
+		db,
+		time.Duration(cfg.PredictionScanMinutes)*time.Minute,
+		time.Duration(cfg.LowDepthGraceHours)*time.Hour,
+	)
+	predictionScheduler.Start()
 
 	// Create HTTP server
 	server := &http.Server{
@@ -97,6 +151,11 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
+	predictionScheduler.Stop()
+
+	// Let running offloads finish; any still running at the deadline are
+	// returned to the queue for the next start
+	offloadService.Stop(ctx)
 
 	log.Println("Server exited properly")
 }
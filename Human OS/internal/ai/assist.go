@@ -0,0 +1,46 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TaskUnstick is the task type of a request for help with an assist step the
+// person is stuck on. It sits outside "assist:<type>" so no assistance type
+// can be mistaken for it.
+const TaskUnstick = "unstick"
+
+// maxSteps caps how many steps a breakdown is read as
+const maxSteps = 10
+
+// stepLine matches a numbered or bulleted line of a breakdown
+var stepLine = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*•])\s+(.+?)\s*$`)
+
+// UnstickInstructions tells a provider how to help with a stuck step
+const UnstickInstructions = `The user is doing a task themselves and is stuck on one step.
+Suggest the smallest action that gets them moving again, and one way around whatever is in the way. Be brief.`
+
+// BreakdownInstructions tells a provider how to split a task the person is
+// doing themselves into steps, focusing on the kind of assistance asked for
+func BreakdownInstructions(assistanceType string) string {
+	return "The user is doing this task themselves. Help only with: " + assistanceType + ".\n" +
+		"Break the task into 3 to 7 small, concrete steps they can check off, as a numbered list with one step per line and nothing else."
+}
+
+// ParseSteps reads the steps of a breakdown from a provider's reply: every
+// numbered or bulleted line, in order, up to 10. Prose around the list is
+// ignored.
+func ParseSteps(output string) []string {
+	var steps []string
+	for _, line := range strings.Split(output, "\n") {
+		match := stepLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		steps = append(steps, match[1])
+		if len(steps) == maxSteps {
+			break
+		}
+	}
+	return steps
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// jobs tracks the background jobs of a provider whose backend only offers
+// blocking calls, giving them the submit/poll/cancel shape of Provider
+type jobs struct {
+	mu      sync.Mutex
+	entries map[string]*job
+}
+
+// job is a tracked job and the function that cancels it
+type job struct {
+	result Result
+	cancel context.CancelFunc
+}
+
+// newJobs creates an empty job tracker
+func newJobs() *jobs {
+	return &jobs{entries: make(map[string]*job)}
+}
+
+// start runs fn in the background as a new job and returns the job ID. The
+// job outlives ctx's cancellation but keeps its values.
+func (j *jobs) start(ctx context.Context, fn func(ctx context.Context) (string, error)) string {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	id := uuid.New().String()
+
+	j.mu.Lock()
+	j.entries[id] = &job{result: Result{JobID: id, Status: StatusRunning}, cancel: cancel}
+	j.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		output, err := fn(ctx)
+		j.finish(id, output, err)
+	}()
+	return id
+}
+
+// finish records a job's outcome unless it was cancelled, and so forgotten,
+// first
+func (j *jobs) finish(id, output string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.entries[id]
+	if !ok {
+		return
+	}
+	switch {
+	case err == nil:
+		entry.result.Status = StatusCompleted
+		entry.result.Output = output
+	case errors.Is(err, context.Canceled):
+		entry.result.Status = StatusCancelled
+	default:
+		entry.result.Status = StatusFailed
+		entry.result.Error = err.Error()
+	}
+}
+
+// poll returns a copy of a job's state, forgetting the job once it has
+// finished
+func (j *jobs) poll(id string) (*Result, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.entries[id]
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	result := entry.result
+	if result.Status != StatusRunning {
+		delete(j.entries, id)
+	}
+	return &result, nil
+}
+
+// cancel stops a running job and forgets it. Cancelling a job that has
+// finished but was never polled just forgets it.
+func (j *jobs) cancel(id string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.entries[id]
+	if !ok {
+		return ErrUnknownJob
+	}
+	entry.cancel()
+	delete(j.entries, id)
+	return nil
+}
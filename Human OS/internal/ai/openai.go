@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIProvider talks to any server implementing the OpenAI chat
+// completions API, including local ones such as Ollama or llama.cpp
+type OpenAIProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+	jobs    *jobs
+}
+
+// NewOpenAIProvider creates a provider for the chat completions API at
+// baseURL. apiKey may be empty for local servers that don't need one.
+func NewOpenAIProvider(baseURL, apiKey, model string, timeout time.Duration) *OpenAIProvider {
+	return &OpenAIProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		client:  &http.Client{Timeout: timeout},
+		jobs:    newJobs(),
+	}
+}
+
+// chatMessage is one message in a chat completion request
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest is the body of a chat completion request
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream,omitempty"`
+}
+
+// chatResponse is the part of a chat completion response, or of one
+// streamed chunk, that is read
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+		Delta   chatMessage `json:"delta"`
+	} `json:"choices"`
+}
+
+// Name identifies the provider on offload records
+func (p *OpenAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+// Submit starts a chat completion in the background
+func (p *OpenAIProvider) Submit(ctx context.Context, req Request) (string, error) {
+	return p.jobs.start(ctx, func(ctx context.Context) (string, error) {
+		return p.complete(ctx, req)
+	}), nil
+}
+
+// Poll returns a job's current state
+func (p *OpenAIProvider) Poll(ctx context.Context, jobID string) (*Result, error) {
+	return p.jobs.poll(jobID)
+}
+
+// Cancel aborts a running completion
+func (p *OpenAIProvider) Cancel(ctx context.Context, jobID string) error {
+	return p.jobs.cancel(jobID)
+}
+
+// complete runs a blocking chat completion
+func (p *OpenAIProvider) complete(ctx context.Context, req Request) (string, error) {
+	resp, err := p.post(ctx, req, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("openai: invalid response: %w", err)
+	}
+	if len(body.Choices) == 0 {
+		return "", errors.New("openai: response has no choices")
+	}
+	return body.Choices[0].Message.Content, nil
+}
+
+// Stream runs a chat completion with server-sent events, passing each
+// content delta to chunk
+func (p *OpenAIProvider) Stream(ctx context.Context, req Request, chunk func(string)) (string, error) {
+	resp, err := p.post(ctx, req, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var output strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var event chatResponse
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return output.String(), fmt.Errorf("openai: invalid stream event: %w", err)
+		}
+		if len(event.Choices) > 0 && event.Choices[0].Delta.Content != "" {
+			output.WriteString(event.Choices[0].Delta.Content)
+			chunk(event.Choices[0].Delta.Content)
+		}
+	}
+	return output.String(), scanner.Err()
+}
+
+// post sends a chat completion request, returning an error for non-2xx
+// responses
+func (p *OpenAIProvider) post(ctx context.Context, req Request, stream bool) (*http.Response, error) {
+	var messages []chatMessage
+	if req.System != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, chatMessage{Role: "user", Content: req.Prompt})
+
+	payload, err := json.Marshal(chatRequest{Model: p.model, Messages: messages, Stream: stream})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("openai: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
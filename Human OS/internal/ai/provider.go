@@ -0,0 +1,132 @@
+// Package ai defines the interface between Human OS and the AI backends that
+// offloaded work is delegated to. A provider accepts a request, runs it as a
+// job that can be polled or cancelled, and can stream output as it is
+// generated. An OpenAI-compatible HTTP client and a deterministic local stub
+// are included; the stub keeps the API usable offline and in tests.
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Provider names accepted by New
+const (
+	ProviderStub   = "stub"
+	ProviderOpenAI = "openai"
+)
+
+// Status is the state of a provider job
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// ErrUnknownJob is returned when polling or cancelling a job the provider
+// does not know about, including cancelled jobs and finished jobs that were
+// already polled
+var ErrUnknownJob = errors.New("unknown job")
+
+// Request is a unit of work sent to a provider
+type Request struct {
+	TaskType   string   // plan, draft, refactor, summarize, explore, assist:*, unstick, judge, summarize-state
+	System     string   // instructions for how to approach the task
+	Prompt     string   // the task itself
+	Candidates []string // for judge requests, the responses being compared
+}
+
+// ReservedTaskType reports whether a task type is one Human OS sends on its
+// own behalf - assist breakdowns, help with stuck steps, quorum judging and
+// state summaries - rather than one a person offloads
+func ReservedTaskType(taskType string) bool {
+	switch taskType {
+	case TaskUnstick, TaskJudge, TaskSummarizeState:
+		return true
+	}
+	return strings.HasPrefix(taskType, "assist:")
+}
+
+// Result is the state of a job when it was polled
+type Result struct {
+	JobID  string
+	Status Status
+	Output string
+	Error  string
+}
+
+// Provider runs requests against an AI backend
+type Provider interface {
+	// Name identifies the provider on offload records
+	Name() string
+
+	// Submit starts a job in the background and returns its ID
+	Submit(ctx context.Context, req Request) (string, error)
+
+	// Poll returns a job's current state. A finished job is forgotten once
+	// its result has been returned.
+	Poll(ctx context.Context, jobID string) (*Result, error)
+
+	// Cancel stops a running job and forgets it
+	Cancel(ctx context.Context, jobID string) error
+
+	// Stream runs a request in the foreground, calling chunk with each piece
+	// of output as it arrives, and returns the full output
+	Stream(ctx context.Context, req Request, chunk func(string)) (string, error)
+}
+
+// Config selects and configures a provider
+type Config struct {
+	Provider string // "stub" or "openai"
+	BaseURL  string // OpenAI-compatible API root, e.g. https://api.openai.com/v1
+	APIKey   string
+	Model    string
+	Timeout  time.Duration // per HTTP request
+}
+
+// New creates the provider named in cfg
+func New(cfg Config) (Provider, error) {
+	switch cfg.Provider {
+	case ProviderStub, "":
+		return NewStubProvider(0), nil
+	case ProviderOpenAI:
+		return NewOpenAIProvider(cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q", cfg.Provider)
+	}
+}
+
+// Run submits a request and polls until the job finishes. If ctx ends first
+// the job is cancelled and ctx's error is returned.
+func Run(ctx context.Context, p Provider, req Request, interval time.Duration) (*Result, error) {
+	jobID, err := p.Submit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := p.Poll(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if result.Status != StatusRunning {
+			return result, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			// The job may have finished in the meantime; either way it is gone
+			_ = p.Cancel(context.Background(), jobID)
+			return nil, ctx.Err()
+		}
+	}
+}
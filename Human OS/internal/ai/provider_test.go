@@ -0,0 +1,252 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// jobCount returns how many jobs a tracker still holds
+func jobCount(j *jobs) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.entries)
+}
+
+// TestOpenAIProvider tests chat completions against a fake OpenAI-compatible
+// server, including the request sent and failed responses
+func TestOpenAIProvider(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		expectedStatus Status
+		expectedOutput string
+		expectedError  string
+	}{
+		{
+			name:           "completion",
+			status:         http.StatusOK,
+			body:           `{"choices": [{"message": {"role": "assistant", "content": "1. Outline"}}]}`,
+			expectedStatus: StatusCompleted,
+			expectedOutput: "1. Outline",
+		},
+		{
+			name:           "error status",
+			status:         http.StatusTooManyRequests,
+			body:           "slow down",
+			expectedStatus: StatusFailed,
+			expectedError:  "429 Too Many Requests: slow down",
+		},
+		{
+			name:           "no choices",
+			status:         http.StatusOK,
+			body:           `{"choices": []}`,
+			expectedStatus: StatusFailed,
+			expectedError:  "response has no choices",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got chatRequest
+			var auth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+				json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewOpenAIProvider(server.URL+"/", "secret", "test-model", time.Second)
+			result, err := Run(context.Background(), provider, Request{
+				TaskType: "plan", System: "Be brief", Prompt: "Plan the launch",
+			}, time.Millisecond)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if result.Status != tt.expectedStatus || result.Output != tt.expectedOutput ||
+				!strings.Contains(result.Error, tt.expectedError) {
+				t.Errorf("Expected %s %q (error %q), got %+v", tt.expectedStatus, tt.expectedOutput, tt.expectedError, result)
+			}
+			if auth != "Bearer secret" || got.Model != "test-model" || len(got.Messages) != 2 ||
+				got.Messages[0].Role != "system" || got.Messages[1].Content != "Plan the launch" {
+				t.Errorf("Unexpected request: auth %q, body %+v", auth, got)
+			}
+			if n := jobCount(provider.jobs); n != 0 {
+				t.Errorf("Expected the polled job to be forgotten, %d left", n)
+			}
+		})
+	}
+}
+
+// TestOpenAIProviderStream tests streamed chat completions, which pass each
+// content delta on as it arrives and stop at the end-of-stream marker
+func TestOpenAIProviderStream(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		expectedChunks []string
+		expectedError  string
+	}{
+		{
+			name:   "deltas",
+			status: http.StatusOK,
+			body: "data: {\"choices\": [{\"delta\": {\"role\": \"assistant\"}}]}\n\n" +
+				"data: {\"choices\": [{\"delta\": {\"content\": \"1. Out\"}}]}\n\n" +
+				": keep-alive\n\n" +
+				"data: {\"choices\": [{\"delta\": {\"content\": \"line\"}}]}\n\n" +
+				"data: [DONE]\n\n" +
+				"data: {\"choices\": [{\"delta\": {\"content\": \"ignored\"}}]}\n\n",
+			expectedChunks: []string{"1. Out", "line"},
+		},
+		{
+			name:           "invalid event",
+			status:         http.StatusOK,
+			body:           "data: {\"choices\": [{\"delta\": {\"content\": \"1. \"}}]}\n\ndata: {oops\n\n",
+			expectedChunks: []string{"1. "},
+			expectedError:  "invalid stream event",
+		},
+		{
+			name:          "error status",
+			status:        http.StatusUnauthorized,
+			body:          "bad key",
+			expectedError: "401 Unauthorized: bad key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got chatRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&got)
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewOpenAIProvider(server.URL, "secret", "test-model", time.Second)
+			var chunks []string
+			output, err := provider.Stream(context.Background(), Request{TaskType: "plan", Prompt: "Plan the launch"}, func(chunk string) {
+				chunks = append(chunks, chunk)
+			})
+
+			if tt.expectedError == "" && err != nil || tt.expectedError != "" && (err == nil || !strings.Contains(err.Error(), tt.expectedError)) {
+				t.Fatalf("Expected error %q, got %v", tt.expectedError, err)
+			}
+			if strings.Join(chunks, "|") != strings.Join(tt.expectedChunks, "|") || output != strings.Join(tt.expectedChunks, "") {
+				t.Errorf("Expected chunks %q, got %q with output %q", tt.expectedChunks, chunks, output)
+			}
+			if !got.Stream {
+				t.Error("Expected a streaming request")
+			}
+		})
+	}
+}
+
+// TestStubProviderStream tests that the stub streams its output line by line
+// and gives up when the context ends first
+func TestStubProviderStream(t *testing.T) {
+	provider := NewStubProvider(0)
+	req := Request{TaskType: "plan", Prompt: "Plan the launch"}
+
+	var chunks []string
+	output, err := provider.Stream(context.Background(), req, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if output != StubOutput(req) || strings.Join(chunks, "") != output || len(chunks) != strings.Count(output, "\n")+1 {
+		t.Errorf("Expected the stub output one line at a time, got %q", chunks)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewStubProvider(time.Hour).Stream(ctx, req, func(string) {
+		t.Error("Expected no output once cancelled")
+	}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+}
+
+// TestJobs tests that jobs are forgotten once polled after finishing or
+// once cancelled, whether or not they were still running
+func TestJobs(t *testing.T) {
+	provider := NewStubProvider(time.Hour)
+	ctx := context.Background()
+
+	running, _ := provider.Submit(ctx, Request{TaskType: "plan", Prompt: "Plan"})
+	if result, err := provider.Poll(ctx, running); err != nil || result.Status != StatusRunning {
+		t.Fatalf("Expected a running job, got %+v, %v", result, err)
+	}
+	if err := provider.Cancel(ctx, running); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if _, err := provider.Poll(ctx, running); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected a cancelled job to be forgotten, got %v", err)
+	}
+	if err := provider.Cancel(ctx, running); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected cancelling twice to fail, got %v", err)
+	}
+
+	// A job that finished but was never polled is forgotten when cancelled
+	finished := provider.jobs.start(ctx, func(ctx context.Context) (string, error) {
+		return "done", nil
+	})
+	for {
+		provider.jobs.mu.Lock()
+		status := provider.jobs.entries[finished].result.Status
+		provider.jobs.mu.Unlock()
+		if status != StatusRunning {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := provider.Cancel(ctx, finished); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if n := jobCount(provider.jobs); n != 0 {
+		t.Errorf("Expected no jobs left, got %d", n)
+	}
+}
+
+// TestRunTimeout tests that a request outlasting its context is cancelled,
+// aborting the HTTP call, and leaves no job behind
+func TestRunTimeout(t *testing.T) {
+	aborted := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body is read
+		io.ReadAll(r.Body)
+		<-r.Context().Done()
+		close(aborted)
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(server.URL, "", "test-model", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, err := Run(ctx, provider, Request{TaskType: "plan", Prompt: "Plan"}, 5*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a deadline error, got %+v, %v", result, err)
+	}
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the HTTP request to be aborted")
+	}
+	if n := jobCount(provider.jobs); n != 0 {
+		t.Errorf("Expected the timed-out job to be forgotten, %d left", n)
+	}
+}
@@ -0,0 +1,321 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Agreement strategy names accepted by NewStrategy
+const (
+	StrategyMajority  = "majority"
+	StrategyUnanimity = "unanimity"
+	StrategyJudge     = "judge"
+)
+
+// TaskJudge is the task type of a request asking a judge whether quorum
+// votes agree
+const TaskJudge = "judge"
+
+// Judge verdicts: "AGREE: <n>" or "DISAGREE: <reason>", at the start of a
+// line and possibly in bold, so that words in the reasoning are not mistaken
+// for one
+var judgeVerdict = regexp.MustCompile(`(?im)^\s*[*_]*(AGREE|DISAGREE)\b[*_]*\s*:?[*_]*\s*(.*)$`)
+
+// judgeChoice is the response number leading an AGREE verdict
+var judgeChoice = regexp.MustCompile(`^\d+`)
+
+// judgePollInterval is how often a judge's job is polled
+const judgePollInterval = 250 * time.Millisecond
+
+// Member is one provider taking part in a quorum, named as configured
+type Member struct {
+	Name     string
+	Provider Provider
+}
+
+// Vote is one member's answer to a quorum request
+type Vote struct {
+	Member string
+	Status Status
+	Output string
+	Error  string
+	Agrees bool // set by the strategy: the vote is part of the agreement
+}
+
+// Decision is a strategy's verdict on a set of votes
+type Decision struct {
+	Reached bool
+	Output  string // the agreed output when Reached
+	Reason  string
+}
+
+// Strategy decides whether a set of votes agrees. It marks the agreeing
+// votes in place.
+type Strategy interface {
+	Name() string
+	Decide(ctx context.Context, req Request, votes []Vote) (Decision, error)
+}
+
+// Quorum fans a request out to several providers and lets a strategy decide
+// whether their answers agree
+type Quorum struct {
+	Members  []Member
+	Strategy Strategy
+}
+
+// Run sends the request to every member at once and waits for all of them.
+// Votes are returned in member order, even when ctx ends first; the
+// decision is only made when every member has answered.
+func (q *Quorum) Run(ctx context.Context, req Request, interval time.Duration) ([]Vote, Decision, error) {
+	votes := make([]Vote, len(q.Members))
+	var wg sync.WaitGroup
+	for i, member := range q.Members {
+		wg.Add(1)
+		go func(i int, member Member) {
+			defer wg.Done()
+			votes[i] = Vote{Member: member.Name}
+			result, err := Run(ctx, member.Provider, req, interval)
+			switch {
+			case err != nil:
+				votes[i].Status, votes[i].Error = StatusFailed, err.Error()
+			default:
+				votes[i].Status, votes[i].Output, votes[i].Error = result.Status, result.Output, result.Error
+			}
+		}(i, member)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return votes, Decision{}, err
+	}
+	decision, err := q.Strategy.Decide(ctx, req, votes)
+	return votes, decision, err
+}
+
+// NewQuorum builds a quorum from configuration: members is a ParseMembers
+// list, strategy a strategy name and judge a single member entry for the
+// judge strategy, defaulting to base itself. It returns nil when fewer than
+// two members are listed, since a quorum of one cannot disagree.
+func NewQuorum(members, strategy, judge string, base Config) (*Quorum, error) {
+	parsed, err := ParseMembers(members, base)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) < 2 {
+		return nil, nil
+	}
+
+	var judgeProvider Provider
+	if strategy == StrategyJudge {
+		judges, err := ParseMembers(judge, base)
+		if err != nil {
+			return nil, err
+		}
+		switch len(judges) {
+		case 0:
+			if judgeProvider, err = New(base); err != nil {
+				return nil, err
+			}
+		case 1:
+			judgeProvider = judges[0].Provider
+		default:
+			return nil, fmt.Errorf("only one judge can be configured, got %q", judge)
+		}
+	}
+
+	s, err := NewStrategy(strategy, judgeProvider)
+	if err != nil {
+		return nil, err
+	}
+	return &Quorum{Members: parsed, Strategy: s}, nil
+}
+
+// NewStrategy creates the named agreement strategy. judge is only used by
+// the judge strategy.
+func NewStrategy(name string, judge Provider) (Strategy, error) {
+	switch name {
+	case StrategyMajority:
+		return matchStrategy{name: name, unanimous: false}, nil
+	case StrategyUnanimity:
+		return matchStrategy{name: name, unanimous: true}, nil
+	case StrategyJudge:
+		if judge == nil {
+			return nil, fmt.Errorf("the judge strategy needs a judge provider")
+		}
+		return judgeStrategy{judge: judge}, nil
+	default:
+		return nil, fmt.Errorf("unknown agreement strategy %q", name)
+	}
+}
+
+// matchStrategy agrees when enough votes give the same output, compared
+// case- and whitespace-insensitively. Majority needs more than half of all
+// members, unanimity needs every one; failed votes count against both.
+type matchStrategy struct {
+	name      string
+	unanimous bool
+}
+
+// Name identifies the strategy on offload records
+func (s matchStrategy) Name() string {
+	return s.name
+}
+
+// Decide groups the completed votes by normalized output and checks the
+// largest group against the threshold
+func (s matchStrategy) Decide(ctx context.Context, req Request, votes []Vote) (Decision, error) {
+	groups := make(map[string][]int)
+	best := ""
+	for i, vote := range votes {
+		if vote.Status != StatusCompleted {
+			continue
+		}
+		key := NormalizeOutput(vote.Output)
+		groups[key] = append(groups[key], i)
+		if len(groups[key]) > len(groups[best]) {
+			best = key
+		}
+	}
+
+	agreeing := groups[best]
+	needed := len(votes)/2 + 1
+	if s.unanimous {
+		needed = len(votes)
+	}
+	if len(agreeing) < needed {
+		return Decision{Reason: fmt.Sprintf("%d of %d votes agree; %s needs %d", len(agreeing), len(votes), s.name, needed)}, nil
+	}
+
+	for _, i := range agreeing {
+		votes[i].Agrees = true
+	}
+	return Decision{
+		Reached: true,
+		Output:  votes[agreeing[0]].Output,
+		Reason:  fmt.Sprintf("%d of %d votes agree", len(agreeing), len(votes)),
+	}, nil
+}
+
+// judgeStrategy asks a judge provider whether the completed votes agree in
+// substance and which one is best. It suits free text, where outputs rarely
+// match word for word.
+type judgeStrategy struct {
+	judge Provider
+}
+
+// Name identifies the strategy on offload records
+func (s judgeStrategy) Name() string {
+	return StrategyJudge
+}
+
+// Decide sends the completed votes to the judge and parses its verdict.
+// More than half of all members must have completed for the judge to be
+// asked, so a few members cannot decide for a larger quorum.
+func (s judgeStrategy) Decide(ctx context.Context, req Request, votes []Vote) (Decision, error) {
+	var candidates []string
+	var indexes []int
+	for i, vote := range votes {
+		if vote.Status == StatusCompleted {
+			candidates = append(candidates, vote.Output)
+			indexes = append(indexes, i)
+		}
+	}
+	if needed := len(votes)/2 + 1; len(candidates) < needed || len(candidates) < 2 {
+		return Decision{Reason: fmt.Sprintf("%d of %d votes completed; the judge needs %d", len(candidates), len(votes), max(needed, 2))}, nil
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Task (%s): %s\n\n", req.TaskType, req.Prompt)
+	for i, candidate := range candidates {
+		fmt.Fprintf(&prompt, "Response %d:\n%s\n\n", i+1, candidate)
+	}
+	prompt.WriteString(`If the responses agree in substance, reply "AGREE: <number of the best response>". Otherwise reply "DISAGREE: <reason>".`)
+
+	result, err := Run(ctx, s.judge, Request{
+		TaskType:   TaskJudge,
+		System:     "You are checking independent answers to the same task for agreement.",
+		Prompt:     prompt.String(),
+		Candidates: candidates,
+	}, judgePollInterval)
+	if err != nil {
+		return Decision{}, fmt.Errorf("judge: %w", err)
+	}
+	if result.Status != StatusCompleted {
+		return Decision{}, fmt.Errorf("judge %s: %s", result.Status, result.Error)
+	}
+
+	match := judgeVerdict.FindStringSubmatch(result.Output)
+	if match == nil {
+		return Decision{}, fmt.Errorf("judge gave no verdict: %q", result.Output)
+	}
+	if strings.EqualFold(match[1], "DISAGREE") {
+		return Decision{Reason: "judge: " + strings.TrimSpace(match[2])}, nil
+	}
+	n, _ := strconv.Atoi(judgeChoice.FindString(match[2]))
+	if n < 1 || n > len(candidates) {
+		return Decision{}, fmt.Errorf("judge named no valid response: %q", result.Output)
+	}
+
+	for _, i := range indexes {
+		votes[i].Agrees = true
+	}
+	return Decision{
+		Reached: true,
+		Output:  candidates[n-1],
+		Reason:  fmt.Sprintf("judge: responses agree, response %d is best", n),
+	}, nil
+}
+
+// NormalizeOutput reduces an output to what majority and unanimity compare:
+// lower case, single spaces, no trailing punctuation
+func NormalizeOutput(output string) string {
+	return strings.TrimRight(strings.ToLower(strings.Join(strings.Fields(output), " ")), ".!")
+}
+
+// ParseMembers parses a comma-separated list of quorum members, each
+// "provider[:model][@base_url][#key_env]". Unset parts come from base, except
+// that a member with its own base URL never gets base's API key: it only
+// sends the key held in the environment variable named by key_env, so the
+// main provider's key is not handed to another host.
+func ParseMembers(spec string, base Config) ([]Member, error) {
+	var members []Member
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		cfg := base
+		rest := entry
+		keyEnv := ""
+		if hash := strings.LastIndex(rest, "#"); hash >= 0 {
+			keyEnv, rest = rest[hash+1:], rest[:hash]
+		}
+		if at := strings.Index(rest, "@"); at >= 0 {
+			cfg.BaseURL, rest = rest[at+1:], rest[:at]
+			cfg.APIKey = ""
+		}
+		cfg.Provider = rest
+		if colon := strings.Index(rest, ":"); colon >= 0 {
+			cfg.Provider, cfg.Model = rest[:colon], rest[colon+1:]
+		}
+		if keyEnv != "" {
+			if cfg.APIKey = os.Getenv(keyEnv); cfg.APIKey == "" {
+				return nil, fmt.Errorf("quorum member %q: %s is not set", entry, keyEnv)
+			}
+		}
+
+		provider, err := New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("quorum member %q: %w", entry, err)
+		}
+		members = append(members, Member{Name: entry, Provider: provider})
+	}
+	return members, nil
+}
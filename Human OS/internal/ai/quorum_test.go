@@ -0,0 +1,153 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// failingProvider is a stub provider whose jobs always fail
+type failingProvider struct {
+	*StubProvider
+}
+
+// Submit starts a job that fails straight away
+func (p failingProvider) Submit(ctx context.Context, req Request) (string, error) {
+	return p.jobs.start(ctx, func(ctx context.Context) (string, error) {
+		return "", errors.New("provider unavailable")
+	}), nil
+}
+
+// replyProvider is a stub provider whose jobs always return the same reply
+type replyProvider struct {
+	*StubProvider
+	reply string
+}
+
+// Submit starts a job that returns the reply straight away
+func (p replyProvider) Submit(ctx context.Context, req Request) (string, error) {
+	return p.jobs.start(ctx, func(ctx context.Context) (string, error) {
+		return p.reply, nil
+	}), nil
+}
+
+// TestJudgeVerdict tests that only a verdict leading a line counts, so the
+// judge's reasoning cannot turn an agreement into a disagreement
+func TestJudgeVerdict(t *testing.T) {
+	tests := []struct {
+		reply          string
+		expectedOutput string
+		expectedReason string
+		expectedError  bool
+	}{
+		{reply: "AGREE: 2 - they disagree on minor points only", expectedOutput: "Ship Friday"},
+		{reply: "AGREE: 2\nThey disagree on minor points.", expectedOutput: "Ship Friday"},
+		{reply: "The dates differ.\n**DISAGREE**: different ship dates", expectedReason: "judge: different ship dates"},
+		{reply: "disagree: one ships Friday", expectedReason: "judge: one ships Friday"},
+		{reply: "I would not disagree, I AGREE: 1", expectedError: true},
+		{reply: "AGREE: 3", expectedError: true},
+	}
+
+	votes := []Vote{
+		{Member: "a", Status: StatusCompleted, Output: "Ship on Friday"},
+		{Member: "b", Status: StatusCompleted, Output: "Ship Friday"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reply, func(t *testing.T) {
+			strategy := judgeStrategy{judge: replyProvider{NewStubProvider(0), tt.reply}}
+			decision, err := strategy.Decide(context.Background(), Request{TaskType: "plan", Prompt: "When do we ship?"}, append([]Vote(nil), votes...))
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected no verdict, got %+v", decision)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decide failed: %v", err)
+			}
+			if decision.Reached != (tt.expectedOutput != "") || decision.Output != tt.expectedOutput ||
+				tt.expectedReason != "" && decision.Reason != tt.expectedReason {
+				t.Errorf("Expected output %q (reason %q), got %+v", tt.expectedOutput, tt.expectedReason, decision)
+			}
+		})
+	}
+}
+
+// TestJudgeQuorum tests that the judge is only asked once more than half of
+// the members have answered
+func TestJudgeQuorum(t *testing.T) {
+	tests := []struct {
+		members  int
+		failures int
+		reached  bool
+	}{
+		{members: 3, failures: 0, reached: true},
+		{members: 3, failures: 1, reached: true},
+		{members: 3, failures: 2, reached: false},
+		{members: 5, failures: 2, reached: true},
+		{members: 5, failures: 3, reached: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d of %d failed", tt.failures, tt.members), func(t *testing.T) {
+			quorum := &Quorum{Strategy: judgeStrategy{judge: NewStubProvider(0)}}
+			for i := 0; i < tt.members; i++ {
+				var provider Provider = NewStubProvider(0)
+				if i < tt.failures {
+					provider = failingProvider{NewStubProvider(0)}
+				}
+				quorum.Members = append(quorum.Members, Member{Name: fmt.Sprintf("member-%d", i), Provider: provider})
+			}
+
+			votes, decision, err := quorum.Run(context.Background(), Request{TaskType: "plan", Prompt: "Plan the launch"}, time.Millisecond)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if decision.Reached != tt.reached {
+				t.Errorf("Expected reached %v, got %+v", tt.reached, decision)
+			}
+			agreeing := 0
+			for _, vote := range votes {
+				if vote.Agrees {
+					agreeing++
+				}
+			}
+			if tt.reached && agreeing != tt.members-tt.failures || !tt.reached && agreeing != 0 {
+				t.Errorf("Unexpected agreeing votes: %+v", votes)
+			}
+		})
+	}
+}
+
+// TestParseMembers tests that the main API key only goes to members using
+// the main base URL
+func TestParseMembers(t *testing.T) {
+	t.Setenv("OTHER_API_KEY", "other-secret")
+	base := Config{Provider: ProviderOpenAI, BaseURL: "https://api.openai.com/v1", APIKey: "secret", Model: "gpt-4o-mini"}
+
+	members, err := ParseMembers("openai:gpt-4o, openai:llama3@http://localhost:11434/v1, openai@https://api.example.com/v1#OTHER_API_KEY", base)
+	if err != nil {
+		t.Fatalf("ParseMembers failed: %v", err)
+	}
+	expected := []struct {
+		baseURL string
+		apiKey  string
+		model   string
+	}{
+		{"https://api.openai.com/v1", "secret", "gpt-4o"},
+		{"http://localhost:11434/v1", "", "llama3"},
+		{"https://api.example.com/v1", "other-secret", "gpt-4o-mini"},
+	}
+	for i, want := range expected {
+		provider := members[i].Provider.(*OpenAIProvider)
+		if provider.baseURL != want.baseURL || provider.apiKey != want.apiKey || provider.model != want.model {
+			t.Errorf("Member %q: expected %+v, got %s %q %s", members[i].Name, want, provider.baseURL, provider.apiKey, provider.model)
+		}
+	}
+
+	if _, err := ParseMembers("openai@https://api.example.com/v1#MISSING_API_KEY, stub", base); err == nil {
+		t.Error("Expected an error for an unset key variable")
+	}
+}
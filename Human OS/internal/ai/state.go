@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"humanos-api/internal/models"
+)
+
+// TaskSummarizeState is the task type of a state summary request. Its prompt
+// is a JSON models.StatePackage and its reply a JSON models.StateBriefing.
+const TaskSummarizeState = "summarize-state"
+
+// Briefing limits
+const (
+	maxBriefingPriorities = 5
+	maxBriefingClosures   = 10
+)
+
+// SummarizeStateInstructions tells a provider how to answer a state summary
+const SummarizeStateInstructions = `You are helping a person get on top of everything they have open.
+The user message is a JSON list of their open loops, pending tasks, captured ideas and active threads.
+Reply with JSON only, in this shape:
+{"summary": "<two sentences>",
+ "priorities": [{"id": "<item id>", "kind": "<item kind>", "reason": "<why now>"}],
+ "closures": [{"id": "<item id>", "kind": "<item kind>", "action": "close|kill|archive|drop|terminate|background", "reason": "<why>"}]}
+List at most 5 priorities, most important first, and only suggest closures for items that are stale or no longer worth holding.`
+
+// SummarizeState builds a briefing from a state package by fixed rules. It
+// is what the stub provider answers with, so summaries work offline.
+//
+// Items are scored by priority, queue and age: high-priority action loops
+// and urgent, important tasks come first, and older items rise. Closures are
+// suggested for items that have sat too long where they are: backburner and
+// low-priority loops, reference loops that are really notes, low-value
+// tasks, ideas never acted on and background threads left running.
+func SummarizeState(pkg models.StatePackage) models.StateBriefing {
+	type scored struct {
+		item  models.StateItem
+		score int
+	}
+	var ranked []scored
+	var closures []models.ClosureSuggestion
+	counts := make(map[string]int)
+	highLoops := 0
+
+	for _, item := range pkg.Items {
+		counts[item.Kind]++
+		age := item.AgeDays
+		score := min(age, 14)
+		var closure *models.ClosureSuggestion
+		suggest := func(action, reason string) {
+			closure = &models.ClosureSuggestion{ID: item.ID, Kind: item.Kind, Text: item.Text, Action: action, Reason: reason}
+		}
+
+		switch item.Kind {
+		case "loop":
+			score += map[string]int{"high": 30, "medium": 20, "low": 10}[item.Priority]
+			score += map[string]int{"action": 10, "backburner": -10}[item.Queue]
+			if item.Priority == "high" {
+				highLoops++
+			}
+			switch {
+			case item.Queue == "backburner" && age > 14:
+				suggest("kill", fmt.Sprintf("On the backburner for %d days; drop it or schedule it", age))
+			case item.Queue == "reference" && age > 7:
+				suggest("archive", fmt.Sprintf("Reference material held open for %d days; archive it", age))
+			case item.Priority == "low" && age > 30:
+				suggest("kill", fmt.Sprintf("Low priority and open for %d days", age))
+			}
+		case "task":
+			urgency, importance, _ := strings.Cut(item.Priority, "/")
+			switch {
+			case urgency == "high" && importance == "high":
+				score += 40
+			case importance == "high":
+				score += 30
+			case urgency == "high":
+				score += 25
+			default:
+				score += 10
+			}
+			if urgency == "low" && importance == "low" && age > 14 {
+				suggest("drop", fmt.Sprintf("Neither urgent nor important, pending for %d days", age))
+			}
+		case "idea":
+			if item.Detail == "act now" {
+				score += 25
+			} else {
+				score += 5
+			}
+			if item.Detail != "act now" && age > 30 {
+				suggest("archive", fmt.Sprintf("Captured %d days ago and never acted on", age))
+			}
+		case "thread":
+			if item.Detail == "foreground" {
+				score += 15
+			} else {
+				score += 5
+				if age > 7 {
+					suggest("terminate", fmt.Sprintf("Running in the background for %d days", age))
+				}
+			}
+		}
+
+		if closure != nil {
+			closures = append(closures, *closure)
+		} else {
+			ranked = append(ranked, scored{item, score})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].item.ID < ranked[j].item.ID
+	})
+
+	briefing := models.StateBriefing{
+		Priorities: []models.BriefingItem{},
+		Closures:   []models.ClosureSuggestion{},
+	}
+	for i := 0; i < len(ranked) && i < maxBriefingPriorities; i++ {
+		item := ranked[i].item
+		briefing.Priorities = append(briefing.Priorities, models.BriefingItem{
+			ID: item.ID, Kind: item.Kind, Text: item.Text, Reason: briefingReason(item),
+		})
+	}
+	if len(closures) > maxBriefingClosures {
+		closures = closures[:maxBriefingClosures]
+	}
+	briefing.Closures = append(briefing.Closures, closures...)
+
+	briefing.Summary = fmt.Sprintf("%d open loop(s) (%d high priority), %d pending task(s), %d captured idea(s), %d active thread(s).",
+		counts["loop"], highLoops, counts["task"], counts["idea"], counts["thread"])
+	if len(briefing.Priorities) > 0 {
+		briefing.Summary += " Start with: " + briefing.Priorities[0].Text + "."
+	} else {
+		briefing.Summary += " Nothing is waiting on you."
+	}
+	return briefing
+}
+
+// briefingReason explains why a ranked item matters
+func briefingReason(item models.StateItem) string {
+	var reason string
+	switch item.Kind {
+	case "loop":
+		reason = item.Priority + "-priority " + item.Queue + " loop"
+		if item.Detail != "" {
+			reason += "; next step: " + item.Detail
+		}
+	case "task":
+		urgency, importance, _ := strings.Cut(item.Priority, "/")
+		reason = "task, " + urgency + " urgency and " + importance + " importance"
+	case "idea":
+		reason = "idea marked to act on now"
+		if item.Detail != "act now" {
+			reason = "captured idea waiting to be processed"
+		}
+	case "thread":
+		reason = item.Detail + " thread still running"
+	}
+	if item.AgeDays > 0 {
+		reason += fmt.Sprintf(", open %d day(s)", item.AgeDays)
+	}
+	return reason
+}
+
+// ParseBriefing reads a briefing from a provider's reply. Models often wrap
+// JSON in prose or code fences, so the outermost object is extracted.
+func ParseBriefing(output string) (*models.StateBriefing, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in reply")
+	}
+	var briefing models.StateBriefing
+	if err := json.Unmarshal([]byte(output[start:end+1]), &briefing); err != nil {
+		return nil, err
+	}
+	return &briefing, nil
+}
@@ -0,0 +1,170 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// stubParts splits a prompt into sentences, lines and list items
+var stubParts = regexp.MustCompile(`[.;!?\n]+\s*`)
+
+// StubProvider is a deterministic local provider. It never calls out to a
+// model: its output is built from the request by fixed rules, so the same
+// request always produces the same result.
+type StubProvider struct {
+	delay time.Duration
+	jobs  *jobs
+}
+
+// NewStubProvider creates a stub provider. Each job takes delay to finish,
+// which lets tests observe running and cancelled jobs; zero finishes
+// immediately.
+func NewStubProvider(delay time.Duration) *StubProvider {
+	return &StubProvider{delay: delay, jobs: newJobs()}
+}
+
+// Name identifies the provider on offload records
+func (p *StubProvider) Name() string {
+	return ProviderStub
+}
+
+// Submit starts a job that produces the stub output after the delay
+func (p *StubProvider) Submit(ctx context.Context, req Request) (string, error) {
+	return p.jobs.start(ctx, func(ctx context.Context) (string, error) {
+		if err := p.wait(ctx); err != nil {
+			return "", err
+		}
+		return StubOutput(req), nil
+	}), nil
+}
+
+// Poll returns a job's current state
+func (p *StubProvider) Poll(ctx context.Context, jobID string) (*Result, error) {
+	return p.jobs.poll(jobID)
+}
+
+// Cancel stops a running job
+func (p *StubProvider) Cancel(ctx context.Context, jobID string) error {
+	return p.jobs.cancel(jobID)
+}
+
+// Stream emits the stub output one line at a time
+func (p *StubProvider) Stream(ctx context.Context, req Request, chunk func(string)) (string, error) {
+	if err := p.wait(ctx); err != nil {
+		return "", err
+	}
+	output := StubOutput(req)
+	for _, line := range strings.SplitAfter(output, "\n") {
+		chunk(line)
+	}
+	return output, nil
+}
+
+// wait sleeps for the configured delay unless ctx ends first
+func (p *StubProvider) wait(ctx context.Context) error {
+	if p.delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(p.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// StubOutput builds the deterministic response the stub gives for a request
+func StubOutput(req Request) string {
+	// A stub judge agrees only with word-for-word matches
+	if req.TaskType == TaskJudge {
+		for _, candidate := range req.Candidates {
+			if NormalizeOutput(candidate) != NormalizeOutput(req.Candidates[0]) {
+				return "DISAGREE: the responses differ"
+			}
+		}
+		return "AGREE: 1"
+	}
+
+	// A stub state summary is worked out by rules
+	if req.TaskType == TaskSummarizeState {
+		var pkg models.StatePackage
+		if err := json.Unmarshal([]byte(req.Prompt), &pkg); err != nil {
+			return `{"summary": "The state package could not be read."}`
+		}
+		out, _ := json.MarshalIndent(SummarizeState(pkg), "", "  ")
+		return string(out)
+	}
+
+	var parts []string
+	for _, part := range stubParts.Split(req.Prompt, -1) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		parts = []string{"(empty scope)"}
+	}
+	subject := parts[0]
+
+	// Every assist type asks for a breakdown into steps
+	kind := req.TaskType
+	if strings.HasPrefix(kind, "assist:") {
+		kind = "assist"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[stub %s]\n", req.TaskType)
+	switch kind {
+	case "plan":
+		fmt.Fprintf(&b, "Plan: %s\n", subject)
+		steps := append([]string{"Define what done looks like"}, parts[1:]...)
+		steps = append(steps, "Do the smallest next step", "Review and adjust")
+		for i, step := range steps {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+		}
+	case "draft":
+		fmt.Fprintf(&b, "Draft: %s\n\n", subject)
+		for _, part := range parts {
+			fmt.Fprintf(&b, "%s.\n", part)
+		}
+	case "refactor":
+		fmt.Fprintf(&b, "Refactor: %s\n", subject)
+		b.WriteString("- Name the one behaviour that must not change\n")
+		b.WriteString("- Split the largest piece into smaller ones\n")
+		b.WriteString("- Remove what is no longer used\n")
+	case "summarize":
+		words := len(strings.Fields(req.Prompt))
+		fmt.Fprintf(&b, "Summary (%d points, %d words): %s\n", len(parts), words, subject)
+	case "explore":
+		fmt.Fprintf(&b, "Options for: %s\n", subject)
+		b.WriteString("A. Do it now, fully\n")
+		b.WriteString("B. Do a smaller version first\n")
+		b.WriteString("C. Defer or drop it\n")
+	case "assist":
+		fmt.Fprintf(&b, "Steps for: %s\n", subject)
+		steps := append([]string{"Get clear on what done looks like"}, parts[1:]...)
+		steps = append(steps, "Do the first small piece", "Check the result against what done looks like")
+		for i, step := range steps {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+		}
+	case TaskUnstick:
+		fmt.Fprintf(&b, "Unstick: %s\n", subject)
+		b.WriteString("- Write down exactly what is in the way\n")
+		b.WriteString("- Do a rough version of the step, badly, in ten minutes\n")
+		b.WriteString("- If it still will not move, ask someone or skip ahead and come back\n")
+	default:
+		fmt.Fprintf(&b, "Notes on: %s\n", subject)
+		for _, part := range parts[1:] {
+			fmt.Fprintf(&b, "- %s\n", part)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
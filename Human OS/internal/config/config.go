@@ -3,8 +3,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,6 +23,54 @@ type Config struct {
 
 	// Database
 	DatabasePath string
+
+	// Archiving
+	AutoArchive bool // archive loops closed as done and completed focus sessions
+
+	// Reset snapshots
+	SnapshotRetention int // snapshots kept for undoing resets, newest first
+
+	// Prediction scheduler
+	PredictionScanMinutes int // how often to check for predictions past their horizon
+	LowDepthGraceHours    int // how long a "low" prediction may sit unresolved before auto-stop
+
+	// Rumination guard (0 disables a cap or budget)
+	MaxRunningLowPredictions    int
+	MaxRunningMediumPredictions int
+	MaxRunningDeepPredictions   int
+	LowBudgetMinutes            int // thinking time before a running prediction is auto-stopped
+	MediumBudgetMinutes         int
+	DeepBudgetMinutes           int
+	RuminationRestartThreshold  int // restarts after a stop that flag a topic as rumination
+	RuminationWindowDays        int
+
+	// Emotional load
+	EmotionHalfLifeHours int // time for a tagged emotion's contribution to load to halve
+
+	// Recommendations
+	TimeZone string // IANA time zone used to judge time of day
+
+	// AI provider
+	AIProvider       string // "stub" (deterministic, offline) or "openai" (any OpenAI-compatible API)
+	AIBaseURL        string
+	AIAPIKey         string
+	AIModel          string
+	AITimeoutSeconds int // how long a single offload attempt may run
+
+	// AI offload queue
+	AIConcurrency         int            // running offloads per task type (0 = unlimited)
+	AITypeConcurrency     map[string]int // per task type overrides, from "explore=1,plan=3"
+	AIMaxAttempts         int
+	AIRetryBackoffSeconds int // delay before the first retry, doubled for each one after
+
+	// AI quorum
+	AIQuorumProviders string // "provider[:model][@base_url][#key_env]" entries, comma-separated
+	AIQuorumStrategy  string // "majority", "unanimity" or "judge"
+	AIQuorumJudge     string // provider entry for the judge strategy (empty = AI_PROVIDER)
+	AIQuorumRequired  bool   // run every offload in quorum mode
+
+	// Personal context
+	TelosPath string // Telos file imported at startup (empty = keep the stored context)
 }
 
 // Load reads configuration from environment variables and .env file
@@ -32,6 +83,62 @@ func Load() (*Config, error) {
 		Env:          getEnv("ENV", "development"),
 		LogLevel:     getEnv("LOG_LEVEL", "info"),
 		DatabasePath: getEnv("DATABASE_PATH", "./humanOS.db"),
+		AutoArchive:  getEnvAsBool("AUTO_ARCHIVE", true),
+
+		SnapshotRetention: getEnvAsInt("SNAPSHOT_RETENTION", 20),
+
+		PredictionScanMinutes: getEnvAsInt("PREDICTION_SCAN_MINUTES", 15),
+		LowDepthGraceHours:    getEnvAsInt("LOW_DEPTH_GRACE_HOURS", 24),
+
+		MaxRunningLowPredictions:    getEnvAsInt("MAX_RUNNING_LOW_PREDICTIONS", 5),
+		MaxRunningMediumPredictions: getEnvAsInt("MAX_RUNNING_MEDIUM_PREDICTIONS", 3),
+		MaxRunningDeepPredictions:   getEnvAsInt("MAX_RUNNING_DEEP_PREDICTIONS", 1),
+		LowBudgetMinutes:            getEnvAsInt("LOW_PREDICTION_BUDGET_MINUTES", 30),
+		MediumBudgetMinutes:         getEnvAsInt("MEDIUM_PREDICTION_BUDGET_MINUTES", 240),
+		DeepBudgetMinutes:           getEnvAsInt("DEEP_PREDICTION_BUDGET_MINUTES", 1440),
+		RuminationRestartThreshold:  getEnvAsInt("RUMINATION_RESTART_THRESHOLD", 2),
+		RuminationWindowDays:        getEnvAsInt("RUMINATION_WINDOW_DAYS", 7),
+
+		EmotionHalfLifeHours: getEnvAsInt("EMOTION_HALF_LIFE_HOURS", 4),
+
+		TimeZone: getEnv("TIMEZONE", "UTC"),
+
+		AIProvider:       getEnv("AI_PROVIDER", "stub"),
+		AIBaseURL:        getEnv("AI_BASE_URL", "https://api.openai.com/v1"),
+		AIAPIKey:         getEnv("AI_API_KEY", ""),
+		AIModel:          getEnv("AI_MODEL", "gpt-4o-mini"),
+		AITimeoutSeconds: getEnvAsInt("AI_TIMEOUT_SECONDS", 120),
+
+		AIConcurrency:         getEnvAsInt("AI_CONCURRENCY", 2),
+		AIMaxAttempts:         getEnvAsInt("AI_MAX_ATTEMPTS", 3),
+		AIRetryBackoffSeconds: getEnvAsInt("AI_RETRY_BACKOFF_SECONDS", 30),
+
+		AIQuorumProviders: getEnv("AI_QUORUM_PROVIDERS", ""),
+		AIQuorumStrategy:  getEnv("AI_QUORUM_STRATEGY", "majority"),
+		AIQuorumJudge:     getEnv("AI_QUORUM_JUDGE", ""),
+		AIQuorumRequired:  getEnvAsBool("AI_QUORUM_REQUIRED", false),
+
+		TelosPath: getEnv("TELOS_PATH", ""),
+	}
+
+	if _, err := time.LoadLocation(cfg.TimeZone); err != nil {
+		return nil, fmt.Errorf("invalid TIMEZONE %q: %w", cfg.TimeZone, err)
+	}
+	if cfg.SnapshotRetention < 1 {
+		return nil, fmt.Errorf("invalid SNAPSHOT_RETENTION %d: at least one snapshot must be kept", cfg.SnapshotRetention)
+	}
+	if cfg.AIProvider != "stub" && cfg.AIProvider != "openai" {
+		return nil, fmt.Errorf("invalid AI_PROVIDER %q: must be stub or openai", cfg.AIProvider)
+	}
+	typeConcurrency, err := parseIntMap(getEnv("AI_TYPE_CONCURRENCY", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_TYPE_CONCURRENCY: %w", err)
+	}
+	cfg.AITypeConcurrency = typeConcurrency
+	switch cfg.AIQuorumStrategy {
+	case "majority", "unanimity", "judge":
+	default:
+		return nil, fmt.Errorf("invalid AI_QUORUM_STRATEGY %q: must be majority, unanimity or judge", cfg.AIQuorumStrategy)
 	}
 
 	return cfg, nil
@@ -63,6 +170,26 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// parseIntMap parses a comma-separated list of key=integer pairs
+func parseIntMap(value string) (map[string]int, error) {
+	result := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not key=value", pair)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%q: value is not a number", pair)
+		}
+		result[strings.TrimSpace(key)] = n
+	}
+	return result, nil
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Env == "development"
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"strings"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// ARCHIVE SEARCH
+// ============================================================================
+
+// archiveSearchSchema creates the FTS5 index over archives and the triggers
+// that keep it in sync. The index stores its own copy of the text keyed by
+// archive_id rather than using external content, because rowids of the
+// archives table are not stable across VACUUM.
+const archiveSearchSchema = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS archives_fts USING fts5(
+		archive_id UNINDEXED,
+		object,
+		summary,
+		lesson,
+		tokenize = 'porter unicode61'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS archives_fts_insert AFTER INSERT ON archives BEGIN
+		INSERT INTO archives_fts (archive_id, object, summary, lesson)
+		VALUES (new.id, new.object, new.summary, COALESCE(new.lesson, ''));
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS archives_fts_delete AFTER DELETE ON archives BEGIN
+		DELETE FROM archives_fts WHERE archive_id = old.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS archives_fts_update AFTER UPDATE ON archives BEGIN
+		DELETE FROM archives_fts WHERE archive_id = old.id;
+		INSERT INTO archives_fts (archive_id, object, summary, lesson)
+		VALUES (new.id, new.object, new.summary, COALESCE(new.lesson, ''));
+	END;
+`
+
+// archiveSearchTriggers are the triggers archiveSearchSchema creates
+var archiveSearchTriggers = []string{"archives_fts_insert", "archives_fts_delete", "archives_fts_update"}
+
+// initArchiveSearch creates the archive full-text index if the SQLite build
+// supports FTS5 (go-sqlite3 needs the sqlite_fts5 build tag). Without it,
+// search falls back to LIKE matching.
+func (db *DB) initArchiveSearch() error {
+	var enabled int
+	if err := db.conn.QueryRow(
+		"SELECT sqlite_compileoption_used('ENABLE_FTS5')",
+	).Scan(&enabled); err != nil {
+		return err
+	}
+
+	var existing, triggers int
+	if err := db.conn.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'archives_fts'",
+	).Scan(&existing); err != nil {
+		return err
+	}
+	if err := db.conn.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'archives_fts_%'",
+	).Scan(&triggers); err != nil {
+		return err
+	}
+
+	if enabled == 0 {
+		// A build with FTS5 may have left its triggers in this file. Writing
+		// to archives would fail with "no such module: fts5", so drop them;
+		// the index is rebuilt the next time an FTS5 build opens the file.
+		for _, trigger := range archiveSearchTriggers {
+			if _, err := db.conn.Exec("DROP TRIGGER IF EXISTS " + trigger); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := db.conn.Exec(archiveSearchSchema); err != nil {
+		return err
+	}
+
+	// Index archives committed before the index existed, or while a build
+	// without FTS5 had dropped the triggers that keep it in sync
+	if existing == 0 || triggers < len(archiveSearchTriggers) {
+		if _, err := db.conn.Exec(`
+			DELETE FROM archives_fts;
+			INSERT INTO archives_fts (archive_id, object, summary, lesson)
+			SELECT id, object, summary, COALESCE(lesson, '') FROM archives
+		`); err != nil {
+			return err
+		}
+	}
+
+	db.ftsEnabled = true
+	return nil
+}
+
+// SearchArchives performs a ranked full-text search over archive objects,
+// summaries and lessons. All terms must match. Results are ordered best first.
+func (db *DB) SearchArchives(query string, limit int) ([]models.ArchiveSearchResult, error) {
+	return db.searchArchives(query, limit, false)
+}
+
+// searchArchives runs the search against the FTS5 index when available, or a
+// LIKE scan otherwise. When anyTerm is set, a match on any single term is enough.
+func (db *DB) searchArchives(query string, limit int, anyTerm bool) ([]models.ArchiveSearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.ftsEnabled {
+		return db.searchArchivesFTS(terms, limit, anyTerm)
+	}
+	return db.searchArchivesLike(terms, limit, anyTerm)
+}
+
+// searchArchivesFTS ranks matches with BM25, weighting the lesson and object
+// above the summary, and returns a highlighted snippet of the best column.
+func (db *DB) searchArchivesFTS(terms []string, limit int, anyTerm bool) ([]models.ArchiveSearchResult, error) {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		// Quote each term so user input can never be parsed as FTS syntax
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+	joiner := " "
+	if anyTerm {
+		joiner = " OR "
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT a.id, a.object, a.summary, COALESCE(a.lesson, ''),
+		       COALESCE(a.source_type, ''), COALESCE(a.source_id, ''), a.created_at,
+		       bm25(archives_fts, 0.0, 2.0, 1.0, 2.0) AS rank,
+		       snippet(archives_fts, -1, '[', ']', '...', 12)
+		FROM archives_fts
+		JOIN archives a ON a.id = archives_fts.archive_id
+		WHERE archives_fts MATCH ?
+		ORDER BY rank
+		LIMIT ?
+	`, strings.Join(quoted, joiner), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []models.ArchiveSearchResult
+	for rows.Next() {
+		var r models.ArchiveSearchResult
+		var rank float64
+		if err := rows.Scan(
+			&r.Archive.ID, &r.Archive.Object, &r.Archive.Summary, &r.Archive.Lesson,
+			&r.Archive.SourceType, &r.Archive.SourceID, &r.Archive.CreatedAt, &rank, &r.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		// bm25 is lower-is-better and negative; expose a positive score
+		r.Score = -rank
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// searchArchivesLike is the fallback when FTS5 is unavailable. Score is the
+// number of matched terms, with lesson and object hits counting double.
+func (db *DB) searchArchivesLike(terms []string, limit int, anyTerm bool) ([]models.ArchiveSearchResult, error) {
+	var (
+		conditions    []string
+		scoreParts    []string
+		conditionArgs []interface{}
+		scoreArgs     []interface{}
+	)
+	for _, term := range terms {
+		pattern := "%" + term + "%"
+		conditions = append(conditions,
+			"(object LIKE ? OR summary LIKE ? OR COALESCE(lesson, '') LIKE ?)")
+		scoreParts = append(scoreParts,
+			"(object LIKE ?) * 2 + (summary LIKE ?) + (COALESCE(lesson, '') LIKE ?) * 2")
+		conditionArgs = append(conditionArgs, pattern, pattern, pattern)
+		scoreArgs = append(scoreArgs, pattern, pattern, pattern)
+	}
+	args := append(scoreArgs, conditionArgs...)
+
+	joiner := " AND "
+	if anyTerm {
+		joiner = " OR "
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT id, object, summary, COALESCE(lesson, ''),
+		       COALESCE(source_type, ''), COALESCE(source_id, ''), created_at,
+		       (`+strings.Join(scoreParts, " + ")+`) AS score
+		FROM archives
+		WHERE `+strings.Join(conditions, joiner)+`
+		ORDER BY score DESC, created_at DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []models.ArchiveSearchResult
+	for rows.Next() {
+		var r models.ArchiveSearchResult
+		if err := rows.Scan(
+			&r.Archive.ID, &r.Archive.Object, &r.Archive.Summary, &r.Archive.Lesson,
+			&r.Archive.SourceType, &r.Archive.SourceID, &r.Archive.CreatedAt, &r.Score,
+		); err != nil {
+			return nil, err
+		}
+		r.Snippet = likeSnippet(r.Archive, terms)
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// searchTerms splits a free-text query into lowercase search terms
+func searchTerms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !(r == '\'' || r == '-' || r == '_' ||
+			(r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r > 127)
+	})
+	terms := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.Trim(f, "'-_"); f != "" {
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
+
+// likeSnippet picks the first field containing a term and highlights it the
+// same way the FTS5 snippet function does
+func likeSnippet(archive models.Archive, terms []string) string {
+	for _, text := range []string{archive.Lesson, archive.Object, archive.Summary} {
+		lower := strings.ToLower(text)
+		if len(lower) != len(text) {
+			// Case folding changed byte offsets; highlighting would misalign
+			continue
+		}
+		for _, term := range terms {
+			if idx := strings.Index(lower, term); idx >= 0 {
+				return text[:idx] + "[" + text[idx:idx+len(term)] + "]" + text[idx+len(term):]
+			}
+		}
+	}
+	return archive.Summary
+}
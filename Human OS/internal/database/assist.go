@@ -0,0 +1,329 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// ASSIST SESSION OPERATIONS
+// ============================================================================
+
+// assistSessionColumns is the column list read by scanAssistSession
+const assistSessionColumns = `
+	id, task, assistance_type, COALESCE(focus_id, ''), COALESCE(offload_id, ''),
+	source, status, completed_at, created_at, updated_at`
+
+// scanAssistSession reads a row selected with assistSessionColumns
+func scanAssistSession(scan func(dest ...interface{}) error) (*models.AssistSession, error) {
+	var session models.AssistSession
+	var completedAt sql.NullTime
+	if err := scan(
+		&session.ID, &session.Task, &session.AssistanceType, &session.FocusID, &session.OffloadID,
+		&session.Source, &session.Status, &completedAt, &session.CreatedAt, &session.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		session.CompletedAt = &completedAt.Time
+	}
+	return &session, nil
+}
+
+// CreateAssistSession creates an assist session along with any steps it
+// already has
+func (db *DB) CreateAssistSession(session *models.AssistSession) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO assist_sessions (id, task, assistance_type, focus_id, offload_id, source, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, session.ID, session.Task, session.AssistanceType, session.FocusID, session.OffloadID,
+		session.Source, session.Status, session.CreatedAt, session.UpdatedAt); err != nil {
+		return err
+	}
+	if err := insertAssistSteps(tx, session.Steps); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertAssistSteps inserts a session's steps within a transaction
+func insertAssistSteps(tx *sql.Tx, steps []models.AssistStep) error {
+	for _, step := range steps {
+		if _, err := tx.Exec(`
+			INSERT INTO assist_steps (id, session_id, position, description, status, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, step.ID, step.SessionID, step.Position, step.Description, step.Status,
+			step.UpdatedAt, step.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAssistSession retrieves an assist session with its steps
+func (db *DB) GetAssistSession(id string) (*models.AssistSession, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	sessions, err := db.queryAssistSessions(
+		"SELECT"+assistSessionColumns+" FROM assist_sessions WHERE id = ?", id,
+	)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
+// GetAssistSessions returns assist sessions with their steps, newest first,
+// optionally filtered by status
+func (db *DB) GetAssistSessions(status string, limit int) ([]models.AssistSession, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := "SELECT" + assistSessionColumns + " FROM assist_sessions"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	return db.queryAssistSessions(query, args...)
+}
+
+// GetFocusAssistSession returns the most recently started unfinished assist
+// session linked to a focus, or nil if there is none
+func (db *DB) GetFocusAssistSession(focusID string) (*models.AssistSession, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	sessions, err := db.queryAssistSessions(
+		"SELECT"+assistSessionColumns+` FROM assist_sessions
+		WHERE focus_id = ? AND status IN ('planning', 'active')
+		ORDER BY created_at DESC LIMIT 1`, focusID,
+	)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
+// GetPlanningAssistSession returns the session still planning from a
+// breakdown offload, or nil if there is none
+func (db *DB) GetPlanningAssistSession(offloadID string) (*models.AssistSession, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	sessions, err := db.queryAssistSessions(
+		"SELECT"+assistSessionColumns+` FROM assist_sessions
+		WHERE offload_id = ? AND status = 'planning'`, offloadID,
+	)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
+// queryAssistSessions runs a query selecting assistSessionColumns and loads
+// each session's steps. Caller must hold the lock.
+func (db *DB) queryAssistSessions(query string, args ...interface{}) ([]models.AssistSession, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.AssistSession
+	for rows.Next() {
+		session, err := scanAssistSession(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range sessions {
+		if err := db.loadAssistSteps(&sessions[i]); err != nil {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
+
+// loadAssistSteps attaches a session's steps in order, with the status and
+// result of any help requested for them, and counts the finished ones.
+// Caller must hold the lock.
+func (db *DB) loadAssistSteps(session *models.AssistSession) error {
+	rows, err := db.conn.Query(`
+		SELECT s.id, s.session_id, s.position, s.description, s.status, COALESCE(s.stuck_on, ''),
+		       COALESCE(s.help_offload_id, ''), COALESCE(o.status, ''), COALESCE(o.result, ''),
+		       s.done_at, s.updated_at
+		FROM assist_steps s
+		LEFT JOIN ai_offloads o ON o.id = s.help_offload_id
+		WHERE s.session_id = ?
+		ORDER BY s.position ASC
+	`, session.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	session.Steps = []models.AssistStep{}
+	session.StepsDone = 0
+	for rows.Next() {
+		var step models.AssistStep
+		var doneAt sql.NullTime
+		if err := rows.Scan(
+			&step.ID, &step.SessionID, &step.Position, &step.Description, &step.Status, &step.StuckOn,
+			&step.HelpOffloadID, &step.HelpStatus, &step.Help, &doneAt, &step.UpdatedAt,
+		); err != nil {
+			return err
+		}
+		if doneAt.Valid {
+			step.DoneAt = &doneAt.Time
+		}
+		if step.Status == "done" {
+			session.StepsDone++
+		}
+		session.Steps = append(session.Steps, step)
+	}
+	return rows.Err()
+}
+
+// SetAssistSteps gives a session that is still planning its steps and makes
+// it active. It affects no rows if the session already has its steps.
+func (db *DB) SetAssistSteps(sessionID, source string, steps []models.AssistStep, now time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE assist_sessions SET status = 'active', source = ?, updated_at = ?
+		WHERE id = ? AND status = 'planning'
+	`, source, now, sessionID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, err
+	}
+
+	if err := insertAssistSteps(tx, steps); err != nil {
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// CompleteAssistStep checks off a step of an active session, completing the
+// session once every step is done. It affects no rows if the step is
+// already done or the session is not active.
+func (db *DB) CompleteAssistStep(sessionID string, position int, now time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE assist_steps SET status = 'done', done_at = ?, updated_at = ?
+		WHERE session_id = ? AND position = ? AND status != 'done'
+		  AND session_id IN (SELECT id FROM assist_sessions WHERE status = 'active')
+	`, now, now, sessionID, position)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE assist_sessions SET updated_at = ? WHERE id = ?
+	`, now, sessionID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE assist_sessions SET status = 'completed', completed_at = ?
+		WHERE id = ? AND NOT EXISTS (
+			SELECT 1 FROM assist_steps WHERE session_id = ? AND status != 'done'
+		)
+	`, now, sessionID, sessionID); err != nil {
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// MarkAssistStepStuck flags a step of an active session as stuck, recording
+// what is in the way, and queues the help request for it in the same
+// transaction. It returns the help request this one replaces, if any, and
+// affects no rows, queueing nothing, if the step is already done or the
+// session is not active.
+func (db *DB) MarkAssistStepStuck(sessionID string, position int, note string, help *models.AIOffload, now time.Time) (int64, string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, "", err
+	}
+	defer tx.Rollback()
+
+	var replaced string
+	err = tx.QueryRow(`
+		SELECT COALESCE(help_offload_id, '') FROM assist_steps WHERE session_id = ? AND position = ?
+	`, sessionID, position).Scan(&replaced)
+	if err == sql.ErrNoRows {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE assist_steps SET status = 'stuck', stuck_on = ?, help_offload_id = ?, updated_at = ?
+		WHERE session_id = ? AND position = ? AND status != 'done'
+		  AND session_id IN (SELECT id FROM assist_sessions WHERE status = 'active')
+	`, note, help.ID, now, sessionID, position)
+	if err != nil {
+		return 0, "", err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, "", err
+	}
+
+	if err := insertAIOffload(tx, help); err != nil {
+		return 0, "", err
+	}
+	if _, err := tx.Exec(`
+		UPDATE assist_sessions SET updated_at = ? WHERE id = ?
+	`, now, sessionID); err != nil {
+		return 0, "", err
+	}
+	return affected, replaced, tx.Commit()
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// CLASSIFICATION RULE OPERATIONS
+// ============================================================================
+
+// CreateClassificationRule creates a new task classification rule
+func (db *DB) CreateClassificationRule(rule *models.ClassificationRule) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	_, err := db.conn.Exec(`
+		INSERT INTO classification_rules (id, name, match_type, pattern, category, urgency,
+		                                  importance, queue, position, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.ID, rule.Name, rule.MatchType, rule.Pattern, rule.Category, rule.Urgency,
+		rule.Importance, rule.Queue, rule.Position, rule.Enabled, rule.CreatedAt, rule.UpdatedAt)
+	return err
+}
+
+// GetClassificationRule retrieves a classification rule by ID
+func (db *DB) GetClassificationRule(id string) (*models.ClassificationRule, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var rule models.ClassificationRule
+	err := db.conn.QueryRow(`
+		SELECT id, name, match_type, pattern, category, urgency, importance, queue,
+		       position, enabled, created_at, updated_at
+		FROM classification_rules WHERE id = ?
+	`, id).Scan(
+		&rule.ID, &rule.Name, &rule.MatchType, &rule.Pattern, &rule.Category, &rule.Urgency,
+		&rule.Importance, &rule.Queue, &rule.Position, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetClassificationRules returns all classification rules in evaluation order.
+// When enabledOnly is set, disabled rules are skipped.
+func (db *DB) GetClassificationRules(enabledOnly bool) ([]models.ClassificationRule, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := `
+		SELECT id, name, match_type, pattern, category, urgency, importance, queue,
+		       position, enabled, created_at, updated_at
+		FROM classification_rules`
+	if enabledOnly {
+		query += " WHERE enabled = 1"
+	}
+	query += " ORDER BY position ASC, created_at ASC"
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.ClassificationRule
+	for rows.Next() {
+		var rule models.ClassificationRule
+		if err := rows.Scan(
+			&rule.ID, &rule.Name, &rule.MatchType, &rule.Pattern, &rule.Category, &rule.Urgency,
+			&rule.Importance, &rule.Queue, &rule.Position, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// UpdateClassificationRule replaces the editable fields of a classification rule
+func (db *DB) UpdateClassificationRule(rule *models.ClassificationRule) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	rule.UpdatedAt = time.Now().UTC()
+	result, err := db.conn.Exec(`
+		UPDATE classification_rules
+		SET name = ?, match_type = ?, pattern = ?, category = ?, urgency = ?, importance = ?,
+		    queue = ?, position = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.Name, rule.MatchType, rule.Pattern, rule.Category, rule.Urgency, rule.Importance,
+		rule.Queue, rule.Position, rule.Enabled, rule.UpdatedAt, rule.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteClassificationRule removes a classification rule
+func (db *DB) DeleteClassificationRule(id string) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec("DELETE FROM classification_rules WHERE id = ?", id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
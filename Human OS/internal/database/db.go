@@ -17,6 +17,10 @@ import (
 type DB struct {
 	conn *sql.DB
 	mu   sync.RWMutex
+
+	// ftsEnabled is true when the SQLite build supports FTS5 and the
+	// archive full-text index is available
+	ftsEnabled bool
 }
 
 // New creates a new database connection and initializes the schema
@@ -34,11 +38,40 @@ func New(path string) (*DB, error) {
 
 	db := &DB{conn: conn}
 
+	// Default prompt templates are only installed along with their table, so
+	// templates the user deleted stay deleted
+	hadTemplates, err := db.tableExists("prompt_templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+
 	// Initialize schema
 	if err := db.initSchema(); err != nil {
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
 
+	// Bring databases created by earlier versions up to date
+	if err := db.migrate(); err != nil {
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	// Install the default emotion taxonomy on first run
+	if err := db.seedEmotionTaxonomy(); err != nil {
+		return nil, fmt.Errorf("failed to seed emotion taxonomy: %w", err)
+	}
+
+	// Install the default prompt templates on first run
+	if !hadTemplates {
+		if err := db.seedPromptTemplates(); err != nil {
+			return nil, fmt.Errorf("failed to seed prompt templates: %w", err)
+		}
+	}
+
+	// Build the archive full-text index when FTS5 is compiled in
+	if err := db.initArchiveSearch(); err != nil {
+		return nil, fmt.Errorf("failed to initialize archive search: %w", err)
+	}
+
 	return db, nil
 }
 
@@ -59,8 +92,11 @@ func (db *DB) initSchema() error {
 		is_locked INTEGER DEFAULT 0,
 		timebox TEXT,
 		fallback TEXT,
+		status TEXT NOT NULL DEFAULT 'active',
 		started_at DATETIME NOT NULL,
 		ends_at DATETIME,
+		completed_at DATETIME,
+		goal_id TEXT,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);
@@ -76,6 +112,7 @@ func (db *DB) initSchema() error {
 		closure_type TEXT,
 		next_step TEXT,
 		closed_at DATETIME,
+		goal_id TEXT,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);
@@ -88,6 +125,7 @@ func (db *DB) initSchema() error {
 		time_scope TEXT NOT NULL,
 		goal TEXT,
 		status TEXT NOT NULL DEFAULT 'active',
+		goal_id TEXT,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);
@@ -99,7 +137,10 @@ func (db *DB) initSchema() error {
 		category TEXT NOT NULL,
 		urgency TEXT NOT NULL,
 		importance TEXT NOT NULL,
+		queue TEXT,
+		rule_id TEXT,
 		status TEXT NOT NULL DEFAULT 'pending',
+		goal_id TEXT,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);
@@ -121,6 +162,8 @@ func (db *DB) initSchema() error {
 		object TEXT NOT NULL,
 		summary TEXT NOT NULL,
 		lesson TEXT,
+		source_type TEXT,
+		source_id TEXT,
 		created_at DATETIME NOT NULL
 	);
 
@@ -128,22 +171,87 @@ func (db *DB) initSchema() error {
 	CREATE TABLE IF NOT EXISTS predictions (
 		id TEXT PRIMARY KEY,
 		scenario TEXT NOT NULL,
+		topic TEXT,
 		time_horizon TEXT NOT NULL,
+		resolve_by DATETIME,
+		budget_ends_at DATETIME,
 		depth TEXT NOT NULL,
 		status TEXT NOT NULL DEFAULT 'running',
 		results TEXT,
+		resolved_at DATETIME,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);
 
+	-- Prediction forecasts table (scorable probability estimates)
+	CREATE TABLE IF NOT EXISTS prediction_forecasts (
+		id TEXT PRIMARY KEY,
+		prediction_id TEXT NOT NULL,
+		statement TEXT NOT NULL,
+		probability REAL NOT NULL,
+		occurred INTEGER,
+		resolved_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+
+	-- Prediction stops table (topics halted via stop, for rumination tracking)
+	CREATE TABLE IF NOT EXISTS prediction_stops (
+		id TEXT PRIMARY KEY,
+		topic TEXT NOT NULL,
+		stopped INTEGER NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	-- Scenario branches table (prediction scenario trees)
+	CREATE TABLE IF NOT EXISTS scenario_branches (
+		id TEXT PRIMARY KEY,
+		prediction_id TEXT NOT NULL,
+		parent_id TEXT,
+		condition TEXT NOT NULL,
+		outcome TEXT NOT NULL,
+		probability REAL NOT NULL,
+		response TEXT,
+		value REAL,
+		created_at DATETIME NOT NULL
+	);
+
 	-- Emotional states table
 	CREATE TABLE IF NOT EXISTS emotional_states (
 		id TEXT PRIMARY KEY,
 		label TEXT NOT NULL,
 		source_guess TEXT NOT NULL,
+		intensity INTEGER,
 		created_at DATETIME NOT NULL
 	);
 
+	-- Emotion taxonomy table (configurable labels and load weights)
+	CREATE TABLE IF NOT EXISTS emotion_taxonomy (
+		label TEXT PRIMARY KEY,
+		valence REAL NOT NULL,
+		arousal REAL NOT NULL,
+		load_weight REAL NOT NULL,
+		synonyms TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Triggers table (named people, projects and contexts behind emotions)
+	CREATE TABLE IF NOT EXISTS triggers (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		kind TEXT NOT NULL,
+		aliases TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Emotion triggers table (the triggers each emotional state was blamed on)
+	CREATE TABLE IF NOT EXISTS emotion_triggers (
+		state_id TEXT NOT NULL,
+		trigger_id TEXT NOT NULL,
+		PRIMARY KEY (state_id, trigger_id)
+	);
+
 	-- Decompress sessions table
 	CREATE TABLE IF NOT EXISTS decompress_sessions (
 		id TEXT PRIMARY KEY,
@@ -152,6 +260,9 @@ func (db *DB) initSchema() error {
 		status TEXT NOT NULL DEFAULT 'active',
 		started_at DATETIME NOT NULL,
 		ends_at DATETIME NOT NULL,
+		ended_at DATETIME,
+		pre_state_id TEXT,
+		post_state_id TEXT,
 		created_at DATETIME NOT NULL
 	);
 
@@ -161,22 +272,265 @@ func (db *DB) initSchema() error {
 		task_type TEXT NOT NULL,
 		scope TEXT NOT NULL,
 		status TEXT NOT NULL DEFAULT 'pending',
+		provider TEXT,
+		result TEXT,
+		error TEXT,
+		completed_at DATETIME,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME,
+		started_at DATETIME,
+		quorum INTEGER NOT NULL DEFAULT 0,
+		strategy TEXT,
+		template_version INTEGER NOT NULL DEFAULT 0,
+		instructions TEXT,
+		prompt TEXT,
+		feedback TEXT,
+		skip_review INTEGER NOT NULL DEFAULT 0,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);
 
+	-- Quorum votes: each provider's answer to each attempt of a quorum offload
+	CREATE TABLE IF NOT EXISTS ai_offload_votes (
+		id TEXT PRIMARY KEY,
+		offload_id TEXT NOT NULL,
+		attempt INTEGER NOT NULL,
+		member TEXT NOT NULL,
+		status TEXT NOT NULL,
+		output TEXT,
+		error TEXT,
+		agrees INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL
+	);
+
+	-- Prompt templates: every saved version of each offload task type's prompt
+	CREATE TABLE IF NOT EXISTS prompt_templates (
+		id TEXT PRIMARY KEY,
+		task_type TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		instructions TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		UNIQUE (task_type, version)
+	);
+
+	-- Review audit: who approved, edited or rejected each offload result
+	CREATE TABLE IF NOT EXISTS ai_offload_reviews (
+		id TEXT PRIMARY KEY,
+		offload_id TEXT NOT NULL,
+		action TEXT NOT NULL,
+		reviewer TEXT NOT NULL,
+		feedback TEXT,
+		ai_result TEXT NOT NULL,
+		accepted_result TEXT,
+		created_at DATETIME NOT NULL
+	);
+
+	-- Assist sessions: tasks being executed with AI support, step by step
+	CREATE TABLE IF NOT EXISTS assist_sessions (
+		id TEXT PRIMARY KEY,
+		task TEXT NOT NULL,
+		assistance_type TEXT NOT NULL,
+		focus_id TEXT,
+		offload_id TEXT,
+		source TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'planning',
+		completed_at DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Assist steps: the checklist of each assist session
+	CREATE TABLE IF NOT EXISTS assist_steps (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		description TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		stuck_on TEXT,
+		help_offload_id TEXT,
+		done_at DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		UNIQUE (session_id, position)
+	);
+
+	-- Telos items: the imported personal context, keyed by IDs like P1, M1, G1
+	CREATE TABLE IF NOT EXISTS telos_items (
+		id TEXT PRIMARY KEY,
+		section TEXT NOT NULL,
+		text TEXT NOT NULL,
+		refs TEXT,
+		position INTEGER NOT NULL,
+		imported_at DATETIME NOT NULL
+	);
+
+	-- Classification rules table (auto-classification of ingested tasks)
+	CREATE TABLE IF NOT EXISTS classification_rules (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		match_type TEXT NOT NULL,
+		pattern TEXT NOT NULL,
+		category TEXT NOT NULL,
+		urgency TEXT NOT NULL,
+		importance TEXT NOT NULL,
+		queue TEXT NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Lesson resurfacings table (lessons shown when starting related work)
+	CREATE TABLE IF NOT EXISTS lesson_resurfacings (
+		id TEXT PRIMARY KEY,
+		archive_id TEXT NOT NULL,
+		context_type TEXT NOT NULL,
+		context_id TEXT NOT NULL,
+		score REAL NOT NULL,
+		useful INTEGER,
+		rated_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+
+	-- State snapshots table (copies of every table taken before a reset)
+	CREATE TABLE IF NOT EXISTS state_snapshots (
+		id TEXT PRIMARY KEY,
+		reason TEXT NOT NULL,
+		row_count INTEGER NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	-- Snapshot tables table (one table's rows in a snapshot, as a JSON array)
+	CREATE TABLE IF NOT EXISTS state_snapshot_tables (
+		snapshot_id TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		columns TEXT NOT NULL,
+		row_count INTEGER NOT NULL,
+		rows TEXT NOT NULL,
+		PRIMARY KEY (snapshot_id, table_name)
+	);
+
 	-- Create indexes for common queries
 	CREATE INDEX IF NOT EXISTS idx_loops_status ON loops(status);
 	CREATE INDEX IF NOT EXISTS idx_threads_status ON threads(status);
 	CREATE INDEX IF NOT EXISTS idx_threads_mode ON threads(mode);
 	CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
 	CREATE INDEX IF NOT EXISTS idx_predictions_status ON predictions(status);
+	CREATE INDEX IF NOT EXISTS idx_ai_offloads_status ON ai_offloads(status);
+	CREATE INDEX IF NOT EXISTS idx_ai_offload_votes_offload ON ai_offload_votes(offload_id);
+	CREATE INDEX IF NOT EXISTS idx_ai_offload_reviews_offload ON ai_offload_reviews(offload_id);
+	CREATE INDEX IF NOT EXISTS idx_assist_sessions_focus ON assist_sessions(focus_id, status);
+	CREATE INDEX IF NOT EXISTS idx_prediction_forecasts_prediction ON prediction_forecasts(prediction_id);
+	CREATE INDEX IF NOT EXISTS idx_scenario_branches_prediction ON scenario_branches(prediction_id);
+	CREATE INDEX IF NOT EXISTS idx_lesson_resurfacings_archive ON lesson_resurfacings(archive_id);
+	CREATE INDEX IF NOT EXISTS idx_emotion_triggers_trigger ON emotion_triggers(trigger_id);
 	`
 
 	_, err := db.conn.Exec(schema)
 	return err
 }
 
+// columnMigration describes a column added to an existing table after its
+// initial release. CREATE TABLE IF NOT EXISTS leaves old tables untouched,
+// so new columns must be added explicitly.
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// columnMigrations lists every column added since the original schema
+var columnMigrations = []columnMigration{
+	{"tasks", "queue", "TEXT"},
+	{"tasks", "rule_id", "TEXT"},
+	{"focus_state", "status", "TEXT NOT NULL DEFAULT 'active'"},
+	{"focus_state", "completed_at", "DATETIME"},
+	{"archives", "source_type", "TEXT"},
+	{"archives", "source_id", "TEXT"},
+	{"predictions", "topic", "TEXT"},
+	{"predictions", "resolved_at", "DATETIME"},
+	{"predictions", "resolve_by", "DATETIME"},
+	{"predictions", "budget_ends_at", "DATETIME"},
+	{"emotional_states", "intensity", "INTEGER"},
+	{"decompress_sessions", "ended_at", "DATETIME"},
+	{"decompress_sessions", "pre_state_id", "TEXT"},
+	{"decompress_sessions", "post_state_id", "TEXT"},
+	{"ai_offloads", "provider", "TEXT"},
+	{"ai_offloads", "result", "TEXT"},
+	{"ai_offloads", "error", "TEXT"},
+	{"ai_offloads", "completed_at", "DATETIME"},
+	{"ai_offloads", "attempts", "INTEGER NOT NULL DEFAULT 0"},
+	{"ai_offloads", "next_attempt_at", "DATETIME"},
+	{"ai_offloads", "started_at", "DATETIME"},
+	{"ai_offloads", "quorum", "INTEGER NOT NULL DEFAULT 0"},
+	{"ai_offloads", "strategy", "TEXT"},
+	{"ai_offloads", "feedback", "TEXT"},
+	{"ai_offloads", "template_version", "INTEGER NOT NULL DEFAULT 0"},
+	{"ai_offloads", "instructions", "TEXT"},
+	{"ai_offloads", "prompt", "TEXT"},
+	{"focus_state", "goal_id", "TEXT"},
+	{"loops", "goal_id", "TEXT"},
+	{"threads", "goal_id", "TEXT"},
+	{"tasks", "goal_id", "TEXT"},
+	{"ai_offloads", "skip_review", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// migrate applies additive schema changes to databases created by earlier versions
+func (db *DB) migrate() error {
+	for _, m := range columnMigrations {
+		exists, err := db.columnExists(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.conn.Exec(fmt.Sprintf(
+			"ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition,
+		)); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w", m.table, m.column, err)
+		}
+	}
+	return nil
+}
+
+// tableExists reports whether the named table has been created
+func (db *DB) tableExists(table string) (bool, error) {
+	var count int
+	err := db.conn.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table,
+	).Scan(&count)
+	return count > 0, err
+}
+
+// columnExists reports whether a table already has the named column
+func (db *DB) columnExists(table, column string) (bool, error) {
+	rows, err := db.conn.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
 // ============================================================================
 // FOCUS OPERATIONS
 // ============================================================================
@@ -190,15 +544,16 @@ func (db *DB) GetCurrentFocus() (*models.FocusState, error) {
 	var endsAt sql.NullTime
 	err := db.conn.QueryRow(`
 		SELECT id, task_name, duration, success_criteria, is_locked,
-		       COALESCE(timebox, ''), COALESCE(fallback, ''),
-		       started_at, ends_at, created_at, updated_at
+		       COALESCE(timebox, ''), COALESCE(fallback, ''), status,
+		       started_at, ends_at, COALESCE(goal_id, ''), created_at, updated_at
 		FROM focus_state
+		WHERE status = 'active'
 		ORDER BY created_at DESC
 		LIMIT 1
 	`).Scan(
 		&focus.ID, &focus.TaskName, &focus.Duration, &focus.SuccessCriteria,
-		&focus.IsLocked, &focus.Timebox, &focus.Fallback,
-		&focus.StartedAt, &endsAt, &focus.CreatedAt, &focus.UpdatedAt,
+		&focus.IsLocked, &focus.Timebox, &focus.Fallback, &focus.Status,
+		&focus.StartedAt, &endsAt, &focus.GoalID, &focus.CreatedAt, &focus.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -214,18 +569,100 @@ func (db *DB) GetCurrentFocus() (*models.FocusState, error) {
 	return &focus, nil
 }
 
-// SetFocus creates or updates the current focus state
+// GetActiveFocuses returns every focus session still marked active, newest
+// first. Normally that is at most one, but databases written before new
+// sessions superseded old ones can hold several.
+func (db *DB) GetActiveFocuses() ([]models.FocusState, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, task_name, duration, success_criteria, is_locked,
+		       COALESCE(timebox, ''), COALESCE(fallback, ''), status,
+		       started_at, ends_at, COALESCE(goal_id, ''), created_at, updated_at
+		FROM focus_state
+		WHERE status = 'active'
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var focuses []models.FocusState
+	for rows.Next() {
+		var focus models.FocusState
+		var endsAt sql.NullTime
+		if err := rows.Scan(
+			&focus.ID, &focus.TaskName, &focus.Duration, &focus.SuccessCriteria,
+			&focus.IsLocked, &focus.Timebox, &focus.Fallback, &focus.Status,
+			&focus.StartedAt, &endsAt, &focus.GoalID, &focus.CreatedAt, &focus.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if endsAt.Valid {
+			focus.EndsAt = endsAt.Time
+		} else {
+			focus.EndsAt = focus.StartedAt
+		}
+		focuses = append(focuses, focus)
+	}
+	return focuses, rows.Err()
+}
+
+// SetFocus starts a new focus session. Any session still active is marked
+// superseded in the same transaction, so only the newest one is current.
 func (db *DB) SetFocus(focus *models.FocusState) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	_, err := db.conn.Exec(`
+	if focus.Status == "" {
+		focus.Status = "active"
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Any session still active is superseded by the new one: it ends now
+	// unless its planned end has already passed
+	if _, err := tx.Exec(`
+		UPDATE focus_state SET status = 'superseded', updated_at = ?,
+		       ends_at = CASE WHEN ends_at IS NULL OR ends_at > ? THEN ? ELSE ends_at END
+		WHERE status = 'active'
+	`, focus.StartedAt, focus.StartedAt, focus.StartedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
 		INSERT INTO focus_state (id, task_name, duration, success_criteria, is_locked,
-		                         timebox, fallback, started_at, ends_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                         timebox, fallback, status, started_at, ends_at, goal_id,
+		                         created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, focus.ID, focus.TaskName, focus.Duration, focus.SuccessCriteria, focus.IsLocked,
-		focus.Timebox, focus.Fallback, focus.StartedAt, focus.EndsAt, focus.CreatedAt, focus.UpdatedAt)
-	return err
+		focus.Timebox, focus.Fallback, focus.Status, focus.StartedAt, focus.EndsAt, focus.GoalID,
+		focus.CreatedAt, focus.UpdatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CompleteFocus marks an active focus session as completed. A session that
+// already ended - completed, superseded or cleared - is left alone.
+func (db *DB) CompleteFocus(id string, completedAt time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		UPDATE focus_state SET status = 'completed', completed_at = ?, updated_at = ?
+		WHERE id = ? AND status = 'active'
+	`, completedAt, completedAt, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 // UpdateFocusLock updates the lock status of the current focus
@@ -259,9 +696,9 @@ func (db *DB) CreateLoop(loop *models.Loop) error {
 	defer db.mu.Unlock()
 
 	_, err := db.conn.Exec(`
-		INSERT INTO loops (id, description, priority, queue, owner, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, loop.ID, loop.Description, loop.Priority, loop.Queue, loop.Owner, loop.Status,
+		INSERT INTO loops (id, description, priority, queue, owner, status, goal_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, loop.ID, loop.Description, loop.Priority, loop.Queue, loop.Owner, loop.Status, loop.GoalID,
 		loop.CreatedAt, loop.UpdatedAt)
 	return err
 }
@@ -276,12 +713,12 @@ func (db *DB) GetLoop(id string) (*models.Loop, error) {
 	err := db.conn.QueryRow(`
 		SELECT id, description, priority, queue, owner, status,
 		       COALESCE(closure_type, ''), COALESCE(next_step, ''), closed_at,
-		       created_at, updated_at
+		       COALESCE(goal_id, ''), created_at, updated_at
 		FROM loops WHERE id = ?
 	`, id).Scan(
 		&loop.ID, &loop.Description, &loop.Priority, &loop.Queue, &loop.Owner, &loop.Status,
 		&loop.ClosureType, &loop.NextStep, &closedAt,
-		&loop.CreatedAt, &loop.UpdatedAt,
+		&loop.GoalID, &loop.CreatedAt, &loop.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -333,7 +770,7 @@ func (db *DB) GetOpenLoops() ([]models.Loop, error) {
 	rows, err := db.conn.Query(`
 		SELECT id, description, priority, queue, owner, status,
 		       COALESCE(closure_type, ''), COALESCE(next_step, ''),
-		       created_at, updated_at
+		       COALESCE(goal_id, ''), created_at, updated_at
 		FROM loops WHERE status = 'open'
 		ORDER BY created_at DESC
 	`)
@@ -348,7 +785,7 @@ func (db *DB) GetOpenLoops() ([]models.Loop, error) {
 		if err := rows.Scan(
 			&loop.ID, &loop.Description, &loop.Priority, &loop.Queue, &loop.Owner, &loop.Status,
 			&loop.ClosureType, &loop.NextStep,
-			&loop.CreatedAt, &loop.UpdatedAt,
+			&loop.GoalID, &loop.CreatedAt, &loop.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -386,10 +823,10 @@ func (db *DB) CreateThread(thread *models.Thread) error {
 	defer db.mu.Unlock()
 
 	_, err := db.conn.Exec(`
-		INSERT INTO threads (id, name, mode, time_scope, goal, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO threads (id, name, mode, time_scope, goal, status, goal_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, thread.ID, thread.Name, thread.Mode, thread.TimeScope, thread.Goal, thread.Status,
-		thread.CreatedAt, thread.UpdatedAt)
+		thread.GoalID, thread.CreatedAt, thread.UpdatedAt)
 	return err
 }
 
@@ -399,7 +836,8 @@ func (db *DB) GetActiveThreads() ([]models.Thread, error) {
 	defer db.mu.RUnlock()
 
 	rows, err := db.conn.Query(`
-		SELECT id, name, mode, time_scope, COALESCE(goal, ''), status, created_at, updated_at
+		SELECT id, name, mode, time_scope, COALESCE(goal, ''), status, COALESCE(goal_id, ''),
+		       created_at, updated_at
 		FROM threads WHERE status = 'active'
 		ORDER BY created_at DESC
 	`)
@@ -413,7 +851,7 @@ func (db *DB) GetActiveThreads() ([]models.Thread, error) {
 		var thread models.Thread
 		if err := rows.Scan(
 			&thread.ID, &thread.Name, &thread.Mode, &thread.TimeScope,
-			&thread.Goal, &thread.Status, &thread.CreatedAt, &thread.UpdatedAt,
+			&thread.Goal, &thread.Status, &thread.GoalID, &thread.CreatedAt, &thread.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -428,7 +866,8 @@ func (db *DB) GetThreadsByMode(mode models.ThreadMode) ([]models.Thread, error)
 	defer db.mu.RUnlock()
 
 	rows, err := db.conn.Query(`
-		SELECT id, name, mode, time_scope, COALESCE(goal, ''), status, created_at, updated_at
+		SELECT id, name, mode, time_scope, COALESCE(goal, ''), status, COALESCE(goal_id, ''),
+		       created_at, updated_at
 		FROM threads WHERE status = 'active' AND mode = ?
 		ORDER BY created_at DESC
 	`, mode)
@@ -442,7 +881,7 @@ func (db *DB) GetThreadsByMode(mode models.ThreadMode) ([]models.Thread, error)
 		var thread models.Thread
 		if err := rows.Scan(
 			&thread.ID, &thread.Name, &thread.Mode, &thread.TimeScope,
-			&thread.Goal, &thread.Status, &thread.CreatedAt, &thread.UpdatedAt,
+			&thread.Goal, &thread.Status, &thread.GoalID, &thread.CreatedAt, &thread.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -506,10 +945,11 @@ func (db *DB) CreateTask(task *models.Task) error {
 	defer db.mu.Unlock()
 
 	_, err := db.conn.Exec(`
-		INSERT INTO tasks (id, description, category, urgency, importance, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, task.ID, task.Description, task.Category, task.Urgency, task.Importance, task.Status,
-		task.CreatedAt, task.UpdatedAt)
+		INSERT INTO tasks (id, description, category, urgency, importance, queue, rule_id,
+		                   status, goal_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, task.ID, task.Description, task.Category, task.Urgency, task.Importance,
+		task.Queue, task.RuleID, task.Status, task.GoalID, task.CreatedAt, task.UpdatedAt)
 	return err
 }
 
@@ -523,6 +963,36 @@ func (db *DB) CountPendingTasks() (int, error) {
 	return count, err
 }
 
+// GetPendingTasks returns tasks still waiting to be processed, oldest first
+func (db *DB) GetPendingTasks() ([]models.Task, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, description, category, urgency, importance, COALESCE(queue, ''),
+		       COALESCE(rule_id, ''), status, COALESCE(goal_id, ''), created_at, updated_at
+		FROM tasks WHERE status = 'pending'
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		if err := rows.Scan(
+			&task.ID, &task.Description, &task.Category, &task.Urgency, &task.Importance,
+			&task.Queue, &task.RuleID, &task.Status, &task.GoalID, &task.CreatedAt, &task.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
 // ClearTasks removes all tasks (for reset operations)
 func (db *DB) ClearTasks() error {
 	db.mu.Lock()
@@ -559,6 +1029,35 @@ func (db *DB) CountCapturedIdeas() (int, error) {
 	return count, err
 }
 
+// GetCapturedIdeas returns ideas not yet processed, oldest first
+func (db *DB) GetCapturedIdeas() ([]models.Idea, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, summary, storage, action_now, status, created_at, updated_at
+		FROM ideas WHERE status = 'captured'
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ideas []models.Idea
+	for rows.Next() {
+		var idea models.Idea
+		if err := rows.Scan(
+			&idea.ID, &idea.Summary, &idea.Storage, &idea.ActionNow, &idea.Status,
+			&idea.CreatedAt, &idea.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		ideas = append(ideas, idea)
+	}
+	return ideas, rows.Err()
+}
+
 // ClearIdeas removes all ideas (for reset operations)
 func (db *DB) ClearIdeas() error {
 	db.mu.Lock()
@@ -578,12 +1077,81 @@ func (db *DB) CreateArchive(archive *models.Archive) error {
 	defer db.mu.Unlock()
 
 	_, err := db.conn.Exec(`
-		INSERT INTO archives (id, object, summary, lesson, created_at)
-		VALUES (?, ?, ?, ?, ?)
-	`, archive.ID, archive.Object, archive.Summary, archive.Lesson, archive.CreatedAt)
+		INSERT INTO archives (id, object, summary, lesson, source_type, source_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, archive.ID, archive.Object, archive.Summary, archive.Lesson,
+		archive.SourceType, archive.SourceID, archive.CreatedAt)
 	return err
 }
 
+// GetArchive retrieves an archive entry by ID
+func (db *DB) GetArchive(id string) (*models.Archive, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var archive models.Archive
+	err := db.conn.QueryRow(`
+		SELECT id, object, summary, COALESCE(lesson, ''),
+		       COALESCE(source_type, ''), COALESCE(source_id, ''), created_at
+		FROM archives WHERE id = ?
+	`, id).Scan(
+		&archive.ID, &archive.Object, &archive.Summary, &archive.Lesson,
+		&archive.SourceType, &archive.SourceID, &archive.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// UpdateArchiveLesson records a lesson on an existing archive entry
+func (db *DB) UpdateArchiveLesson(id, lesson string) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec("UPDATE archives SET lesson = ? WHERE id = ?", lesson, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetArchivesAwaitingLesson returns automatically created archives that have
+// no lesson recorded yet, newest first
+func (db *DB) GetArchivesAwaitingLesson(limit int) ([]models.Archive, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, object, summary, COALESCE(lesson, ''),
+		       COALESCE(source_type, ''), COALESCE(source_id, ''), created_at
+		FROM archives
+		WHERE COALESCE(source_type, '') != '' AND COALESCE(lesson, '') = ''
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var archives []models.Archive
+	for rows.Next() {
+		var archive models.Archive
+		if err := rows.Scan(
+			&archive.ID, &archive.Object, &archive.Summary, &archive.Lesson,
+			&archive.SourceType, &archive.SourceID, &archive.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		archives = append(archives, archive)
+	}
+	return archives, rows.Err()
+}
+
 // ClearArchives removes all archives (for hard reset only)
 func (db *DB) ClearArchives() error {
 	db.mu.Lock()
@@ -597,19 +1165,6 @@ func (db *DB) ClearArchives() error {
 // PREDICTION OPERATIONS
 // ============================================================================
 
-// CreatePrediction creates a new prediction
-func (db *DB) CreatePrediction(pred *models.Prediction) error {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
-	_, err := db.conn.Exec(`
-		INSERT INTO predictions (id, scenario, time_horizon, depth, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, pred.ID, pred.Scenario, pred.TimeHorizon, pred.Depth, pred.Status,
-		pred.CreatedAt, pred.UpdatedAt)
-	return err
-}
-
 // StopPredictionByTopic stops predictions matching the topic
 func (db *DB) StopPredictionByTopic(topic string) (int64, error) {
 	db.mu.Lock()
@@ -618,8 +1173,8 @@ func (db *DB) StopPredictionByTopic(topic string) (int64, error) {
 	now := time.Now().UTC()
 	result, err := db.conn.Exec(`
 		UPDATE predictions SET status = 'stopped', updated_at = ?
-		WHERE status = 'running' AND scenario LIKE ?
-	`, now, "%"+topic+"%")
+		WHERE status = 'running' AND (scenario LIKE ? OR topic LIKE ?)
+	`, now, "%"+topic+"%", "%"+topic+"%")
 	if err != nil {
 		return 0, err
 	}
@@ -641,6 +1196,12 @@ func (db *DB) ClearPredictions() error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
+	if _, err := db.conn.Exec("DELETE FROM prediction_forecasts"); err != nil {
+		return err
+	}
+	if _, err := db.conn.Exec("DELETE FROM scenario_branches"); err != nil {
+		return err
+	}
 	_, err := db.conn.Exec("DELETE FROM predictions")
 	return err
 }
@@ -655,9 +1216,9 @@ func (db *DB) CreateEmotionalState(state *models.EmotionalState) error {
 	defer db.mu.Unlock()
 
 	_, err := db.conn.Exec(`
-		INSERT INTO emotional_states (id, label, source_guess, created_at)
-		VALUES (?, ?, ?, ?)
-	`, state.ID, state.Label, state.SourceGuess, state.CreatedAt)
+		INSERT INTO emotional_states (id, label, source_guess, intensity, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, state.ID, state.Label, state.SourceGuess, state.Intensity, state.CreatedAt)
 	return err
 }
 
@@ -667,7 +1228,7 @@ func (db *DB) GetRecentEmotionalStates(limit int) ([]models.EmotionalState, erro
 	defer db.mu.RUnlock()
 
 	rows, err := db.conn.Query(`
-		SELECT id, label, source_guess, created_at
+		SELECT id, label, source_guess, intensity, created_at
 		FROM emotional_states
 		ORDER BY created_at DESC
 		LIMIT ?
@@ -677,28 +1238,38 @@ func (db *DB) GetRecentEmotionalStates(limit int) ([]models.EmotionalState, erro
 	}
 	defer rows.Close()
 
-	var states []models.EmotionalState
-	for rows.Next() {
-		var state models.EmotionalState
-		if err := rows.Scan(&state.ID, &state.Label, &state.SourceGuess, &state.CreatedAt); err != nil {
-			return nil, err
-		}
-		states = append(states, state)
-	}
-	return states, rows.Err()
+	return scanEmotionalStates(rows)
 }
 
-// CreateDecompressSession creates a new decompression session
+// CreateDecompressSession creates a new decompression session, storing its
+// pre-session check-in in the same transaction
 func (db *DB) CreateDecompressSession(session *models.DecompressSession) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	_, err := db.conn.Exec(`
-		INSERT INTO decompress_sessions (id, method, duration, status, started_at, ends_at, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if state := session.PreCheckIn; state != nil {
+		if _, err := tx.Exec(`
+			INSERT INTO emotional_states (id, label, source_guess, intensity, created_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, state.ID, state.Label, state.SourceGuess, state.Intensity, state.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO decompress_sessions (id, method, duration, status, started_at, ends_at, pre_state_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`, session.ID, session.Method, session.Duration, session.Status,
-		session.StartedAt, session.EndsAt, session.CreatedAt)
-	return err
+		session.StartedAt, session.EndsAt, checkInID(session.PreCheckIn), session.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // ClearEmotionalStates removes all emotional states (for reset operations)
@@ -706,6 +1277,9 @@ func (db *DB) ClearEmotionalStates() error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
+	if _, err := db.conn.Exec("DELETE FROM emotion_triggers"); err != nil {
+		return err
+	}
 	_, err := db.conn.Exec("DELETE FROM emotional_states")
 	return err
 }
@@ -728,10 +1302,26 @@ func (db *DB) CreateAIOffload(offload *models.AIOffload) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	_, err := db.conn.Exec(`
-		INSERT INTO ai_offloads (id, task_type, scope, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, offload.ID, offload.TaskType, offload.Scope, offload.Status,
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertAIOffload(tx, offload); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertAIOffload inserts an offload within a transaction
+func insertAIOffload(tx *sql.Tx, offload *models.AIOffload) error {
+	_, err := tx.Exec(`
+		INSERT INTO ai_offloads (id, task_type, scope, status, quorum, strategy, template_version,
+		                         instructions, prompt, skip_review, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, offload.ID, offload.TaskType, offload.Scope, offload.Status, offload.Quorum, offload.Strategy,
+		offload.TemplateVersion, offload.Instructions, offload.Prompt, offload.SkipReview,
 		offload.CreatedAt, offload.UpdatedAt)
 	return err
 }
@@ -741,6 +1331,11 @@ func (db *DB) ClearAIOffloads() error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
+	for _, table := range []string{"ai_offload_votes", "ai_offload_reviews"} {
+		if _, err := db.conn.Exec("DELETE FROM " + table); err != nil {
+			return err
+		}
+	}
 	_, err := db.conn.Exec("DELETE FROM ai_offloads")
 	return err
 }
@@ -749,55 +1344,94 @@ func (db *DB) ClearAIOffloads() error {
 // RESET OPERATIONS
 // ============================================================================
 
-// SoftReset clears active state but preserves archives and historical data
-func (db *DB) SoftReset() error {
-	// Clear active states
-	if err := db.ClearFocus(); err != nil {
-		return err
-	}
-	// Close all open loops instead of deleting
+// SoftReset snapshots every table as snapshotID and applies the changes a
+// soft reset was planned with, in one transaction: focus sessions are
+// cleared, loops abandoned, threads terminated and predictions stopped. Rows
+// are changed by ID, so the reset does exactly what its plan listed; anything
+// that finished in the meantime is left alone. Archives and other history
+// are preserved.
+func (db *DB) SoftReset(changes *models.SoftResetChanges, snapshotID string, now time.Time) (*models.StateSnapshot, error) {
 	db.mu.Lock()
-	now := time.Now().UTC()
-	_, err := db.conn.Exec(`
-		UPDATE loops SET status = 'closed', closure_type = 'abandoned',
-		                 closed_at = ?, updated_at = ?
-		WHERE status = 'open'
-	`, now, now)
-	db.mu.Unlock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// Terminate all active threads
-	db.mu.Lock()
-	_, err = db.conn.Exec(`
-		UPDATE threads SET status = 'terminated', updated_at = ?
-		WHERE status = 'active'
-	`, now)
-	db.mu.Unlock()
+	defer tx.Rollback()
+
+	snapshot, err := createSnapshot(tx, snapshotID, "soft_reset", now)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// Stop all running predictions
-	db.mu.Lock()
-	_, err = db.conn.Exec(`
-		UPDATE predictions SET status = 'stopped', updated_at = ?
-		WHERE status = 'running'
-	`, now)
-	db.mu.Unlock()
-	return err
+
+	for _, focus := range changes.Focus {
+		if _, err := tx.Exec(`
+			UPDATE focus_state SET status = 'cleared', ends_at = ?, updated_at = ?
+			WHERE id = ? AND status = 'active'
+		`, now, now, focus.ID); err != nil {
+			return nil, err
+		}
+	}
+	for _, loop := range changes.Loops {
+		if _, err := tx.Exec(`
+			UPDATE loops SET status = 'closed', closure_type = 'abandoned',
+			                 closed_at = ?, updated_at = ?
+			WHERE id = ? AND status = 'open'
+		`, now, now, loop.ID); err != nil {
+			return nil, err
+		}
+	}
+	for _, thread := range changes.Threads {
+		if _, err := tx.Exec(`
+			UPDATE threads SET status = 'terminated', updated_at = ?
+			WHERE id = ? AND status = 'active'
+		`, now, thread.ID); err != nil {
+			return nil, err
+		}
+	}
+	for _, pred := range changes.Predictions {
+		if _, err := tx.Exec(`
+			UPDATE predictions SET status = 'stopped', updated_at = ?
+			WHERE id = ? AND status = 'running'
+		`, now, pred.ID); err != nil {
+			return nil, err
+		}
+	}
+	return snapshot, tx.Commit()
 }
 
-// HardReset clears all data including archives
-func (db *DB) HardReset() error {
+// HardReset snapshots every table as snapshotID and clears all data
+// including archives, in one transaction. Configuration - the emotion
+// taxonomy, triggers, prompt templates, classification rules and Telos
+// context - and state snapshots are kept.
+func (db *DB) HardReset(snapshotID string, now time.Time) (*models.StateSnapshot, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	snapshot, err := createSnapshot(tx, snapshotID, "hard_reset", now)
+	if err != nil {
+		return nil, err
+	}
+
 	tables := []string{
 		"focus_state", "loops", "threads", "tasks", "ideas",
 		"archives", "predictions", "emotional_states",
-		"decompress_sessions", "ai_offloads",
+		"decompress_sessions", "ai_offloads", "lesson_resurfacings",
+		"prediction_forecasts", "scenario_branches", "prediction_stops",
+		"emotion_triggers", "ai_offload_votes", "ai_offload_reviews",
+		"assist_sessions", "assist_steps",
 	}
 	for _, table := range tables {
-		if _, err := db.conn.Exec("DELETE FROM " + table); err != nil {
-			return err
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	return snapshot, tx.Commit()
 }
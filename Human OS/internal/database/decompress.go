@@ -0,0 +1,223 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// DECOMPRESSION LIFECYCLE OPERATIONS
+// ============================================================================
+
+// decompressColumns is the column list read by scanDecompressSession
+const decompressColumns = `
+	id, method, duration, status, started_at, ends_at, ended_at,
+	COALESCE(pre_state_id, ''), COALESCE(post_state_id, ''), created_at`
+
+// scanDecompressSession reads a row selected with decompressColumns along
+// with the IDs of its check-in states
+func scanDecompressSession(scan func(dest ...interface{}) error) (*models.DecompressSession, string, string, error) {
+	var session models.DecompressSession
+	var endedAt sql.NullTime
+	var preID, postID string
+	if err := scan(
+		&session.ID, &session.Method, &session.Duration, &session.Status,
+		&session.StartedAt, &session.EndsAt, &endedAt, &preID, &postID, &session.CreatedAt,
+	); err != nil {
+		return nil, "", "", err
+	}
+	if endedAt.Valid {
+		session.EndedAt = &endedAt.Time
+	}
+	return &session, preID, postID, nil
+}
+
+// checkInID returns a check-in's state ID, or nil to store NULL
+func checkInID(state *models.EmotionalState) interface{} {
+	if state == nil {
+		return nil
+	}
+	return state.ID
+}
+
+// GetDecompressSession retrieves a decompression session with its check-ins
+func (db *DB) GetDecompressSession(id string) (*models.DecompressSession, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	session, preID, postID, err := scanDecompressSession(db.conn.QueryRow(
+		"SELECT"+decompressColumns+" FROM decompress_sessions WHERE id = ?", id,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.loadCheckIns(session, preID, postID); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetDecompressSessions returns every decompression session with its
+// check-ins, newest first
+func (db *DB) GetDecompressSessions() ([]models.DecompressSession, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(
+		"SELECT" + decompressColumns + " FROM decompress_sessions ORDER BY started_at DESC",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.DecompressSession
+	var checkIns [][2]string
+	for rows.Next() {
+		session, preID, postID, err := scanDecompressSession(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+		checkIns = append(checkIns, [2]string{preID, postID})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range sessions {
+		if err := db.loadCheckIns(&sessions[i], checkIns[i][0], checkIns[i][1]); err != nil {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
+
+// GetActiveDecompressSession returns the most recently started active
+// session, or nil if none is running
+func (db *DB) GetActiveDecompressSession() (*models.DecompressSession, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	session, preID, postID, err := scanDecompressSession(db.conn.QueryRow(
+		"SELECT" + decompressColumns + " FROM decompress_sessions WHERE status = 'active' ORDER BY started_at DESC LIMIT 1",
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.loadCheckIns(session, preID, postID); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// loadCheckIns attaches the pre- and post-session check-in states. Caller
+// must hold the lock.
+func (db *DB) loadCheckIns(session *models.DecompressSession, preID, postID string) error {
+	var err error
+	if preID != "" {
+		if session.PreCheckIn, err = db.getEmotionalState(preID); err != nil {
+			return err
+		}
+	}
+	if postID != "" {
+		if session.PostCheckIn, err = db.getEmotionalState(postID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getEmotionalState reads one emotional state, or nil if it no longer
+// exists. Caller must hold the lock.
+func (db *DB) getEmotionalState(id string) (*models.EmotionalState, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, label, source_guess, intensity, created_at
+		FROM emotional_states WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states, err := scanEmotionalStates(rows)
+	if err != nil || len(states) == 0 {
+		return nil, err
+	}
+	return &states[0], nil
+}
+
+// EndDecompressSession moves an active session to a final status
+func (db *DB) EndDecompressSession(id, status string, endedAt time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		UPDATE decompress_sessions SET status = ?, ended_at = ?
+		WHERE id = ? AND status = 'active'
+	`, status, endedAt, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SetDecompressPostCheckIn stores a post-session check-in and links it to a
+// session that does not have one yet, in one transaction. Nothing is stored
+// if the session already has one.
+func (db *DB) SetDecompressPostCheckIn(id string, state *models.EmotionalState) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO emotional_states (id, label, source_guess, intensity, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, state.ID, state.Label, state.SourceGuess, state.Intensity, state.CreatedAt); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`
+		UPDATE decompress_sessions SET post_state_id = ?
+		WHERE id = ? AND post_state_id IS NULL
+	`, state.ID, id)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// CompleteExpiredDecompressSessions completes active sessions whose planned
+// end has passed, recording the planned end as when they ended
+func (db *DB) CompleteExpiredDecompressSessions(now time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		UPDATE decompress_sessions SET status = 'completed', ended_at = ends_at
+		WHERE status = 'active' AND ends_at <= ?
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,196 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// EMOTION TAXONOMY OPERATIONS
+// ============================================================================
+
+// defaultEmotionTaxonomy is installed the first time the database is opened.
+// The load weights keep the original high/medium groupings: 1.0 for the
+// emotions that used to count as high load, 0.5 for medium.
+var defaultEmotionTaxonomy = []models.EmotionDefinition{
+	{Label: "angry", Valence: -0.8, Arousal: 0.9, LoadWeight: 1.0, Synonyms: []string{"mad", "furious", "irritated", "annoyed"}},
+	{Label: "anxious", Valence: -0.7, Arousal: 0.8, LoadWeight: 1.0, Synonyms: []string{"nervous", "on edge", "panicky"}},
+	{Label: "overwhelmed", Valence: -0.8, Arousal: 0.8, LoadWeight: 1.0, Synonyms: []string{"swamped", "drowning"}},
+	{Label: "stressed", Valence: -0.6, Arousal: 0.8, LoadWeight: 1.0, Synonyms: []string{"pressured", "tense"}},
+	{Label: "frustrated", Valence: -0.6, Arousal: 0.7, LoadWeight: 1.0, Synonyms: []string{"stuck", "blocked"}},
+	{Label: "tired", Valence: -0.3, Arousal: 0.1, LoadWeight: 0.5, Synonyms: []string{"exhausted", "drained", "fatigued", "sleepy"}},
+	{Label: "resentful", Valence: -0.6, Arousal: 0.5, LoadWeight: 0.5, Synonyms: []string{"bitter"}},
+	{Label: "worried", Valence: -0.5, Arousal: 0.6, LoadWeight: 0.5, Synonyms: []string{"concerned", "uneasy"}},
+	{Label: "uncertain", Valence: -0.3, Arousal: 0.4, LoadWeight: 0.5, Synonyms: []string{"unsure", "confused"}},
+	{Label: "sad", Valence: -0.7, Arousal: 0.2, LoadWeight: 0.5, Synonyms: []string{"down", "blue"}},
+	{Label: "bored", Valence: -0.2, Arousal: 0.1, LoadWeight: 0.2, Synonyms: []string{"restless"}},
+	{Label: "calm", Valence: 0.6, Arousal: 0.1, LoadWeight: 0, Synonyms: []string{"relaxed", "peaceful"}},
+	{Label: "content", Valence: 0.7, Arousal: 0.3, LoadWeight: 0, Synonyms: []string{"satisfied"}},
+	{Label: "happy", Valence: 0.8, Arousal: 0.6, LoadWeight: 0, Synonyms: []string{"glad", "joyful", "excited"}},
+	{Label: "focused", Valence: 0.5, Arousal: 0.5, LoadWeight: 0, Synonyms: []string{"engaged", "in flow"}},
+}
+
+// seedEmotionTaxonomy installs the default taxonomy into an empty table.
+// Once any entry exists the taxonomy belongs to the user and is left alone.
+func (db *DB) seedEmotionTaxonomy() error {
+	var count int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM emotion_taxonomy").Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	for _, def := range defaultEmotionTaxonomy {
+		if _, err := db.conn.Exec(`
+			INSERT INTO emotion_taxonomy (label, valence, arousal, load_weight, synonyms, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, def.Label, def.Valence, def.Arousal, def.LoadWeight, strings.Join(def.Synonyms, ","), now, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEmotionTaxonomy returns every taxonomy entry ordered by label
+func (db *DB) GetEmotionTaxonomy() ([]models.EmotionDefinition, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT label, valence, arousal, load_weight, COALESCE(synonyms, ''), created_at, updated_at
+		FROM emotion_taxonomy
+		ORDER BY label ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var taxonomy []models.EmotionDefinition
+	for rows.Next() {
+		var def models.EmotionDefinition
+		var synonyms string
+		if err := rows.Scan(
+			&def.Label, &def.Valence, &def.Arousal, &def.LoadWeight, &synonyms, &def.CreatedAt, &def.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		def.Synonyms = splitSynonyms(synonyms)
+		taxonomy = append(taxonomy, def)
+	}
+	return taxonomy, rows.Err()
+}
+
+// UpsertEmotionDefinition creates a taxonomy entry or replaces an existing one
+func (db *DB) UpsertEmotionDefinition(def *models.EmotionDefinition) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	_, err := db.conn.Exec(`
+		INSERT INTO emotion_taxonomy (label, valence, arousal, load_weight, synonyms, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(label) DO UPDATE SET
+			valence = excluded.valence,
+			arousal = excluded.arousal,
+			load_weight = excluded.load_weight,
+			synonyms = excluded.synonyms,
+			updated_at = excluded.updated_at
+	`, def.Label, def.Valence, def.Arousal, def.LoadWeight, strings.Join(def.Synonyms, ","),
+		def.CreatedAt, def.UpdatedAt)
+	return err
+}
+
+// DeleteEmotionDefinition removes a taxonomy entry
+func (db *DB) DeleteEmotionDefinition(label string) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec("DELETE FROM emotion_taxonomy WHERE label = ?", label)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetEmotionalStatesSince returns emotional states tagged at or after since, newest first
+func (db *DB) GetEmotionalStatesSince(since time.Time) ([]models.EmotionalState, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, label, source_guess, intensity, created_at
+		FROM emotional_states
+		WHERE created_at >= ?
+		ORDER BY created_at DESC
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEmotionalStates(rows)
+}
+
+// scanEmotionalStates reads rows of id, label, source_guess, intensity, created_at
+func scanEmotionalStates(rows *sql.Rows) ([]models.EmotionalState, error) {
+	var states []models.EmotionalState
+	for rows.Next() {
+		var state models.EmotionalState
+		var intensity sql.NullInt64
+		if err := rows.Scan(&state.ID, &state.Label, &state.SourceGuess, &intensity, &state.CreatedAt); err != nil {
+			return nil, err
+		}
+		if intensity.Valid {
+			i := int(intensity.Int64)
+			state.Intensity = &i
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+// splitSynonyms parses the comma-separated synonyms column
+func splitSynonyms(s string) []string {
+	synonyms := []string{}
+	for _, synonym := range strings.Split(s, ",") {
+		if synonym = strings.TrimSpace(synonym); synonym != "" {
+			synonyms = append(synonyms, synonym)
+		}
+	}
+	return synonyms
+}
+
+// GetEmotionalStates returns emotional states tagged within [since, until),
+// newest first. An empty label matches every label; limit <= 0 means no limit.
+func (db *DB) GetEmotionalStates(since, until time.Time, label string, limit int) ([]models.EmotionalState, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := `
+		SELECT id, label, source_guess, intensity, created_at
+		FROM emotional_states
+		WHERE created_at >= ? AND created_at < ?`
+	args := []interface{}{since, until}
+	if label != "" {
+		query += " AND label = ?"
+		args = append(args, label)
+	}
+	query += " ORDER BY created_at DESC"
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEmotionalStates(rows)
+}
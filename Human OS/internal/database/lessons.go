@@ -0,0 +1,113 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// LESSON OPERATIONS
+// ============================================================================
+
+// SearchLessons finds archived entries with a recorded lesson related to the
+// given text. Any term may match, so loosely related past work still surfaces;
+// ranking puts the closest matches first.
+func (db *DB) SearchLessons(query string, limit int) ([]models.ArchiveSearchResult, error) {
+	// Over-fetch because archives without a lesson are filtered out below
+	results, err := db.searchArchives(query, limit*4, true)
+	if err != nil {
+		return nil, err
+	}
+
+	lessons := make([]models.ArchiveSearchResult, 0, limit)
+	for _, r := range results {
+		if strings.TrimSpace(r.Archive.Lesson) == "" {
+			continue
+		}
+		lessons = append(lessons, r)
+		if len(lessons) == limit {
+			break
+		}
+	}
+	return lessons, nil
+}
+
+// CreateLessonResurfacing records that a lesson was shown for a piece of work
+func (db *DB) CreateLessonResurfacing(id, archiveID string, contextType models.LessonContext, contextID string, score float64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	_, err := db.conn.Exec(`
+		INSERT INTO lesson_resurfacings (id, archive_id, context_type, context_id, score, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, id, archiveID, contextType, contextID, score, time.Now().UTC())
+	return err
+}
+
+// RateLessonResurfacing records whether a resurfaced lesson was useful and
+// returns the archive ID it refers to. An empty ID means it was not found.
+func (db *DB) RateLessonResurfacing(id string, useful bool) (string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var archiveID string
+	err := db.conn.QueryRow(
+		"SELECT archive_id FROM lesson_resurfacings WHERE id = ?", id,
+	).Scan(&archiveID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.conn.Exec(`
+		UPDATE lesson_resurfacings SET useful = ?, rated_at = ? WHERE id = ?
+	`, useful, time.Now().UTC(), id)
+	if err != nil {
+		return "", err
+	}
+	return archiveID, nil
+}
+
+// GetLessonFeedback returns aggregated usefulness ratings for the given archives
+func (db *DB) GetLessonFeedback(archiveIDs []string) (map[string]models.LessonFeedback, error) {
+	feedback := make(map[string]models.LessonFeedback, len(archiveIDs))
+	if len(archiveIDs) == 0 {
+		return feedback, nil
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(archiveIDs)), ", ")
+	args := make([]interface{}, len(archiveIDs))
+	for i, id := range archiveIDs {
+		args[i] = id
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT archive_id,
+		       COALESCE(SUM(CASE WHEN useful = 1 THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN useful = 0 THEN 1 ELSE 0 END), 0)
+		FROM lesson_resurfacings
+		WHERE archive_id IN (`+placeholders+`)
+		GROUP BY archive_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f models.LessonFeedback
+		if err := rows.Scan(&f.ArchiveID, &f.UsefulCount, &f.NotUsefulCount); err != nil {
+			return nil, err
+		}
+		feedback[f.ArchiveID] = f
+	}
+	return feedback, rows.Err()
+}
@@ -0,0 +1,411 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// AI OFFLOAD QUEUE OPERATIONS
+// ============================================================================
+// The ai_offloads table doubles as the offload job queue: pending rows are
+// waiting to run (from next_attempt_at, when set), processing rows are
+// claimed by a worker, awaiting_review rows hold a result a person has not
+// yet accepted, and the remaining statuses are final.
+
+// offloadColumns is the column list read by scanAIOffload
+const offloadColumns = `
+	id, task_type, scope, status, COALESCE(provider, ''), COALESCE(result, ''),
+	COALESCE(error, ''), attempts, next_attempt_at, started_at, completed_at,
+	quorum, COALESCE(strategy, ''), template_version, COALESCE(instructions, ''),
+	COALESCE(prompt, ''), COALESCE(feedback, ''), skip_review, created_at, updated_at`
+
+// scanAIOffload reads a row selected with offloadColumns
+func scanAIOffload(scan func(dest ...interface{}) error) (*models.AIOffload, error) {
+	var offload models.AIOffload
+	var nextAttemptAt, startedAt, completedAt sql.NullTime
+	if err := scan(
+		&offload.ID, &offload.TaskType, &offload.Scope, &offload.Status,
+		&offload.Provider, &offload.Result, &offload.Error, &offload.Attempts,
+		&nextAttemptAt, &startedAt, &completedAt, &offload.Quorum, &offload.Strategy,
+		&offload.TemplateVersion, &offload.Instructions, &offload.Prompt, &offload.Feedback,
+		&offload.SkipReview, &offload.CreatedAt, &offload.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if nextAttemptAt.Valid {
+		offload.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if startedAt.Valid {
+		offload.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		offload.CompletedAt = &completedAt.Time
+	}
+	return &offload, nil
+}
+
+// GetAIOffload retrieves an AI offload by ID
+func (db *DB) GetAIOffload(id string) (*models.AIOffload, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	offload, err := scanAIOffload(db.conn.QueryRow(
+		"SELECT"+offloadColumns+" FROM ai_offloads WHERE id = ?", id,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return offload, err
+}
+
+// GetAIOffloads returns offloads newest first, optionally filtered by status
+// and task type
+func (db *DB) GetAIOffloads(status, taskType string, limit int) ([]models.AIOffload, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := "SELECT" + offloadColumns + " FROM ai_offloads WHERE 1 = 1"
+	var args []interface{}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	if taskType != "" {
+		query += " AND task_type = ?"
+		args = append(args, taskType)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	return db.queryAIOffloads(query, args...)
+}
+
+// GetDueAIOffloads returns pending offloads that are ready to run, oldest
+// first, leaving out the concurrency groups in skipGroups. Assist task types
+// and unstick share the "assist" group; every other task type is its own
+// group.
+func (db *DB) GetDueAIOffloads(now time.Time, skipGroups []string, limit int) ([]models.AIOffload, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := `
+		SELECT` + offloadColumns + `
+		FROM ai_offloads
+		WHERE status = 'pending' AND (next_attempt_at IS NULL OR next_attempt_at <= ?)`
+	args := []interface{}{now}
+	if len(skipGroups) > 0 {
+		query += `
+		  AND CASE WHEN task_type LIKE 'assist:%' OR task_type = 'unstick' THEN 'assist' ELSE task_type END
+		      NOT IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(skipGroups)), ", ") + `)`
+		for _, group := range skipGroups {
+			args = append(args, group)
+		}
+	}
+	query += `
+		ORDER BY created_at ASC
+		LIMIT ?`
+	args = append(args, limit)
+
+	return db.queryAIOffloads(query, args...)
+}
+
+// queryAIOffloads runs a query selecting offloadColumns. Caller must hold
+// the lock.
+func (db *DB) queryAIOffloads(query string, args ...interface{}) ([]models.AIOffload, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var offloads []models.AIOffload
+	for rows.Next() {
+		offload, err := scanAIOffload(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		offloads = append(offloads, *offload)
+	}
+	return offloads, rows.Err()
+}
+
+// ClaimAIOffload marks a pending offload as processing on a provider and
+// counts the attempt. It affects no rows if another worker claimed it first.
+func (db *DB) ClaimAIOffload(id, provider string, now time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		UPDATE ai_offloads
+		SET status = 'processing', provider = ?, attempts = attempts + 1,
+		    next_attempt_at = NULL, started_at = ?, updated_at = ?
+		WHERE id = ? AND status = 'pending'
+	`, provider, now, now, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// FinishAIOffload records the outcome of a processing offload
+func (db *DB) FinishAIOffload(id, status, output, errMsg string, now time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		UPDATE ai_offloads SET status = ?, result = ?, error = ?, completed_at = ?, updated_at = ?
+		WHERE id = ? AND status = 'processing'
+	`, status, output, errMsg, now, now, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RetryAIOffload returns a processing offload to the queue after a failed
+// attempt, to run again from nextAttemptAt
+func (db *DB) RetryAIOffload(id, errMsg string, nextAttemptAt, now time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		UPDATE ai_offloads SET status = 'pending', error = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ? AND status = 'processing'
+	`, errMsg, nextAttemptAt, now, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ReleaseAIOffloads returns processing offloads to the queue without
+// counting their interrupted attempt. With no IDs, every processing offload
+// is released, which recovers offloads left behind by a crash.
+func (db *DB) ReleaseAIOffloads(now time.Time, ids ...string) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	query := `
+		UPDATE ai_offloads SET status = 'pending', attempts = MAX(attempts - 1, 0), updated_at = ?
+		WHERE status = 'processing'`
+	args := []interface{}{now}
+	if len(ids) > 0 {
+		query += " AND id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	result, err := db.conn.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CancelAIOffload cancels an offload that is still waiting in the queue
+func (db *DB) CancelAIOffload(id string, now time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		UPDATE ai_offloads SET status = 'cancelled', next_attempt_at = NULL, completed_at = ?, updated_at = ?
+		WHERE id = ? AND status = 'pending'
+	`, now, now, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RecordAIOffloadVotes stores the votes cast in one attempt of a quorum
+// offload
+func (db *DB) RecordAIOffloadVotes(votes []models.AIOffloadVote) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, vote := range votes {
+		if _, err := tx.Exec(`
+			INSERT INTO ai_offload_votes (id, offload_id, attempt, member, status, output, error, agrees, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, vote.ID, vote.OffloadID, vote.Attempt, vote.Member, vote.Status,
+			vote.Output, vote.Error, vote.Agrees, vote.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetAIOffloadVotes returns the votes cast for an offload, by attempt
+func (db *DB) GetAIOffloadVotes(offloadID string) ([]models.AIOffloadVote, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, offload_id, attempt, member, status, COALESCE(output, ''),
+		       COALESCE(error, ''), agrees, created_at
+		FROM ai_offload_votes
+		WHERE offload_id = ?
+		ORDER BY attempt ASC, created_at ASC, member ASC
+	`, offloadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var votes []models.AIOffloadVote
+	for rows.Next() {
+		var vote models.AIOffloadVote
+		if err := rows.Scan(
+			&vote.ID, &vote.OffloadID, &vote.Attempt, &vote.Member, &vote.Status,
+			&vote.Output, &vote.Error, &vote.Agrees, &vote.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		votes = append(votes, vote)
+	}
+	return votes, rows.Err()
+}
+
+// reviewColumns is the column list read by scanAIOffloadReview
+const reviewColumns = `
+	id, offload_id, action, reviewer, COALESCE(feedback, ''), ai_result,
+	COALESCE(accepted_result, ''), created_at`
+
+// scanAIOffloadReview reads a row selected with reviewColumns
+func scanAIOffloadReview(scan func(dest ...interface{}) error) (*models.AIOffloadReview, error) {
+	var review models.AIOffloadReview
+	if err := scan(
+		&review.ID, &review.OffloadID, &review.Action, &review.Reviewer,
+		&review.Feedback, &review.AIResult, &review.AcceptedResult, &review.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ReviewAIOffload records a review of an offload awaiting review. Approved
+// and edited offloads are completed with the accepted result; rejected ones
+// return to the queue with the reviewer's feedback and a fresh set of
+// attempts. The reviewed result is read in the same transaction and recorded
+// as the review's AI result, and as the accepted result of an approval, so a
+// rerun that finished after the reviewer looked is never overwritten. It
+// affects no rows if the offload is not awaiting review.
+func (db *DB) ReviewAIOffload(review *models.AIOffloadReview) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		SELECT COALESCE(result, '') FROM ai_offloads
+		WHERE id = ? AND status = 'awaiting_review'
+	`, review.OffloadID).Scan(&review.AIResult)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if review.Action == "approved" {
+		review.AcceptedResult = review.AIResult
+	}
+
+	var result sql.Result
+	if review.Action == "rejected" {
+		result, err = tx.Exec(`
+			UPDATE ai_offloads
+			SET status = 'pending', feedback = ?, attempts = 0, error = NULL,
+			    next_attempt_at = NULL, completed_at = NULL, updated_at = ?
+			WHERE id = ? AND status = 'awaiting_review'
+		`, review.Feedback, review.CreatedAt, review.OffloadID)
+	} else {
+		result, err = tx.Exec(`
+			UPDATE ai_offloads SET status = 'completed', result = ?, updated_at = ?
+			WHERE id = ? AND status = 'awaiting_review'
+		`, review.AcceptedResult, review.CreatedAt, review.OffloadID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO ai_offload_reviews (id, offload_id, action, reviewer, feedback, ai_result, accepted_result, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, review.ID, review.OffloadID, review.Action, review.Reviewer, review.Feedback,
+		review.AIResult, review.AcceptedResult, review.CreatedAt); err != nil {
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// GetAIOffloadReviews returns the reviews of an offload, oldest first
+func (db *DB) GetAIOffloadReviews(offloadID string) ([]models.AIOffloadReview, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.queryAIOffloadReviews(
+		"SELECT"+reviewColumns+" FROM ai_offload_reviews WHERE offload_id = ? ORDER BY created_at ASC",
+		offloadID,
+	)
+}
+
+// GetRecentAIOffloadReviews returns reviews newest first, optionally
+// filtered by reviewer and action
+func (db *DB) GetRecentAIOffloadReviews(reviewer, action string, limit int) ([]models.AIOffloadReview, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := "SELECT" + reviewColumns + " FROM ai_offload_reviews WHERE 1 = 1"
+	var args []interface{}
+	if reviewer != "" {
+		query += " AND reviewer = ?"
+		args = append(args, reviewer)
+	}
+	if action != "" {
+		query += " AND action = ?"
+		args = append(args, action)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	return db.queryAIOffloadReviews(query, args...)
+}
+
+// queryAIOffloadReviews runs a query selecting reviewColumns. Caller must
+// hold the lock.
+func (db *DB) queryAIOffloadReviews(query string, args ...interface{}) ([]models.AIOffloadReview, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []models.AIOffloadReview
+	for rows.Next() {
+		review, err := scanAIOffloadReview(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, *review)
+	}
+	return reviews, rows.Err()
+}
@@ -0,0 +1,391 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// PREDICTION RESOLUTION OPERATIONS
+// ============================================================================
+
+// predictionColumns is the column list read by scanPrediction
+const predictionColumns = `
+	id, scenario, COALESCE(topic, ''), time_horizon, resolve_by, budget_ends_at, depth, status,
+	COALESCE(results, ''), resolved_at, created_at, updated_at`
+
+// scanPrediction reads a row selected with predictionColumns
+func scanPrediction(scan func(dest ...interface{}) error) (*models.Prediction, error) {
+	var pred models.Prediction
+	var resolveBy, budgetEndsAt, resolvedAt sql.NullTime
+	if err := scan(
+		&pred.ID, &pred.Scenario, &pred.Topic, &pred.TimeHorizon, &resolveBy, &budgetEndsAt,
+		&pred.Depth, &pred.Status, &pred.Results, &resolvedAt, &pred.CreatedAt, &pred.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if resolveBy.Valid {
+		pred.ResolveBy = &resolveBy.Time
+	}
+	if budgetEndsAt.Valid {
+		pred.BudgetEndsAt = &budgetEndsAt.Time
+	}
+	if resolvedAt.Valid {
+		pred.ResolvedAt = &resolvedAt.Time
+	}
+	return &pred, nil
+}
+
+// GetPrediction retrieves a prediction by ID along with its forecasts
+func (db *DB) GetPrediction(id string) (*models.Prediction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	pred, err := scanPrediction(db.conn.QueryRow(
+		"SELECT"+predictionColumns+" FROM predictions WHERE id = ?", id,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if pred.Forecasts, err = db.getForecasts(id); err != nil {
+		return nil, err
+	}
+	return pred, nil
+}
+
+// GetDuePredictions returns unresolved predictions whose resolution date has
+// passed, most overdue first
+func (db *DB) GetDuePredictions(now time.Time) ([]models.Prediction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT`+predictionColumns+`
+		FROM predictions
+		WHERE status != 'resolved' AND resolve_by IS NOT NULL AND resolve_by <= ?
+		ORDER BY resolve_by ASC
+	`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var predictions []models.Prediction
+	for rows.Next() {
+		pred, err := scanPrediction(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		predictions = append(predictions, *pred)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range predictions {
+		if predictions[i].Forecasts, err = db.getForecasts(predictions[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return predictions, nil
+}
+
+// StopOverduePredictions stops running predictions of the given depth whose
+// resolution date passed before the cutoff
+func (db *DB) StopOverduePredictions(depth string, cutoff time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		UPDATE predictions SET status = 'stopped', updated_at = ?
+		WHERE status = 'running' AND depth = ? AND resolve_by IS NOT NULL AND resolve_by <= ?
+	`, time.Now().UTC(), depth, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CreateForecast attaches a probability estimate to a prediction
+func (db *DB) CreateForecast(forecast *models.Forecast) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	_, err := db.conn.Exec(`
+		INSERT INTO prediction_forecasts (id, prediction_id, statement, probability, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, forecast.ID, forecast.PredictionID, forecast.Statement, forecast.Probability, forecast.CreatedAt)
+	return err
+}
+
+// getForecasts returns the forecasts for a prediction, oldest first.
+// Callers must hold the lock.
+func (db *DB) getForecasts(predictionID string) ([]models.Forecast, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, prediction_id, statement, probability, occurred, resolved_at, created_at
+		FROM prediction_forecasts
+		WHERE prediction_id = ?
+		ORDER BY created_at ASC
+	`, predictionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var forecasts []models.Forecast
+	for rows.Next() {
+		var f models.Forecast
+		var occurred sql.NullBool
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(
+			&f.ID, &f.PredictionID, &f.Statement, &f.Probability, &occurred, &resolvedAt, &f.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if occurred.Valid {
+			f.Occurred = &occurred.Bool
+		}
+		if resolvedAt.Valid {
+			f.ResolvedAt = &resolvedAt.Time
+		}
+		forecasts = append(forecasts, f)
+	}
+	return forecasts, rows.Err()
+}
+
+// ResolvePrediction records the actual outcome of a prediction and whether
+// each listed forecast came true. Both are written in a single transaction.
+func (db *DB) ResolvePrediction(id, outcome string, occurred map[string]bool, resolvedAt time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE predictions SET status = 'resolved', results = ?, resolved_at = ?, updated_at = ?
+		WHERE id = ?
+	`, outcome, resolvedAt, resolvedAt, id); err != nil {
+		return err
+	}
+
+	for forecastID, happened := range occurred {
+		if _, err := tx.Exec(`
+			UPDATE prediction_forecasts SET occurred = ?, resolved_at = ?
+			WHERE id = ? AND prediction_id = ?
+		`, happened, resolvedAt, forecastID, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetResolvedForecasts returns every forecast with a known outcome, together
+// with the depth and topic of its prediction, for calibration scoring
+func (db *DB) GetResolvedForecasts() ([]models.ResolvedForecast, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT f.probability, f.occurred, p.depth, COALESCE(p.topic, '')
+		FROM prediction_forecasts f
+		JOIN predictions p ON p.id = f.prediction_id
+		WHERE f.occurred IS NOT NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var forecasts []models.ResolvedForecast
+	for rows.Next() {
+		var f models.ResolvedForecast
+		if err := rows.Scan(&f.Probability, &f.Occurred, &f.Depth, &f.Topic); err != nil {
+			return nil, err
+		}
+		forecasts = append(forecasts, f)
+	}
+	return forecasts, rows.Err()
+}
+
+// ============================================================================
+// RUMINATION GUARD OPERATIONS
+// ============================================================================
+
+// GetRunningPredictions returns running predictions, oldest first
+func (db *DB) GetRunningPredictions() ([]models.Prediction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT` + predictionColumns + `
+		FROM predictions
+		WHERE status = 'running'
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var predictions []models.Prediction
+	for rows.Next() {
+		pred, err := scanPrediction(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		predictions = append(predictions, *pred)
+	}
+	return predictions, rows.Err()
+}
+
+// CreatePredictionWithinLimit creates a prediction with its forecasts unless
+// limit predictions of its depth are already running, and returns how many
+// were. The count and the inserts happen in one transaction under the lock,
+// so two requests can never both take the last slot and a failed forecast
+// leaves no prediction behind. A limit of zero means unlimited.
+func (db *DB) CreatePredictionWithinLimit(pred *models.Prediction, limit int) (bool, int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback()
+
+	var running int
+	if err := tx.QueryRow(
+		"SELECT COUNT(*) FROM predictions WHERE status = 'running' AND depth = ?", pred.Depth,
+	).Scan(&running); err != nil {
+		return false, 0, err
+	}
+	if limit > 0 && running >= limit {
+		return false, running, nil
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO predictions (id, scenario, topic, time_horizon, resolve_by, budget_ends_at,
+		                         depth, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, pred.ID, pred.Scenario, pred.Topic, pred.TimeHorizon, pred.ResolveBy, pred.BudgetEndsAt,
+		pred.Depth, pred.Status, pred.CreatedAt, pred.UpdatedAt); err != nil {
+		return false, running, err
+	}
+	for _, forecast := range pred.Forecasts {
+		if _, err := tx.Exec(`
+			INSERT INTO prediction_forecasts (id, prediction_id, statement, probability, created_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, forecast.ID, forecast.PredictionID, forecast.Statement, forecast.Probability, forecast.CreatedAt); err != nil {
+			return false, running, err
+		}
+	}
+	return true, running, tx.Commit()
+}
+
+// StopExpiredPredictions stops running predictions whose time budget has run out
+func (db *DB) StopExpiredPredictions(now time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		UPDATE predictions SET status = 'stopped', updated_at = ?
+		WHERE status = 'running' AND budget_ends_at IS NOT NULL AND budget_ends_at <= ?
+	`, now, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RecordPredictionStop records that predictions on a topic were stopped
+func (db *DB) RecordPredictionStop(id, topic string, stopped int64, at time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	_, err := db.conn.Exec(`
+		INSERT INTO prediction_stops (id, topic, stopped, created_at) VALUES (?, ?, ?, ?)
+	`, id, topic, stopped, at)
+	return err
+}
+
+// GetRuminationTopics returns each topic stopped since the given time with the
+// number of stops and the predictions started on it after the first stop.
+// Predictions match a topic the same way StopPredictionByTopic does.
+func (db *DB) GetRuminationTopics(since time.Time) ([]models.RuminationTopic, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT topic, created_at FROM prediction_stops
+		WHERE created_at >= ?
+		ORDER BY created_at ASC
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var topics []models.RuminationTopic
+	firstStop := make(map[string]time.Time)
+	index := make(map[string]int)
+	for rows.Next() {
+		var topic string
+		var stoppedAt time.Time
+		if err := rows.Scan(&topic, &stoppedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		key := strings.ToLower(strings.TrimSpace(topic))
+		if i, ok := index[key]; ok {
+			topics[i].Stops++
+			continue
+		}
+		index[key] = len(topics)
+		firstStop[key] = stoppedAt
+		topics = append(topics, models.RuminationTopic{Topic: topic, Stops: 1})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Restarts are predictions on the topic started after its first stop
+	for key, i := range index {
+		pattern := "%" + topics[i].Topic + "%"
+		rows, err := db.conn.Query(`
+			SELECT created_at FROM predictions
+			WHERE created_at > ? AND (scenario LIKE ? OR topic LIKE ?)
+			ORDER BY created_at DESC
+		`, firstStop[key], pattern, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var startedAt time.Time
+			if err := rows.Scan(&startedAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if topics[i].Restarts == 0 {
+				topics[i].LastRestartedAt = &startedAt
+			}
+			topics[i].Restarts++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return topics, nil
+}
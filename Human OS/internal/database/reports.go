@@ -0,0 +1,279 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// REPORT QUERIES
+// ============================================================================
+
+// CountLoopsOpened returns the number of loops authorized within [since, until)
+func (db *DB) CountLoopsOpened(since, until time.Time) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var count int
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM loops WHERE created_at >= ? AND created_at < ?
+	`, since, until).Scan(&count)
+	return count, err
+}
+
+// CountLoopsClosedByType returns loops closed within [since, until) grouped by closure type
+func (db *DB) CountLoopsClosedByType(since, until time.Time) (map[models.ClosureType]int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT COALESCE(closure_type, ''), COUNT(*)
+		FROM loops
+		WHERE status = 'closed' AND closed_at >= ? AND closed_at < ?
+		GROUP BY closure_type
+	`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[models.ClosureType]int)
+	for rows.Next() {
+		var closureType models.ClosureType
+		var count int
+		if err := rows.Scan(&closureType, &count); err != nil {
+			return nil, err
+		}
+		counts[closureType] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetFocusSessions returns focus sessions started within [since, until), oldest first
+func (db *DB) GetFocusSessions(since, until time.Time) ([]models.FocusState, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, task_name, duration, success_criteria, is_locked,
+		       COALESCE(timebox, ''), COALESCE(fallback, ''), status,
+		       started_at, ends_at, completed_at, COALESCE(goal_id, ''), created_at, updated_at
+		FROM focus_state
+		WHERE started_at >= ? AND started_at < ?
+		ORDER BY started_at ASC
+	`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.FocusState
+	for rows.Next() {
+		var focus models.FocusState
+		var endsAt, completedAt sql.NullTime
+		if err := rows.Scan(
+			&focus.ID, &focus.TaskName, &focus.Duration, &focus.SuccessCriteria,
+			&focus.IsLocked, &focus.Timebox, &focus.Fallback, &focus.Status,
+			&focus.StartedAt, &endsAt, &completedAt, &focus.GoalID, &focus.CreatedAt, &focus.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if endsAt.Valid {
+			focus.EndsAt = endsAt.Time
+		} else {
+			focus.EndsAt = focus.StartedAt
+		}
+		if completedAt.Valid {
+			focus.CompletedAt = &completedAt.Time
+		}
+		sessions = append(sessions, focus)
+	}
+	return sessions, rows.Err()
+}
+
+// GetLoopsOpenDuring returns loops that were open at some point within
+// [since, until): created before until and not closed before since
+func (db *DB) GetLoopsOpenDuring(since, until time.Time) ([]models.Loop, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, description, priority, queue, owner, status,
+		       COALESCE(closure_type, ''), COALESCE(next_step, ''), closed_at,
+		       COALESCE(goal_id, ''), created_at, updated_at
+		FROM loops
+		WHERE created_at < ? AND (closed_at IS NULL OR closed_at >= ?)
+		ORDER BY created_at ASC
+	`, until, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var loops []models.Loop
+	for rows.Next() {
+		var loop models.Loop
+		var closedAt sql.NullTime
+		if err := rows.Scan(
+			&loop.ID, &loop.Description, &loop.Priority, &loop.Queue, &loop.Owner, &loop.Status,
+			&loop.ClosureType, &loop.NextStep, &closedAt,
+			&loop.GoalID, &loop.CreatedAt, &loop.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if closedAt.Valid {
+			loop.ClosedAt = &closedAt.Time
+		}
+		loops = append(loops, loop)
+	}
+	return loops, rows.Err()
+}
+
+// GetThreadsActiveDuring returns threads that were active at some point
+// within [since, until). A terminated thread counts as active until it was
+// last updated.
+func (db *DB) GetThreadsActiveDuring(since, until time.Time) ([]models.Thread, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, name, mode, time_scope, COALESCE(goal, ''), status, COALESCE(goal_id, ''),
+		       created_at, updated_at
+		FROM threads
+		WHERE created_at < ? AND (status = 'active' OR updated_at >= ?)
+		ORDER BY created_at ASC
+	`, until, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []models.Thread
+	for rows.Next() {
+		var thread models.Thread
+		if err := rows.Scan(
+			&thread.ID, &thread.Name, &thread.Mode, &thread.TimeScope,
+			&thread.Goal, &thread.Status, &thread.GoalID, &thread.CreatedAt, &thread.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		threads = append(threads, thread)
+	}
+	return threads, rows.Err()
+}
+
+// CountEmotionLabels returns emotional tags within [since, until) grouped by label
+func (db *DB) CountEmotionLabels(since, until time.Time) (map[string]int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT label, COUNT(*) FROM emotional_states
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY label
+	`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var label string
+		var count int
+		if err := rows.Scan(&label, &count); err != nil {
+			return nil, err
+		}
+		counts[label] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetArchivedLessons returns archive entries with a lesson created within [since, until)
+func (db *DB) GetArchivedLessons(since, until time.Time) ([]models.Archive, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, object, summary, COALESCE(lesson, ''),
+		       COALESCE(source_type, ''), COALESCE(source_id, ''), created_at
+		FROM archives
+		WHERE COALESCE(lesson, '') != '' AND created_at >= ? AND created_at < ?
+		ORDER BY created_at ASC
+	`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var archives []models.Archive
+	for rows.Next() {
+		var archive models.Archive
+		if err := rows.Scan(
+			&archive.ID, &archive.Object, &archive.Summary, &archive.Lesson,
+			&archive.SourceType, &archive.SourceID, &archive.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		archives = append(archives, archive)
+	}
+	return archives, rows.Err()
+}
+
+// CountPredictionsStarted returns the number of predictions started within [since, until)
+func (db *DB) CountPredictionsStarted(since, until time.Time) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var count int
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM predictions WHERE created_at >= ? AND created_at < ?
+	`, since, until).Scan(&count)
+	return count, err
+}
+
+// GetStoppedPredictions returns predictions stopped within [since, until)
+func (db *DB) GetStoppedPredictions(since, until time.Time) ([]models.Prediction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, scenario, COALESCE(topic, ''), time_horizon, depth, status,
+		       COALESCE(results, ''), created_at, updated_at
+		FROM predictions
+		WHERE status = 'stopped' AND updated_at >= ? AND updated_at < ?
+		ORDER BY updated_at ASC
+	`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var predictions []models.Prediction
+	for rows.Next() {
+		var pred models.Prediction
+		if err := rows.Scan(
+			&pred.ID, &pred.Scenario, &pred.Topic, &pred.TimeHorizon, &pred.Depth, &pred.Status,
+			&pred.Results, &pred.CreatedAt, &pred.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		predictions = append(predictions, pred)
+	}
+	return predictions, rows.Err()
+}
+
+// GetAIOffloadsBetween returns AI offloads created within [since, until)
+func (db *DB) GetAIOffloadsBetween(since, until time.Time) ([]models.AIOffload, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.queryAIOffloads(`
+		SELECT`+offloadColumns+`
+		FROM ai_offloads
+		WHERE created_at >= ? AND created_at < ?
+		ORDER BY created_at ASC
+	`, since, until)
+}
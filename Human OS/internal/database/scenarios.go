@@ -0,0 +1,86 @@
+package database
+
+import (
+	"database/sql"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// SCENARIO TREE OPERATIONS
+// ============================================================================
+
+// CreateScenarioBranch adds a branch to a prediction's scenario tree
+func (db *DB) CreateScenarioBranch(branch *models.ScenarioBranch) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var parentID interface{}
+	if branch.ParentID != "" {
+		parentID = branch.ParentID
+	}
+
+	_, err := db.conn.Exec(`
+		INSERT INTO scenario_branches (id, prediction_id, parent_id, condition, outcome,
+		                               probability, response, value, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, branch.ID, branch.PredictionID, parentID, branch.Condition, branch.Outcome,
+		branch.Probability, branch.Response, branch.Value, branch.CreatedAt)
+	return err
+}
+
+// GetScenarioBranches returns every branch of a prediction's scenario tree as
+// a flat list, oldest first
+func (db *DB) GetScenarioBranches(predictionID string) ([]models.ScenarioBranch, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, prediction_id, COALESCE(parent_id, ''), condition, outcome,
+		       probability, COALESCE(response, ''), value, created_at
+		FROM scenario_branches
+		WHERE prediction_id = ?
+		ORDER BY created_at ASC
+	`, predictionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var branches []models.ScenarioBranch
+	for rows.Next() {
+		var b models.ScenarioBranch
+		var value sql.NullFloat64
+		if err := rows.Scan(
+			&b.ID, &b.PredictionID, &b.ParentID, &b.Condition, &b.Outcome,
+			&b.Probability, &b.Response, &value, &b.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if value.Valid {
+			b.Value = &value.Float64
+		}
+		branches = append(branches, b)
+	}
+	return branches, rows.Err()
+}
+
+// PruneScenarioBranch deletes a branch and everything below it, returning the
+// number of branches removed
+func (db *DB) PruneScenarioBranch(predictionID, branchID string) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec(`
+		WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM scenario_branches WHERE id = ? AND prediction_id = ?
+			UNION ALL
+			SELECT b.id FROM scenario_branches b JOIN subtree s ON b.parent_id = s.id
+		)
+		DELETE FROM scenario_branches WHERE id IN (SELECT id FROM subtree)
+	`, branchID, predictionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,326 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// STATE SNAPSHOT OPERATIONS
+// ============================================================================
+
+// snapshotTables returns the tables a snapshot copies: every table except
+// the snapshot store, SQLite's own tables, full-text indexes, which are kept
+// in step with their source tables by triggers, and the AI offload queue,
+// which the offload worker tracks in memory while jobs run. Caller must hold
+// the lock.
+func snapshotTables(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query(`
+		SELECT name, COALESCE(sql, '') FROM sqlite_master WHERE type = 'table' ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names, virtual []string
+	for rows.Next() {
+		var name, definition string
+		if err := rows.Scan(&name, &definition); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(strings.ToUpper(definition), "CREATE VIRTUAL TABLE") {
+			virtual = append(virtual, name)
+			continue
+		}
+		if strings.HasPrefix(name, "sqlite_") || strings.HasPrefix(name, "state_snapshot") ||
+			strings.HasPrefix(name, "ai_offload") {
+			continue
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Drop the shadow tables that store each full-text index
+	tables := names[:0]
+	for _, name := range names {
+		shadow := false
+		for _, v := range virtual {
+			if strings.HasPrefix(name, v+"_") {
+				shadow = true
+			}
+		}
+		if !shadow {
+			tables = append(tables, name)
+		}
+	}
+	return tables, nil
+}
+
+// tableColumns returns a table's columns in order. Caller must hold the lock.
+func tableColumns(tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+// createSnapshot copies every table into the snapshot store within tx, so
+// the copy is consistent with whatever tx changes next. Each table is stored
+// as a JSON array of row objects built by SQLite itself, so values -
+// timestamps included - are restored exactly as they were stored. Caller
+// must hold the lock.
+func createSnapshot(tx *sql.Tx, id, reason string, now time.Time) (*models.StateSnapshot, error) {
+	tables, err := snapshotTables(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.StateSnapshot{ID: id, Reason: reason, Tables: make(map[string]int), CreatedAt: now}
+	for _, table := range tables {
+		columns, err := tableColumns(tx, table)
+		if err != nil {
+			return nil, err
+		}
+		fields := make([]string, len(columns))
+		for i, column := range columns {
+			fields[i] = fmt.Sprintf("'%s', \"%s\"", column, column)
+		}
+
+		var count int
+		var rows string
+		if err := tx.QueryRow(fmt.Sprintf(
+			"SELECT COUNT(*), COALESCE(json_group_array(json_object(%s)), '[]') FROM %s",
+			strings.Join(fields, ", "), table,
+		)).Scan(&count, &rows); err != nil {
+			return nil, fmt.Errorf("failed to copy %s: %w", table, err)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO state_snapshot_tables (snapshot_id, table_name, columns, row_count, rows)
+			VALUES (?, ?, ?, ?, ?)
+		`, id, table, strings.Join(columns, ","), count, rows); err != nil {
+			return nil, err
+		}
+		snapshot.Tables[table] = count
+		snapshot.RowCount += count
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO state_snapshots (id, reason, row_count, created_at)
+		VALUES (?, ?, ?, ?)
+	`, id, reason, snapshot.RowCount, now); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// RestoreSnapshot snapshots the current state as undoID and replaces the
+// contents of every table in snapshot id with the rows it copied, in one
+// transaction, and returns the undo snapshot. Columns added since the
+// snapshot was taken get their defaults; tables created since, and tables
+// snapshots no longer copy, are left alone.
+func (db *DB) RestoreSnapshot(id, undoID string, now time.Time) (*models.StateSnapshot, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	undo, err := createSnapshot(tx, undoID, "restore", now)
+	if err != nil {
+		return nil, err
+	}
+	if err := restoreSnapshot(tx, id); err != nil {
+		return nil, err
+	}
+	return undo, tx.Commit()
+}
+
+// restoreSnapshot replaces the contents of every table in a snapshot with
+// the rows it copied, within tx. Caller must hold the lock.
+func restoreSnapshot(tx *sql.Tx, id string) error {
+	type copied struct {
+		table, columns, rows string
+	}
+	rows, err := tx.Query(`
+		SELECT table_name, columns, rows FROM state_snapshot_tables WHERE snapshot_id = ?
+	`, id)
+	if err != nil {
+		return err
+	}
+	var copies []copied
+	for rows.Next() {
+		var c copied
+		if err := rows.Scan(&c.table, &c.columns, &c.rows); err != nil {
+			rows.Close()
+			return err
+		}
+		copies = append(copies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	current, err := snapshotTables(tx)
+	if err != nil {
+		return err
+	}
+	for _, c := range copies {
+		if !slices.Contains(current, c.table) {
+			continue
+		}
+		existing, err := tableColumns(tx, c.table)
+		if err != nil {
+			return err
+		}
+		var columns, values []string
+		for _, column := range strings.Split(c.columns, ",") {
+			if slices.Contains(existing, column) {
+				columns = append(columns, `"`+column+`"`)
+				values = append(values, fmt.Sprintf("json_extract(value, '$.%s')", column))
+			}
+		}
+
+		if _, err := tx.Exec("DELETE FROM " + c.table); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(fmt.Sprintf(
+			"INSERT INTO %s (%s) SELECT %s FROM json_each(?)",
+			c.table, strings.Join(columns, ", "), strings.Join(values, ", "),
+		), c.rows); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", c.table, err)
+		}
+	}
+	return nil
+}
+
+// GetSnapshot retrieves a snapshot's summary, without the rows it copied
+func (db *DB) GetSnapshot(id string) (*models.StateSnapshot, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	snapshots, err := db.querySnapshots(`
+		SELECT id, reason, row_count, created_at FROM state_snapshots WHERE id = ?
+	`, id)
+	if err != nil || len(snapshots) == 0 {
+		return nil, err
+	}
+	return &snapshots[0], nil
+}
+
+// GetSnapshots returns snapshot summaries, newest first
+func (db *DB) GetSnapshots(limit int) ([]models.StateSnapshot, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.querySnapshots(`
+		SELECT id, reason, row_count, created_at FROM state_snapshots
+		ORDER BY created_at DESC LIMIT ?
+	`, limit)
+}
+
+// querySnapshots runs a query selecting snapshot summaries and attaches the
+// row count of each table copied. Caller must hold the lock.
+func (db *DB) querySnapshots(query string, args ...interface{}) ([]models.StateSnapshot, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.StateSnapshot
+	for rows.Next() {
+		snapshot := models.StateSnapshot{Tables: make(map[string]int)}
+		if err := rows.Scan(&snapshot.ID, &snapshot.Reason, &snapshot.RowCount, &snapshot.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range snapshots {
+		tableRows, err := db.conn.Query(`
+			SELECT table_name, row_count FROM state_snapshot_tables WHERE snapshot_id = ?
+		`, snapshots[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		for tableRows.Next() {
+			var table string
+			var count int
+			if err := tableRows.Scan(&table, &count); err != nil {
+				tableRows.Close()
+				return nil, err
+			}
+			snapshots[i].Tables[table] = count
+		}
+		tableRows.Close()
+		if err := tableRows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return snapshots, nil
+}
+
+// PruneSnapshots deletes all but the newest keep snapshots
+func (db *DB) PruneSnapshots(keep int) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		DELETE FROM state_snapshots WHERE id NOT IN (
+			SELECT id FROM state_snapshots ORDER BY created_at DESC LIMIT ?
+		)
+	`, keep)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM state_snapshot_tables WHERE snapshot_id NOT IN (SELECT id FROM state_snapshots)
+	`); err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// TELOS OPERATIONS
+// ============================================================================
+
+// telosColumns is the column list read by scanTelosItem
+const telosColumns = `
+	id, section, text, COALESCE(refs, ''), position, imported_at`
+
+// scanTelosItem reads a row selected with telosColumns
+func scanTelosItem(scan func(dest ...interface{}) error) (*models.TelosItem, error) {
+	var item models.TelosItem
+	var refs string
+	if err := scan(
+		&item.ID, &item.Section, &item.Text, &refs, &item.Position, &item.ImportedAt,
+	); err != nil {
+		return nil, err
+	}
+	if refs != "" {
+		item.Refs = strings.Split(refs, ",")
+	}
+	return &item, nil
+}
+
+// ImportTelosItems replaces the stored Telos context with items, numbering
+// them in the order given. The file is the source of truth, so items no
+// longer in it are removed; work linked to a removed goal keeps its ID.
+func (db *DB) ImportTelosItems(items []models.TelosItem, now time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM telos_items"); err != nil {
+		return err
+	}
+	for i, item := range items {
+		if _, err := tx.Exec(`
+			INSERT INTO telos_items (id, section, text, refs, position, imported_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, item.ID, item.Section, item.Text, strings.Join(item.Refs, ","), i+1, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetTelosItem retrieves a Telos item by ID
+func (db *DB) GetTelosItem(id string) (*models.TelosItem, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	item, err := scanTelosItem(db.conn.QueryRow(
+		"SELECT"+telosColumns+" FROM telos_items WHERE id = ?", strings.ToUpper(id),
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetTelosItems returns the imported Telos items in file order, optionally
+// only those in one section
+func (db *DB) GetTelosItems(section string) ([]models.TelosItem, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := "SELECT" + telosColumns + " FROM telos_items"
+	var args []interface{}
+	if section != "" {
+		query += " WHERE section = ?"
+		args = append(args, strings.ToUpper(section))
+	}
+	query += " ORDER BY position ASC"
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.TelosItem
+	for rows.Next() {
+		item, err := scanTelosItem(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
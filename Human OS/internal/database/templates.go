@@ -0,0 +1,162 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// PROMPT TEMPLATE OPERATIONS
+// ============================================================================
+// Templates are never edited in place: saving one adds a version, and the
+// highest version of a task type is the one offloads use.
+
+// defaultPromptBody gives the provider the scope and the linked context
+const defaultPromptBody = `{{scope}}
+
+Current focus: {{focus}}
+
+Related open loops:
+{{loops}}
+
+Goals:
+{{goals}}`
+
+// defaultPromptTemplates are installed when the template table is created,
+// as version 1 of each offload task type
+var defaultPromptTemplates = []models.PromptTemplate{
+	{TaskType: "plan", Instructions: "Produce a structured, numbered plan. Each step should be concrete and small enough to start today."},
+	{TaskType: "draft", Instructions: "Write a first draft. Favour completeness over polish; it will be edited."},
+	{TaskType: "refactor", Instructions: "Propose improvements that keep the original intent. Say what changes and why."},
+	{TaskType: "summarize", Instructions: "Compress the material. Keep decisions, deadlines and open questions; drop the rest."},
+	{TaskType: "explore", Instructions: "Lay out the options with their trade-offs. Do not decide; inform the decision."},
+}
+
+// promptTemplateColumns is the column list read by scanPromptTemplate
+const promptTemplateColumns = `id, task_type, version, instructions, body, created_at`
+
+// scanPromptTemplate reads a row selected with promptTemplateColumns
+func scanPromptTemplate(scan func(dest ...interface{}) error) (*models.PromptTemplate, error) {
+	var t models.PromptTemplate
+	if err := scan(&t.ID, &t.TaskType, &t.Version, &t.Instructions, &t.Body, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// seedPromptTemplates installs the default templates into a newly created
+// table
+func (db *DB) seedPromptTemplates() error {
+	now := time.Now().UTC()
+	for _, t := range defaultPromptTemplates {
+		if _, err := db.conn.Exec(`
+			INSERT INTO prompt_templates (id, task_type, version, instructions, body, created_at)
+			VALUES (?, ?, 1, ?, ?, ?)
+		`, uuid.New().String(), t.TaskType, t.Instructions, defaultPromptBody, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPromptTemplates returns the current version of every task type's
+// template
+func (db *DB) GetPromptTemplates() ([]models.PromptTemplate, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.queryPromptTemplates(`
+		SELECT ` + promptTemplateColumns + `
+		FROM prompt_templates t
+		WHERE version = (SELECT MAX(version) FROM prompt_templates WHERE task_type = t.task_type)
+		ORDER BY task_type ASC
+	`)
+}
+
+// GetPromptTemplate returns one version of a task type's template, or the
+// current version when version is 0
+func (db *DB) GetPromptTemplate(taskType string, version int) (*models.PromptTemplate, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := "SELECT " + promptTemplateColumns + " FROM prompt_templates WHERE task_type = ?"
+	args := []interface{}{taskType}
+	if version > 0 {
+		query += " AND version = ?"
+		args = append(args, version)
+	}
+	query += " ORDER BY version DESC LIMIT 1"
+
+	t, err := scanPromptTemplate(db.conn.QueryRow(query, args...).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+// GetPromptTemplateVersions returns every version of a task type's
+// template, newest first
+func (db *DB) GetPromptTemplateVersions(taskType string) ([]models.PromptTemplate, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.queryPromptTemplates(
+		"SELECT "+promptTemplateColumns+" FROM prompt_templates WHERE task_type = ? ORDER BY version DESC",
+		taskType,
+	)
+}
+
+// CreatePromptTemplateVersion saves a template as the next version of its
+// task type and sets its Version
+func (db *DB) CreatePromptTemplateVersion(t *models.PromptTemplate) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.conn.QueryRow(
+		"SELECT COALESCE(MAX(version), 0) + 1 FROM prompt_templates WHERE task_type = ?", t.TaskType,
+	).Scan(&t.Version); err != nil {
+		return err
+	}
+
+	_, err := db.conn.Exec(`
+		INSERT INTO prompt_templates (id, task_type, version, instructions, body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, t.ID, t.TaskType, t.Version, t.Instructions, t.Body, t.CreatedAt)
+	return err
+}
+
+// DeletePromptTemplate removes every version of a task type's template
+func (db *DB) DeletePromptTemplate(taskType string) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result, err := db.conn.Exec("DELETE FROM prompt_templates WHERE task_type = ?", taskType)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// queryPromptTemplates runs a query selecting promptTemplateColumns. Caller
+// must hold the lock.
+func (db *DB) queryPromptTemplates(query string, args ...interface{}) ([]models.PromptTemplate, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []models.PromptTemplate
+	for rows.Next() {
+		t, err := scanPromptTemplate(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
@@ -0,0 +1,245 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// ============================================================================
+// TRIGGER OPERATIONS
+// ============================================================================
+
+// triggerColumns is the column list read by scanTrigger
+const triggerColumns = `
+	t.id, t.name, t.kind, COALESCE(t.aliases, ''), t.created_at, t.updated_at,
+	(SELECT COUNT(*) FROM emotion_triggers et WHERE et.trigger_id = t.id)`
+
+// scanTrigger reads a row selected with triggerColumns
+func scanTrigger(scan func(dest ...interface{}) error) (*models.Trigger, error) {
+	var trigger models.Trigger
+	var aliases string
+	if err := scan(
+		&trigger.ID, &trigger.Name, &trigger.Kind, &aliases,
+		&trigger.CreatedAt, &trigger.UpdatedAt, &trigger.Tags,
+	); err != nil {
+		return nil, err
+	}
+	trigger.Aliases = splitSynonyms(aliases)
+	return &trigger, nil
+}
+
+// GetOrCreateTrigger returns the trigger with the given name, creating it
+// when it does not exist yet
+func (db *DB) GetOrCreateTrigger(trigger *models.Trigger) (*models.Trigger, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, err := db.conn.Exec(`
+		INSERT OR IGNORE INTO triggers (id, name, kind, aliases, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, trigger.ID, trigger.Name, trigger.Kind, strings.Join(trigger.Aliases, ","),
+		trigger.CreatedAt, trigger.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return scanTrigger(db.conn.QueryRow(
+		"SELECT"+triggerColumns+" FROM triggers t WHERE t.name = ?", trigger.Name,
+	).Scan)
+}
+
+// GetTrigger retrieves a trigger by ID
+func (db *DB) GetTrigger(id string) (*models.Trigger, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	trigger, err := scanTrigger(db.conn.QueryRow(
+		"SELECT"+triggerColumns+" FROM triggers t WHERE t.id = ?", id,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return trigger, err
+}
+
+// GetTriggers returns every trigger with its tag count, most tagged first
+func (db *DB) GetTriggers() ([]models.Trigger, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT` + triggerColumns + `
+		FROM triggers t
+		ORDER BY 7 DESC, t.name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var triggers []models.Trigger
+	for rows.Next() {
+		trigger, err := scanTrigger(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, *trigger)
+	}
+	return triggers, rows.Err()
+}
+
+// SaveTrigger stores a trigger, creating it when create is set and
+// otherwise replacing its name, kind and aliases, and merges the triggers in
+// mergeIDs into it, all in one transaction. It returns the stored trigger, or
+// nil if an update found no trigger with the ID.
+func (db *DB) SaveTrigger(trigger *models.Trigger, create bool, mergeIDs []string) (*models.Trigger, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	aliases := strings.Join(trigger.Aliases, ",")
+	if create {
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO triggers (id, name, kind, aliases, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, trigger.ID, trigger.Name, trigger.Kind, aliases, trigger.CreatedAt, trigger.UpdatedAt); err != nil {
+			return nil, err
+		}
+	} else {
+		result, err := tx.Exec(`
+			UPDATE triggers SET name = ?, kind = ?, aliases = ?, updated_at = ?
+			WHERE id = ?
+		`, trigger.Name, trigger.Kind, aliases, trigger.UpdatedAt, trigger.ID)
+		if err != nil {
+			return nil, err
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return nil, err
+		}
+	}
+
+	saved, err := scanTrigger(tx.QueryRow(
+		"SELECT"+triggerColumns+" FROM triggers t WHERE t.name = ?", trigger.Name,
+	).Scan)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fromID := range mergeIDs {
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO emotion_triggers (state_id, trigger_id)
+			SELECT state_id, ? FROM emotion_triggers WHERE trigger_id = ?
+		`, saved.ID, fromID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec("DELETE FROM emotion_triggers WHERE trigger_id = ?", fromID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec("DELETE FROM triggers WHERE id = ?", fromID); err != nil {
+			return nil, err
+		}
+	}
+	if len(mergeIDs) > 0 {
+		if saved, err = scanTrigger(tx.QueryRow(
+			"SELECT"+triggerColumns+" FROM triggers t WHERE t.id = ?", saved.ID,
+		).Scan); err != nil {
+			return nil, err
+		}
+	}
+	return saved, tx.Commit()
+}
+
+// DeleteTrigger removes a trigger and its links to emotional states
+func (db *DB) DeleteTrigger(id string) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM emotion_triggers WHERE trigger_id = ?", id); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec("DELETE FROM triggers WHERE id = ?", id)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// LinkEmotionTriggers records the triggers an emotional state was blamed on
+func (db *DB) LinkEmotionTriggers(stateID string, triggerIDs []string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, triggerID := range triggerIDs {
+		if _, err := db.conn.Exec(`
+			INSERT OR IGNORE INTO emotion_triggers (state_id, trigger_id) VALUES (?, ?)
+		`, stateID, triggerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEmotionTriggerLinks returns the trigger IDs of each emotional state
+// tagged within [since, until), keyed by state ID
+func (db *DB) GetEmotionTriggerLinks(since, until time.Time) (map[string][]string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT et.state_id, et.trigger_id
+		FROM emotion_triggers et
+		JOIN emotional_states es ON es.id = et.state_id
+		WHERE es.created_at >= ? AND es.created_at < ?
+	`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make(map[string][]string)
+	for rows.Next() {
+		var stateID, triggerID string
+		if err := rows.Scan(&stateID, &triggerID); err != nil {
+			return nil, err
+		}
+		links[stateID] = append(links[stateID], triggerID)
+	}
+	return links, rows.Err()
+}
+
+// GetUnlinkedEmotionalStates returns emotional states tagged within
+// [since, until) that have a source guess but no triggers, oldest first
+func (db *DB) GetUnlinkedEmotionalStates(since, until time.Time) ([]models.EmotionalState, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`
+		SELECT id, label, source_guess, intensity, created_at
+		FROM emotional_states es
+		WHERE created_at >= ? AND created_at < ? AND TRIM(source_guess) != ''
+		  AND NOT EXISTS (SELECT 1 FROM emotion_triggers et WHERE et.state_id = es.id)
+		ORDER BY created_at ASC
+	`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEmotionalStates(rows)
+}
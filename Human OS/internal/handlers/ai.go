@@ -5,36 +5,78 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"humanos-api/internal/ai"
 	"humanos-api/internal/database"
 	"humanos-api/internal/models"
+	"humanos-api/internal/services"
 )
 
 // AIHandler handles AI-related endpoints
 type AIHandler struct {
-	db *database.DB
+	db        *database.DB
+	offloads  *services.OffloadService
+	prompts   *services.PromptService
+	summaries *services.StateSummaryService
+	assists   *services.AssistService
 }
 
 // NewAIHandler creates a new AI handler
-func NewAIHandler(db *database.DB) *AIHandler {
-	return &AIHandler{db: db}
+func NewAIHandler(
+	db *database.DB,
+	offloads *services.OffloadService,
+	prompts *services.PromptService,
+	summaries *services.StateSummaryService,
+	assists *services.AssistService,
+) *AIHandler {
+	return &AIHandler{db: db, offloads: offloads, prompts: prompts, summaries: summaries, assists: assists}
 }
 
 // Offload handles POST /api/v1/ai/offload
 // Offloading to AI means explicitly delegating a task type (plan, draft,
 // refactor, summarize, explore) with a defined scope. This frees up human
 // cognitive resources for tasks that require uniquely human judgment.
+// The offload is queued and run in the background on the configured AI
+// provider; its result is stored on the offload record and waits for review.
+// The prompt is rendered from the task type's template when queued, with the
+// current focus, related loops and goals as context. A quorum offload runs
+// on every quorum provider and only produces a result when their answers
+// agree.
 func (h *AIHandler) Offload(c *gin.Context) {
 	var req models.AIOffloadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
 		return
 	}
+	req.TaskType = normalizeTaskType(req.TaskType)
+	if req.TaskType == "" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", "task_type must not be blank"))
+		return
+	}
+	if ai.ReservedTaskType(req.TaskType) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Invalid request",
+			fmt.Sprintf("task_type %q is reserved for requests Human OS makes itself", req.TaskType),
+		))
+		return
+	}
+
+	quorum := req.Quorum || h.offloads.QuorumRequired()
+	if quorum && !h.offloads.QuorumAvailable() {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Quorum not configured",
+			"Set AI_QUORUM_PROVIDERS to at least two providers to run quorum offloads",
+		))
+		return
+	}
 
 	now := time.Now().UTC()
 	offload := &models.AIOffload{
@@ -42,11 +84,19 @@ func (h *AIHandler) Offload(c *gin.Context) {
 		TaskType:  req.TaskType,
 		Scope:     req.Scope,
 		Status:    "pending",
+		Quorum:    quorum,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	if err := h.db.CreateAIOffload(offload); err != nil {
+	if err := h.prompts.Render(offload); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to prepare AI prompt",
+			err.Error(),
+		))
+		return
+	}
+	if err := h.offloads.Enqueue(offload); err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			"Failed to register AI offload",
 			err.Error(),
@@ -70,8 +120,13 @@ func (h *AIHandler) Offload(c *gin.Context) {
 		taskAdvice = "Task delegated to AI. Monitor progress."
 	}
 
+	runningOn := "Running on " + h.offloads.ProviderName() + "."
+	if quorum {
+		runningOn = "Running in quorum (" + offload.Strategy + ")."
+	}
+
 	c.JSON(http.StatusOK, models.AIResponse{
-		Message:   "Offloaded to AI: " + req.TaskType + " [" + req.Scope + "]. " + taskAdvice,
+		Message:   "Offloaded to AI: " + req.TaskType + " [" + req.Scope + "]. " + runningOn + " " + taskAdvice,
 		ID:        offload.ID,
 		Timestamp: now,
 	})
@@ -80,7 +135,10 @@ func (h *AIHandler) Offload(c *gin.Context) {
 // AssistForExecution handles POST /api/v1/ai/assist-for-execution
 // This is different from offload - here you're still doing the work, but
 // getting AI assistance for specific aspects of execution. The human remains
-// in the driver's seat; AI is the copilot.
+// in the driver's seat; AI is the copilot. The task becomes an assist
+// session: the AI (or, with "template", a local template) breaks it into
+// steps you check off, and the session is linked to your current focus so
+// its progress shows on the dashboard.
 func (h *AIHandler) AssistForExecution(c *gin.Context) {
 	var req models.AIAssistRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -89,28 +147,741 @@ func (h *AIHandler) AssistForExecution(c *gin.Context) {
 	}
 
 	now := time.Now().UTC()
+	session, err := h.assists.Start(req.Task, req.AssistanceType, req.Template, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to start assist session",
+			err.Error(),
+		))
+		return
+	}
 
-	// Log the assist request (using the same offload table for simplicity)
-	offload := &models.AIOffload{
-		ID:        uuid.New().String(),
-		TaskType:  "assist:" + req.AssistanceType,
-		Scope:     req.Task,
-		Status:    "processing",
-		CreatedAt: now,
-		UpdatedAt: now,
+	message := "AI assistance activated for: " + req.Task + ". Assistance type: " + req.AssistanceType + ". You lead, AI supports."
+	if session.Status == "planning" {
+		message += " Breaking the task into steps on " + h.offloads.ProviderName() + "."
+	} else {
+		message += fmt.Sprintf(" %d steps ready.", len(session.Steps))
 	}
+	if session.FocusID != "" {
+		message += " Linked to your current focus."
+	}
+
+	c.JSON(http.StatusOK, models.AssistSessionResponse{
+		Message:   message,
+		Session:   session,
+		Timestamp: now,
+	})
+}
 
-	if err := h.db.CreateAIOffload(offload); err != nil {
+// ListAssistSessions handles GET /api/v1/ai/assist/sessions?status=active&limit=50
+// Lists assist sessions with their steps, newest first.
+func (h *AIHandler) ListAssistSessions(c *gin.Context) {
+	limit := 50
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 500 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Invalid limit",
+				"Limit must be a number between 1 and 500",
+			))
+			return
+		}
+		limit = parsed
+	}
+
+	now := time.Now().UTC()
+	sessions, err := h.assists.List(c.Query("status"), limit)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"Failed to register assist request",
+			"Failed to list assist sessions",
 			err.Error(),
 		))
 		return
 	}
+	if sessions == nil {
+		sessions = []models.AssistSession{}
+	}
 
-	c.JSON(http.StatusOK, models.AIResponse{
-		Message:   "AI assistance activated for: " + req.Task + ". Assistance type: " + req.AssistanceType + ". You lead, AI supports.",
-		ID:        offload.ID,
+	c.JSON(http.StatusOK, models.AssistSessionResponse{
+		Message:   fmt.Sprintf("%d assist session(s).", len(sessions)),
+		Sessions:  sessions,
+		Timestamp: now,
+	})
+}
+
+// GetAssistSession handles GET /api/v1/ai/assist/sessions/:id
+// Returns a session's steps and how many are done. A session is "planning"
+// until its steps are taken from the finished breakdown; stuck steps carry
+// the help requested for them once it arrives.
+func (h *AIHandler) GetAssistSession(c *gin.Context) {
+	session, ok := h.loadAssistSession(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AssistSessionResponse{
+		Message:   fmt.Sprintf("Session %s: %d of %d steps done.", session.Status, session.StepsDone, len(session.Steps)),
+		Session:   session,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// PlanAssistSession handles POST /api/v1/ai/assist/sessions/:id/plan
+// Gives a planning session its steps once its breakdown request has
+// finished. The queue normally does this as the breakdown finishes; this
+// catches up a session it missed. A breakdown that failed, was cancelled or
+// listed no steps falls back to the local template.
+func (h *AIHandler) PlanAssistSession(c *gin.Context) {
+	session, err := h.assists.Plan(c.Param("id"), time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to plan assist session",
+			err.Error(),
+		))
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Session not found",
+			"No assist session exists with the provided ID",
+		))
+		return
+	}
+
+	message := fmt.Sprintf("%d steps ready from the %s.", len(session.Steps), session.Source)
+	if session.Status == "planning" {
+		message = "Breakdown still running; try again once offload " + session.OffloadID + " has finished."
+	}
+	c.JSON(http.StatusOK, models.AssistSessionResponse{
+		Message:   message,
+		Session:   session,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// CompleteAssistStep handles POST /api/v1/ai/assist/sessions/:id/steps/:position/done
+// Checks off a step, stuck or not. Checking off the last step completes the
+// session.
+func (h *AIHandler) CompleteAssistStep(c *gin.Context) {
+	session, step, ok := h.loadAssistStep(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	done, err := h.assists.CompleteStep(session.ID, step.Position, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to complete step",
+			err.Error(),
+		))
+		return
+	}
+	if !done {
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			"Step already done",
+			fmt.Sprintf("Step %d is already checked off", step.Position),
+		))
+		return
+	}
+
+	if session, ok = h.loadAssistSession(c); !ok {
+		return
+	}
+	message := fmt.Sprintf("Step %d done: %d of %d steps done.", step.Position, session.StepsDone, len(session.Steps))
+	if session.Status == "completed" {
+		message += " Session complete."
+	}
+	c.JSON(http.StatusOK, models.AssistSessionResponse{
+		Message:   message,
+		Session:   session,
 		Timestamp: now,
 	})
 }
+
+// StuckAssistStep handles POST /api/v1/ai/assist/sessions/:id/steps/:position/stuck
+// Flags a step as stuck and asks the AI for a way past it, with what is in
+// the way as context. The help request runs on the queue; its result appears
+// on the step. Asking again cancels and replaces the earlier request.
+func (h *AIHandler) StuckAssistStep(c *gin.Context) {
+	var req models.AssistStuckRequest
+	// The body is optional; an empty request asks for help without a note
+	if err := bindOptionalJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	session, step, ok := h.loadAssistStep(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	stuck, err := h.assists.Stuck(session, *step, req.Note, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to request help",
+			err.Error(),
+		))
+		return
+	}
+	if !stuck {
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			"Step already done",
+			fmt.Sprintf("Step %d is already checked off", step.Position),
+		))
+		return
+	}
+
+	if session, ok = h.loadAssistSession(c); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, models.AssistSessionResponse{
+		Message:   fmt.Sprintf("Help requested for step %d on %s.", step.Position, h.offloads.ProviderName()),
+		Session:   session,
+		Timestamp: now,
+	})
+}
+
+// SummarizeState handles POST /api/v1/ai/summarize-state
+// Gathers every open loop, pending task, captured idea and active thread
+// into one package and asks the AI for a prioritized briefing and the items
+// worth closing. Seeing everything at once, ranked, is what lets you stop
+// carrying it. Runs while you wait on the configured provider; the stub
+// answers by fixed rules.
+func (h *AIHandler) SummarizeState(c *gin.Context) {
+	now := time.Now().UTC()
+	summary, err := h.summaries.Summarize(c.Request.Context(), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to summarize state",
+			err.Error(),
+		))
+		return
+	}
+
+	counts := map[string]int{"loop": 0, "task": 0, "idea": 0, "thread": 0}
+	for _, item := range summary.Package.Items {
+		counts[item.Kind]++
+	}
+
+	message := fmt.Sprintf("Briefing on %d open item(s): %d priority item(s), %d suggested closure(s).",
+		len(summary.Package.Items), len(summary.Briefing.Priorities), len(summary.Briefing.Closures))
+	if summary.Unstructured {
+		message += " The provider's reply was not a structured briefing; it is shown as the summary."
+	}
+
+	c.JSON(http.StatusOK, models.StateSummaryResponse{
+		Message:   message,
+		Provider:  h.summaries.ProviderName(),
+		Counts:    counts,
+		Briefing:  summary.Briefing,
+		Timestamp: now,
+	})
+}
+
+// ListOffloads handles GET /api/v1/ai/offloads?status=pending&task_type=plan&limit=50
+// Lists queued, running and finished offloads, newest first, so delegated
+// work can be watched rather than assumed done.
+func (h *AIHandler) ListOffloads(c *gin.Context) {
+	limit := 50
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 500 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Invalid limit",
+				"Limit must be a number between 1 and 500",
+			))
+			return
+		}
+		limit = parsed
+	}
+
+	offloads, err := h.db.GetAIOffloads(c.Query("status"), c.Query("task_type"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list AI offloads",
+			err.Error(),
+		))
+		return
+	}
+	if offloads == nil {
+		offloads = []models.AIOffload{}
+	}
+
+	c.JSON(http.StatusOK, models.AIOffloadResponse{
+		Message:   fmt.Sprintf("%d offload(s).", len(offloads)),
+		Offloads:  offloads,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// GetOffload handles GET /api/v1/ai/offloads/:id
+// Returns an offload with its status, attempts and result or last error,
+// and the reviews of its result. Quorum offloads include every member's vote
+// on every attempt.
+func (h *AIHandler) GetOffload(c *gin.Context) {
+	offload, ok := h.loadOffload(c)
+	if !ok {
+		return
+	}
+
+	if offload.Quorum {
+		votes, err := h.db.GetAIOffloadVotes(offload.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				"Failed to get quorum votes",
+				err.Error(),
+			))
+			return
+		}
+		offload.Votes = votes
+	}
+
+	reviews, err := h.db.GetAIOffloadReviews(offload.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to get offload reviews",
+			err.Error(),
+		))
+		return
+	}
+	offload.Reviews = reviews
+
+	c.JSON(http.StatusOK, models.AIOffloadResponse{
+		Message:   "Offload " + offload.Status + ".",
+		Offload:   offload,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// CancelOffload handles POST /api/v1/ai/offloads/:id/cancel
+// A pending offload is cancelled immediately; a running one is interrupted
+// and shows as cancelled once its worker stops. Finished offloads cannot be
+// cancelled.
+func (h *AIHandler) CancelOffload(c *gin.Context) {
+	offload, ok := h.loadOffload(c)
+	if !ok {
+		return
+	}
+
+	cancelled, err := h.offloads.Cancel(offload.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to cancel AI offload",
+			err.Error(),
+		))
+		return
+	}
+	if !cancelled {
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			"Offload already finished",
+			"Offload is "+offload.Status+"; only pending or processing offloads can be cancelled",
+		))
+		return
+	}
+
+	if offload, ok = h.loadOffload(c); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, models.AIOffloadResponse{
+		Message:   "Offload cancellation requested.",
+		Offload:   offload,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// ApproveOffload handles POST /api/v1/ai/offloads/:id/approve
+// Accepts an offload's result as the AI produced it. Nothing an AI produced
+// counts as done until a person has looked at it; the approval is kept as an
+// audit record of who accepted what.
+func (h *AIHandler) ApproveOffload(c *gin.Context) {
+	h.reviewOffload(c, "approved")
+}
+
+// EditOffload handles POST /api/v1/ai/offloads/:id/edit
+// Accepts an offload with a corrected result. The audit record keeps both
+// what the AI produced and what was accepted.
+func (h *AIHandler) EditOffload(c *gin.Context) {
+	h.reviewOffload(c, "edited")
+}
+
+// RejectOffload handles POST /api/v1/ai/offloads/:id/reject
+// Sends an offload back to the queue. The rejected result and the reviewer's
+// feedback go to the provider with the next attempt, and the result waits
+// for review again.
+func (h *AIHandler) RejectOffload(c *gin.Context) {
+	h.reviewOffload(c, "rejected")
+}
+
+// ListReviews handles GET /api/v1/ai/reviews?reviewer=sam&action=edited&limit=50
+// The review audit trail, newest first: who approved, edited or rejected
+// which AI result.
+func (h *AIHandler) ListReviews(c *gin.Context) {
+	limit := 50
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 500 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Invalid limit",
+				"Limit must be a number between 1 and 500",
+			))
+			return
+		}
+		limit = parsed
+	}
+
+	reviews, err := h.db.GetRecentAIOffloadReviews(c.Query("reviewer"), c.Query("action"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list AI reviews",
+			err.Error(),
+		))
+		return
+	}
+	if reviews == nil {
+		reviews = []models.AIOffloadReview{}
+	}
+
+	c.JSON(http.StatusOK, models.AIOffloadReviewResponse{
+		Message:   fmt.Sprintf("%d review(s).", len(reviews)),
+		Reviews:   reviews,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// reviewOffload records a review with the given action and responds with the
+// updated offload
+func (h *AIHandler) reviewOffload(c *gin.Context, action string) {
+	var req models.AIOffloadReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+	if action == "rejected" && req.Feedback == "" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Invalid request",
+			"Feedback is required when rejecting a result",
+		))
+		return
+	}
+	if action == "edited" && req.Result == "" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Invalid request",
+			"The edited result is required",
+		))
+		return
+	}
+
+	offload, ok := h.loadOffload(c)
+	if !ok {
+		return
+	}
+
+	review := &models.AIOffloadReview{
+		ID:        uuid.New().String(),
+		OffloadID: offload.ID,
+		Action:    action,
+		Reviewer:  req.Reviewer,
+		Feedback:  req.Feedback,
+		CreatedAt: time.Now().UTC(),
+	}
+	if action == "edited" {
+		review.AcceptedResult = req.Result
+	}
+
+	reviewed, err := h.offloads.Review(review)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to review AI offload",
+			err.Error(),
+		))
+		return
+	}
+	if !reviewed {
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			"Offload not awaiting review",
+			"Offload is "+offload.Status+"; only offloads awaiting review can be approved, edited or rejected",
+		))
+		return
+	}
+
+	var message string
+	switch action {
+	case "approved":
+		message = "Result approved by " + req.Reviewer + "."
+	case "edited":
+		message = "Edited result accepted by " + req.Reviewer + "."
+	default:
+		message = "Result rejected by " + req.Reviewer + ". Re-queued with feedback."
+	}
+
+	if offload, ok = h.loadOffload(c); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, models.AIOffloadResponse{
+		Message:   message,
+		Offload:   offload,
+		Timestamp: review.CreatedAt,
+	})
+}
+
+// ListTemplates handles GET /api/v1/ai/templates
+// Returns the current prompt template of every task type. What an offload
+// asks for shapes what comes back, so the prompts are yours to tune.
+func (h *AIHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.db.GetPromptTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list prompt templates",
+			err.Error(),
+		))
+		return
+	}
+	if templates == nil {
+		templates = []models.PromptTemplate{}
+	}
+
+	c.JSON(http.StatusOK, models.PromptTemplateResponse{
+		Message:   fmt.Sprintf("%d template(s).", len(templates)),
+		Templates: templates,
+		Variables: services.PromptVariables,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// GetTemplate handles GET /api/v1/ai/templates/:task_type?version=2
+// Returns the current template for a task type, or an earlier version to
+// see exactly what a past offload was sent with.
+func (h *AIHandler) GetTemplate(c *gin.Context) {
+	version := 0
+	if raw := c.Query("version"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Invalid version",
+				"Version must be a positive number",
+			))
+			return
+		}
+		version = parsed
+	}
+
+	taskType := templateTaskType(c)
+	template, err := h.db.GetPromptTemplate(taskType, version)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to get prompt template",
+			err.Error(),
+		))
+		return
+	}
+	if template == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Template not found",
+			"No prompt template exists for: "+taskType,
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PromptTemplateResponse{
+		Message:   fmt.Sprintf("Template %s v%d.", template.TaskType, template.Version),
+		Template:  template,
+		Variables: services.PromptVariables,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// ListTemplateVersions handles GET /api/v1/ai/templates/:task_type/versions
+// Returns every saved version of a task type's template, newest first.
+func (h *AIHandler) ListTemplateVersions(c *gin.Context) {
+	taskType := templateTaskType(c)
+	templates, err := h.db.GetPromptTemplateVersions(taskType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list prompt template versions",
+			err.Error(),
+		))
+		return
+	}
+	if len(templates) == 0 {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Template not found",
+			"No prompt template exists for: "+taskType,
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PromptTemplateResponse{
+		Message:   fmt.Sprintf("%d version(s).", len(templates)),
+		Templates: templates,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// PutTemplate handles PUT /api/v1/ai/templates/:task_type
+// Saves a new version of a task type's template, creating the template if
+// the task type has none. Earlier versions are kept, so every offload can be
+// traced to the prompt it was sent with.
+func (h *AIHandler) PutTemplate(c *gin.Context) {
+	var req models.PromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	template := &models.PromptTemplate{
+		ID:           uuid.New().String(),
+		TaskType:     templateTaskType(c),
+		Instructions: req.Instructions,
+		Body:         req.Body,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := h.prompts.ValidateTemplate(template); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+	if err := h.db.CreatePromptTemplateVersion(template); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to save prompt template",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PromptTemplateResponse{
+		Message:   fmt.Sprintf("Template saved: %s v%d.", template.TaskType, template.Version),
+		Template:  template,
+		Timestamp: template.CreatedAt,
+	})
+}
+
+// DeleteTemplate handles DELETE /api/v1/ai/templates/:task_type
+// Removes every version of a task type's template. Offloads of that type
+// are then sent with general instructions and their scope alone; past
+// offloads keep the prompt they were sent with.
+func (h *AIHandler) DeleteTemplate(c *gin.Context) {
+	taskType := templateTaskType(c)
+	affected, err := h.db.DeletePromptTemplate(taskType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to delete prompt template",
+			err.Error(),
+		))
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Template not found",
+			"No prompt template exists for: "+taskType,
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PromptTemplateResponse{
+		Message:   fmt.Sprintf("Template removed: %s (%d version(s)).", taskType, affected),
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// templateTaskType reads the task type path parameter the way templates are
+// stored: trimmed and lower case
+func templateTaskType(c *gin.Context) string {
+	return normalizeTaskType(c.Param("task_type"))
+}
+
+// normalizeTaskType trims and lower-cases a task type, so offloads and
+// templates agree on it whatever case a client sends
+func normalizeTaskType(taskType string) string {
+	return strings.ToLower(strings.TrimSpace(taskType))
+}
+
+// loadOffload fetches the offload named by the :id parameter, writing a 404
+// or 500 response when it cannot be loaded
+func (h *AIHandler) loadOffload(c *gin.Context) (*models.AIOffload, bool) {
+	offload, err := h.db.GetAIOffload(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to find AI offload",
+			err.Error(),
+		))
+		return nil, false
+	}
+	if offload == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Offload not found",
+			"No AI offload exists with the provided ID",
+		))
+		return nil, false
+	}
+	return offload, true
+}
+
+// loadAssistSession fetches the assist session named by the :id parameter,
+// writing a 404 or 500 response when it cannot be loaded
+func (h *AIHandler) loadAssistSession(c *gin.Context) (*models.AssistSession, bool) {
+	session, err := h.assists.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to find assist session",
+			err.Error(),
+		))
+		return nil, false
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Session not found",
+			"No assist session exists with the provided ID",
+		))
+		return nil, false
+	}
+	return session, true
+}
+
+// loadAssistStep fetches the session and step named by the :id and
+// :position parameters, planning the session first if its breakdown has
+// finished. Steps can only be changed while the session is active, so a
+// planning or completed session gets a 409.
+func (h *AIHandler) loadAssistStep(c *gin.Context) (*models.AssistSession, *models.AssistStep, bool) {
+	position, err := strconv.Atoi(c.Param("position"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Invalid step",
+			"Step position must be a number",
+		))
+		return nil, nil, false
+	}
+
+	session, err := h.assists.Plan(c.Param("id"), time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to find assist session",
+			err.Error(),
+		))
+		return nil, nil, false
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Session not found",
+			"No assist session exists with the provided ID",
+		))
+		return nil, nil, false
+	}
+	if session.Status != "active" {
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			"Session not active",
+			"Session is "+session.Status+"; steps can only be changed while it is active",
+		))
+		return nil, nil, false
+	}
+	for i := range session.Steps {
+		if session.Steps[i].Position == position {
+			return session, &session.Steps[i], true
+		}
+	}
+	c.JSON(http.StatusNotFound, models.NewErrorResponse(
+		"Step not found",
+		fmt.Sprintf("Session has no step %d", position),
+	))
+	return nil, nil, false
+}
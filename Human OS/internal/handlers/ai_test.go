@@ -0,0 +1,1043 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/ai"
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// setupAIRouter creates a test router with AI routes, the dashboard and a
+// started offload queue running on provider
+func setupAIRouter(db *database.DB, provider ai.Provider, policy services.OffloadPolicy) (*gin.Engine, *services.OffloadService) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	policy.PollInterval = 10 * time.Millisecond
+	offloads := services.NewOffloadService(db, provider, policy)
+	offloads.Start()
+	assists := services.NewAssistService(db, offloads)
+	handler := NewAIHandler(db, offloads,
+		services.NewPromptService(db),
+		services.NewStateSummaryService(db, provider, 5*time.Second),
+		assists,
+	)
+	cognitive := services.NewCognitiveStateService(
+		db,
+		services.NewRuminationGuard(db, services.RuminationPolicy{}),
+		services.NewEmotionService(db, 4*time.Hour),
+		assists,
+	)
+	focusHandler := NewFocusHandler(db, cognitive, services.NewArchiver(db, false), services.NewLessonService(db))
+
+	router.GET("/api/v1/dashboard/status", focusHandler.GetDashboardStatus)
+	router.POST("/api/v1/ai/offload", handler.Offload)
+	router.POST("/api/v1/ai/assist-for-execution", handler.AssistForExecution)
+	router.GET("/api/v1/ai/assist/sessions", handler.ListAssistSessions)
+	router.GET("/api/v1/ai/assist/sessions/:id", handler.GetAssistSession)
+	router.POST("/api/v1/ai/assist/sessions/:id/plan", handler.PlanAssistSession)
+	router.POST("/api/v1/ai/assist/sessions/:id/steps/:position/done", handler.CompleteAssistStep)
+	router.POST("/api/v1/ai/assist/sessions/:id/steps/:position/stuck", handler.StuckAssistStep)
+	router.POST("/api/v1/ai/summarize-state", handler.SummarizeState)
+	router.GET("/api/v1/ai/offloads", handler.ListOffloads)
+	router.GET("/api/v1/ai/offloads/:id", handler.GetOffload)
+	router.POST("/api/v1/ai/offloads/:id/cancel", handler.CancelOffload)
+	router.POST("/api/v1/ai/offloads/:id/approve", handler.ApproveOffload)
+	router.POST("/api/v1/ai/offloads/:id/edit", handler.EditOffload)
+	router.POST("/api/v1/ai/offloads/:id/reject", handler.RejectOffload)
+	router.GET("/api/v1/ai/reviews", handler.ListReviews)
+	router.GET("/api/v1/ai/templates", handler.ListTemplates)
+	router.GET("/api/v1/ai/templates/:task_type", handler.GetTemplate)
+	router.GET("/api/v1/ai/templates/:task_type/versions", handler.ListTemplateVersions)
+	router.PUT("/api/v1/ai/templates/:task_type", handler.PutTemplate)
+	router.DELETE("/api/v1/ai/templates/:task_type", handler.DeleteTemplate)
+
+	return router, offloads
+}
+
+// aiClient sends requests to an AI test router
+type aiClient struct {
+	t      *testing.T
+	router *gin.Engine
+}
+
+// send performs a request and returns the recorder
+func (a aiClient) send(method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w
+}
+
+// assist starts an assist session
+func (a aiClient) assist(body string) *models.AssistSession {
+	w := a.send("POST", "/api/v1/ai/assist-for-execution", body)
+	if w.Code != http.StatusOK {
+		a.t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp models.AssistSessionResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.Session
+}
+
+// offload queues an offload and returns its ID
+func (a aiClient) offload(path, body string) string {
+	w := a.send("POST", path, body)
+	if w.Code != http.StatusOK {
+		a.t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp models.AIResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.ID
+}
+
+// get fetches an offload through the API
+func (a aiClient) get(id string) *models.AIOffload {
+	w := a.send("GET", "/api/v1/ai/offloads/"+id, "")
+	if w.Code != http.StatusOK {
+		a.t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp models.AIOffloadResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.Offload
+}
+
+// await polls an offload until it reaches status
+func (a aiClient) await(id, status string) *models.AIOffload {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		offload := a.get(id)
+		if offload.Status == status {
+			return offload
+		}
+		if time.Now().After(deadline) {
+			a.t.Fatalf("Offload %s never reached %s: %+v", id, status, offload)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestAIOffload tests that offloads run on the stub provider and can be
+// listed and fetched
+func TestAIOffload(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router, offloads := setupAIRouter(db, ai.NewStubProvider(0), services.OffloadPolicy{})
+	defer offloads.Stop(context.Background())
+	client := aiClient{t, router}
+
+	t.Run("stub is deterministic", func(t *testing.T) {
+		body := `{"task_type": "plan", "scope": "Ship the beta. Fix login; write release notes"}`
+		first := client.await(client.offload("/api/v1/ai/offload", body), "awaiting_review")
+		second := client.await(client.offload("/api/v1/ai/offload", body), "awaiting_review")
+		if first.Provider != "stub" || first.Attempts != 1 || first.CompletedAt == nil {
+			t.Fatalf("Expected completed stub offload, got %+v", first)
+		}
+		if first.Result != second.Result || !strings.Contains(first.Result, "2. Fix login") {
+			t.Errorf("Expected identical plans including the scope's steps, got %q and %q", first.Result, second.Result)
+		}
+	})
+
+	t.Run("assist runs", func(t *testing.T) {
+		session := client.assist(`{"task": "Write the migration", "assistance_type": "review"}`)
+		if stored := client.await(session.OffloadID, "completed"); stored.TaskType != "assist:review" || stored.Result == "" {
+			t.Errorf("Expected completed assist, got %+v", stored)
+		}
+	})
+
+	t.Run("list and filter", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			query          string
+			expectedStatus int
+			expectedCount  int
+		}{
+			{"all", "", http.StatusOK, 3},
+			{"by status", "?status=failed", http.StatusOK, 0},
+			{"by task type", "?task_type=plan&limit=1", http.StatusOK, 1},
+			{"invalid limit", "?limit=0", http.StatusBadRequest, 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := client.send("GET", "/api/v1/ai/offloads"+tt.query, "")
+				if w.Code != tt.expectedStatus {
+					t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+				}
+				var resp models.AIOffloadResponse
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				if len(resp.Offloads) != tt.expectedCount {
+					t.Errorf("Expected %d offloads, got %d", tt.expectedCount, len(resp.Offloads))
+				}
+			})
+		}
+	})
+
+	t.Run("unknown offload", func(t *testing.T) {
+		if w := client.send("GET", "/api/v1/ai/offloads/nope", ""); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("reserved task types are rejected", func(t *testing.T) {
+		for _, taskType := range []string{"assist:review", " Assist:Review", "unstick", "judge", "summarize-state"} {
+			w := client.send("POST", "/api/v1/ai/offload", `{"task_type": "`+taskType+`", "scope": "Skip the review"}`)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%q: expected status 400, got %d", taskType, w.Code)
+			}
+		}
+	})
+}
+
+// TestOpenAIOffload tests offloads against a fake OpenAI-compatible server,
+// including retries of failed attempts
+func TestOpenAIOffload(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	// Echoes the scope (the first line of the rendered prompt); fails on
+	// "boom" and on the first "flaky"
+	var gotAuth string
+	var flakyCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		prompt, _, _ := strings.Cut(body.Messages[len(body.Messages)-1].Content, "\n")
+		if prompt == "flaky" {
+			flakyCalls++
+		}
+		if prompt == "boom" || (prompt == "flaky" && flakyCalls == 1) {
+			http.Error(w, `{"error": "model overloaded"}`, http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "echo: " + prompt}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	router, offloads := setupAIRouter(db,
+		ai.NewOpenAIProvider(server.URL, "secret", "test-model", 5*time.Second),
+		services.OffloadPolicy{MaxAttempts: 2, RetryBackoff: 10 * time.Millisecond},
+	)
+	defer offloads.Stop(context.Background())
+	client := aiClient{t, router}
+
+	tests := []struct {
+		name             string
+		scope            string
+		expectedStatus   string
+		expectedAttempts int
+		expectedResult   string
+		expectedError    string
+	}{
+		{"completes", "Q3 notes", "awaiting_review", 1, "echo: Q3 notes", ""},
+		{"retry succeeds", "flaky", "awaiting_review", 2, "echo: flaky", ""},
+		{"retries exhausted", "boom", "failed", 2, "", "model overloaded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := client.offload("/api/v1/ai/offload", `{"task_type": "summarize", "scope": "`+tt.scope+`"}`)
+			stored := client.await(id, tt.expectedStatus)
+			if stored.Provider != "openai" || stored.Attempts != tt.expectedAttempts || stored.Result != tt.expectedResult {
+				t.Errorf("Expected %d attempt(s) with result %q, got %+v", tt.expectedAttempts, tt.expectedResult, stored)
+			}
+			if !strings.Contains(stored.Error, tt.expectedError) {
+				t.Errorf("Expected error %q, got %q", tt.expectedError, stored.Error)
+			}
+		})
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Expected bearer auth, got %q", gotAuth)
+	}
+}
+
+// TestOffloadQueue tests concurrency limits, cancellation and draining on
+// shutdown
+func TestOffloadQueue(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router, offloads := setupAIRouter(db, ai.NewStubProvider(time.Hour), services.OffloadPolicy{
+		Concurrency:     2,
+		TypeConcurrency: map[string]int{"explore": 1},
+	})
+	client := aiClient{t, router}
+
+	running := client.offload("/api/v1/ai/offload", `{"task_type": "explore", "scope": "Options for the move"}`)
+	client.await(running, "processing")
+	queued := client.offload("/api/v1/ai/offload", `{"task_type": "explore", "scope": "Options for the car"}`)
+	other := client.offload("/api/v1/ai/offload", `{"task_type": "plan", "scope": "Plan the week"}`)
+
+	// explore is limited to one at a time; plan has its own slots
+	client.await(other, "processing")
+	if got := client.get(queued); got.Status != "pending" {
+		t.Fatalf("Expected second explore offload to wait, got %+v", got)
+	}
+
+	t.Run("cancel pending", func(t *testing.T) {
+		if w := client.send("POST", "/api/v1/ai/offloads/"+queued+"/cancel", ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if got := client.get(queued); got.Status != "cancelled" || got.Attempts != 0 {
+			t.Errorf("Expected cancelled before running, got %+v", got)
+		}
+	})
+
+	t.Run("cancel running", func(t *testing.T) {
+		if w := client.send("POST", "/api/v1/ai/offloads/"+running+"/cancel", ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		client.await(running, "cancelled")
+	})
+
+	t.Run("cancel finished", func(t *testing.T) {
+		if w := client.send("POST", "/api/v1/ai/offloads/"+running+"/cancel", ""); w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("drain requeues running offloads", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		offloads.Stop(ctx)
+
+		if got := client.get(other); got.Status != "pending" || got.Attempts != 0 {
+			t.Fatalf("Expected interrupted offload back in the queue, got %+v", got)
+		}
+
+		// The next start picks it up again
+		restarted := services.NewOffloadService(db, ai.NewStubProvider(0), services.OffloadPolicy{PollInterval: 10 * time.Millisecond})
+		restarted.Start()
+		defer restarted.Stop(context.Background())
+		if got := client.await(other, "awaiting_review"); got.Attempts != 1 {
+			t.Errorf("Expected one counted attempt, got %+v", got)
+		}
+	})
+}
+
+// TestOffloadQueueBacklog tests that a backlog larger than a dispatch batch
+// in a group at its limit does not hold back offloads of other groups
+func TestOffloadQueueBacklog(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	earlier := time.Now().UTC().Add(-time.Hour)
+	for i := 0; i < 150; i++ {
+		created := earlier.Add(time.Duration(i) * time.Second)
+		if err := db.CreateAIOffload(&models.AIOffload{
+			ID: fmt.Sprintf("explore-%d", i), TaskType: "explore", Scope: "Options", Status: "pending",
+			CreatedAt: created, UpdatedAt: created,
+		}); err != nil {
+			t.Fatalf("Failed to queue offload: %v", err)
+		}
+	}
+
+	router, _ := setupAIRouter(db, ai.NewStubProvider(time.Hour), services.OffloadPolicy{
+		Concurrency:     2,
+		TypeConcurrency: map[string]int{"explore": 1},
+	})
+	client := aiClient{t, router}
+	client.await("explore-0", "processing")
+
+	plan := client.offload("/api/v1/ai/offload", `{"task_type": "plan", "scope": "Plan the week"}`)
+	client.await(plan, "processing")
+	if got := client.get("explore-1"); got.Status != "pending" {
+		t.Errorf("Expected the explore backlog to wait, got %+v", got)
+	}
+}
+
+// TestQuorumOffload tests quorum offloads under each agreement strategy,
+// with two stub members that always agree and an OpenAI member that does not
+func TestQuorumOffload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "A different answer"}},
+			},
+		})
+	}))
+	defer server.Close()
+	dissenter := "openai:other@" + server.URL
+
+	tests := []struct {
+		name           string
+		members        string
+		strategy       string
+		require        bool
+		body           string
+		expectedCode   int
+		expectedStatus string
+		expectedVotes  int
+		expectedAgrees int
+		expectedError  string
+	}{
+		{"majority reached", "stub, stub, " + dissenter, "majority", false, `{"task_type": "plan", "scope": "Move house", "quorum": true}`, http.StatusOK, "awaiting_review", 3, 2, ""},
+		{"unanimity not reached", "stub, stub, " + dissenter, "unanimity", false, `{"task_type": "plan", "scope": "Move house", "quorum": true}`, http.StatusOK, "failed", 6, 0, "quorum not reached (unanimity): 2 of 3 votes agree"},
+		{"judge agrees", "stub, stub", "judge", false, `{"task_type": "explore", "scope": "New laptop", "quorum": true}`, http.StatusOK, "awaiting_review", 2, 2, ""},
+		{"judge disagrees", "stub, " + dissenter, "judge", false, `{"task_type": "explore", "scope": "New laptop", "quorum": true}`, http.StatusOK, "failed", 4, 0, "judge: the responses differ"},
+		{"required by config", "stub, stub", "majority", true, `{"task_type": "draft", "scope": "Cover letter"}`, http.StatusOK, "awaiting_review", 2, 2, ""},
+		{"not configured", "stub", "majority", false, `{"task_type": "plan", "scope": "Move house", "quorum": true}`, http.StatusBadRequest, "", 0, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, cleanup := testDB(t)
+			defer cleanup()
+
+			quorum, err := ai.NewQuorum(tt.members, tt.strategy, "", ai.Config{})
+			if err != nil {
+				t.Fatalf("Failed to build quorum: %v", err)
+			}
+			router, offloads := setupAIRouter(db, ai.NewStubProvider(0), services.OffloadPolicy{
+				MaxAttempts:   2,
+				RetryBackoff:  10 * time.Millisecond,
+				Quorum:        quorum,
+				RequireQuorum: tt.require,
+			})
+			defer offloads.Stop(context.Background())
+			client := aiClient{t, router}
+
+			w := client.send("POST", "/api/v1/ai/offload", tt.body)
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			var resp models.AIResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+
+			stored := client.await(resp.ID, tt.expectedStatus)
+			if !stored.Quorum || stored.Strategy != tt.strategy || stored.Provider != "quorum" {
+				t.Errorf("Expected a %s quorum offload, got %+v", tt.strategy, stored)
+			}
+			if !strings.Contains(stored.Error, tt.expectedError) {
+				t.Errorf("Expected error %q, got %q", tt.expectedError, stored.Error)
+			}
+			if tt.expectedStatus == "awaiting_review" && !strings.HasPrefix(stored.Result, "[stub ") {
+				t.Errorf("Expected the agreed stub output, got %q", stored.Result)
+			}
+
+			agrees := 0
+			for _, vote := range stored.Votes {
+				if vote.Agrees {
+					agrees++
+				}
+			}
+			if len(stored.Votes) != tt.expectedVotes || agrees != tt.expectedAgrees {
+				t.Errorf("Expected %d votes with %d agreeing, got %+v", tt.expectedVotes, tt.expectedAgrees, stored.Votes)
+			}
+		})
+	}
+}
+
+// TestOffloadReview tests that offload results wait for review and can be
+// approved, edited or rejected with feedback, leaving an audit trail
+func TestOffloadReview(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router, offloads := setupAIRouter(db, ai.NewStubProvider(0), services.OffloadPolicy{})
+	defer offloads.Stop(context.Background())
+	client := aiClient{t, router}
+
+	queue := func() *models.AIOffload {
+		return client.await(client.offload("/api/v1/ai/offload", `{"task_type": "plan", "scope": "Tax return"}`), "awaiting_review")
+	}
+
+	t.Run("approve", func(t *testing.T) {
+		offload := queue()
+		w := client.send("POST", "/api/v1/ai/offloads/"+offload.ID+"/approve", `{"reviewer": "sam"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		stored := client.get(offload.ID)
+		if stored.Status != "completed" || stored.Result != offload.Result || len(stored.Reviews) != 1 {
+			t.Fatalf("Expected completed offload with one review, got %+v", stored)
+		}
+		if review := stored.Reviews[0]; review.Reviewer != "sam" || review.Action != "approved" || review.AcceptedResult != offload.Result {
+			t.Errorf("Expected sam's approval of the AI result, got %+v", review)
+		}
+
+		if w := client.send("POST", "/api/v1/ai/offloads/"+offload.ID+"/approve", `{"reviewer": "sam"}`); w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 approving twice, got %d", w.Code)
+		}
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		offload := queue()
+		w := client.send("POST", "/api/v1/ai/offloads/"+offload.ID+"/edit", `{"reviewer": "sam", "result": "1. File by April"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		stored := client.get(offload.ID)
+		if stored.Status != "completed" || stored.Result != "1. File by April" {
+			t.Fatalf("Expected the edited result accepted, got %+v", stored)
+		}
+		if review := stored.Reviews[0]; review.AIResult != offload.Result || review.AcceptedResult != "1. File by April" {
+			t.Errorf("Expected both AI and accepted results audited, got %+v", review)
+		}
+	})
+
+	t.Run("reject requeues with feedback", func(t *testing.T) {
+		offload := queue()
+		w := client.send("POST", "/api/v1/ai/offloads/"+offload.ID+"/reject", `{"reviewer": "sam", "feedback": "Include the receipts"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		revised := client.await(offload.ID, "awaiting_review")
+		if revised.Feedback != "Include the receipts" || revised.Attempts != 1 {
+			t.Fatalf("Expected a fresh attempt with the feedback, got %+v", revised)
+		}
+		if revised.Result == offload.Result || !strings.Contains(revised.Result, "Include the receipts") {
+			t.Errorf("Expected a revised result using the feedback, got %q", revised.Result)
+		}
+		if len(revised.Reviews) != 1 || revised.Reviews[0].Action != "rejected" || revised.Reviews[0].AcceptedResult != "" {
+			t.Errorf("Expected the rejection audited, got %+v", revised.Reviews)
+		}
+	})
+
+	t.Run("invalid reviews", func(t *testing.T) {
+		offload := queue()
+		tests := []struct {
+			name   string
+			action string
+			body   string
+		}{
+			{"missing reviewer", "approve", `{}`},
+			{"reject without feedback", "reject", `{"reviewer": "sam"}`},
+			{"edit without result", "edit", `{"reviewer": "sam"}`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if w := client.send("POST", "/api/v1/ai/offloads/"+offload.ID+"/"+tt.action, tt.body); w.Code != http.StatusBadRequest {
+					t.Errorf("Expected status 400, got %d", w.Code)
+				}
+			})
+		}
+	})
+
+	t.Run("assist skips review", func(t *testing.T) {
+		offload := client.await(client.assist(`{"task": "Tax return", "assistance_type": "checklist"}`).OffloadID, "completed")
+		if !offload.SkipReview || queue().SkipReview {
+			t.Errorf("Expected only the assist request to skip review, got %+v", offload)
+		}
+	})
+
+	t.Run("audit trail", func(t *testing.T) {
+		w := client.send("GET", "/api/v1/ai/reviews?reviewer=sam", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp models.AIOffloadReviewResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Reviews) != 3 || resp.Reviews[0].Action != "rejected" {
+			t.Errorf("Expected 3 reviews, newest first, got %+v", resp.Reviews)
+		}
+	})
+}
+
+// TestPromptTemplates tests template versioning, validation and the context
+// rendered into offload prompts
+func TestPromptTemplates(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	now := time.Now().UTC()
+	db.SetFocus(&models.FocusState{
+		ID: "focus-1", TaskName: "Quarterly report", SuccessCriteria: "Sent to finance",
+		Duration: "90m", Status: "active", StartedAt: now, CreatedAt: now, UpdatedAt: now,
+	})
+	for _, loop := range []models.Loop{
+		{ID: "loop-1", Description: "Chase the report numbers from sales", Priority: models.PriorityHigh},
+		{ID: "loop-2", Description: "Book the dentist", Priority: models.PriorityLow},
+	} {
+		loop.Queue, loop.Owner, loop.Status, loop.CreatedAt, loop.UpdatedAt = models.QueueAction, "me", "open", now, now
+		db.CreateLoop(&loop)
+	}
+	db.ImportTelosItems([]models.TelosItem{{ID: "G1", Section: "GOALS", Text: "Get promoted by December"}}, now)
+
+	router, offloads := setupAIRouter(db, ai.NewStubProvider(0), services.OffloadPolicy{})
+	defer offloads.Stop(context.Background())
+	client := aiClient{t, router}
+
+	t.Run("defaults are installed", func(t *testing.T) {
+		w := client.send("GET", "/api/v1/ai/templates", "")
+		var resp models.PromptTemplateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || len(resp.Templates) != 5 || resp.Templates[0].Version != 1 || len(resp.Variables) == 0 {
+			t.Fatalf("Expected 5 version-1 templates and their variables, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("prompt includes linked context", func(t *testing.T) {
+		id := client.offload("/api/v1/ai/offload", `{"task_type": "summarize", "scope": "Draft the quarterly report"}`)
+		offload := client.get(id)
+		if offload.TemplateVersion != 1 || !strings.HasPrefix(offload.Instructions, "Compress") {
+			t.Fatalf("Expected summarize template v1, got %+v", offload)
+		}
+		for _, want := range []string{"Draft the quarterly report", "Quarterly report (done when: Sent to finance)", "Chase the report numbers", "G1: Get promoted"} {
+			if !strings.Contains(offload.Prompt, want) {
+				t.Errorf("Expected prompt to contain %q, got %q", want, offload.Prompt)
+			}
+		}
+		if strings.Contains(offload.Prompt, "dentist") {
+			t.Errorf("Expected unrelated loops left out, got %q", offload.Prompt)
+		}
+	})
+
+	t.Run("saving creates a new version", func(t *testing.T) {
+		w := client.send("PUT", "/api/v1/ai/templates/plan", `{"instructions": "Three steps at most.", "body": "Plan {{ scope }} for {{goals}}"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		for _, taskType := range []string{"plan", " Plan "} {
+			offload := client.get(client.offload("/api/v1/ai/offload", `{"task_type": "`+taskType+`", "scope": "the offsite"}`))
+			if offload.TaskType != "plan" || offload.TemplateVersion != 2 || offload.Prompt != "Plan the offsite for - G1: Get promoted by December" {
+				t.Errorf("%q: expected v2 plan prompt, got %s version %d and %q", taskType, offload.TaskType, offload.TemplateVersion, offload.Prompt)
+			}
+		}
+		if w := client.send("POST", "/api/v1/ai/offload", `{"task_type": "  ", "scope": "the offsite"}`); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for a blank task type, got %d", w.Code)
+		}
+
+		var resp models.PromptTemplateResponse
+		json.Unmarshal(client.send("GET", "/api/v1/ai/templates/plan?version=1", "").Body.Bytes(), &resp)
+		if resp.Template == nil || resp.Template.Version != 1 || !strings.HasPrefix(resp.Template.Instructions, "Produce") {
+			t.Errorf("Expected version 1 kept, got %+v", resp.Template)
+		}
+		json.Unmarshal(client.send("GET", "/api/v1/ai/templates/Plan/versions", "").Body.Bytes(), &resp)
+		if len(resp.Templates) != 2 || resp.Templates[0].Version != 2 {
+			t.Errorf("Expected 2 versions newest first, got %+v", resp.Templates)
+		}
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			taskType string
+			body     string
+		}{
+			{"missing scope", "draft", `{"instructions": "x", "body": "Write it"}`},
+			{"unknown variable", "draft", `{"instructions": "x", "body": "{{scope}} {{mood}}"}`},
+			{"assist type", "assist:review", `{"instructions": "x", "body": "{{scope}}"}`},
+			{"missing instructions", "draft", `{"body": "{{scope}}"}`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if w := client.send("PUT", "/api/v1/ai/templates/"+tt.taskType, tt.body); w.Code != http.StatusBadRequest {
+					t.Errorf("Expected status 400, got %d", w.Code)
+				}
+			})
+		}
+	})
+
+	t.Run("delete falls back to the scope", func(t *testing.T) {
+		if w := client.send("DELETE", "/api/v1/ai/templates/Explore", ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := client.send("GET", "/api/v1/ai/templates/explore", ""); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+		offload := client.get(client.offload("/api/v1/ai/offload", `{"task_type": "explore", "scope": "Options for the car"}`))
+		if offload.TemplateVersion != 0 || offload.Prompt != "Options for the car" {
+			t.Errorf("Expected the untemplated scope, got version %d and %q", offload.TemplateVersion, offload.Prompt)
+		}
+	})
+}
+
+// TestPromptTemplatesStayDeleted tests that the default templates are only
+// installed with a new database, so deleting them survives a restart
+func TestPromptTemplatesStayDeleted(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "humanos_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	path := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(path)
+
+	db, err := database.New(path)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	templates, _ := db.GetPromptTemplates()
+	if len(templates) != 5 {
+		t.Fatalf("Expected the 5 default templates, got %d", len(templates))
+	}
+	for _, template := range templates {
+		if _, err := db.DeletePromptTemplate(template.TaskType); err != nil {
+			t.Fatalf("Failed to delete template: %v", err)
+		}
+	}
+	db.Close()
+
+	db, err = database.New(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+	if templates, _ := db.GetPromptTemplates(); len(templates) != 0 {
+		t.Errorf("Expected deleted templates to stay deleted, got %d", len(templates))
+	}
+}
+
+// TestSummarizeState tests the state briefing on the stub's rules and on a
+// provider whose replies need checking
+func TestSummarizeState(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router, offloads := setupAIRouter(db, ai.NewStubProvider(0), services.OffloadPolicy{})
+	defer offloads.Stop(context.Background())
+	client := aiClient{t, router}
+
+	summarize := func(client aiClient) models.StateSummaryResponse {
+		w := client.send("POST", "/api/v1/ai/summarize-state", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp models.StateSummaryResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	t.Run("nothing open", func(t *testing.T) {
+		resp := summarize(client)
+		if !strings.Contains(resp.Briefing.Summary, "Nothing is waiting on you") || len(resp.Briefing.Priorities) != 0 {
+			t.Errorf("Expected an empty briefing, got %+v", resp.Briefing)
+		}
+	})
+
+	daysAgo := func(days int) time.Time {
+		return time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour)
+	}
+	for _, loop := range []models.Loop{
+		{ID: "grant", Description: "Finish the grant application", Priority: models.PriorityHigh, Queue: models.QueueAction, CreatedAt: daysAgo(2)},
+		{ID: "rust", Description: "Learn Rust", Priority: models.PriorityMedium, Queue: models.QueueBackburner, CreatedAt: daysAgo(20)},
+		{ID: "tax", Description: "Notes on tax rules", Priority: models.PriorityLow, Queue: models.QueueReference, CreatedAt: daysAgo(10)},
+	} {
+		loop.Owner, loop.Status, loop.UpdatedAt = "me", "open", loop.CreatedAt
+		db.CreateLoop(&loop)
+	}
+	for _, task := range []models.Task{
+		{ID: "invoice", Description: "Pay the invoice", Urgency: models.PriorityHigh, Importance: models.PriorityHigh, CreatedAt: daysAgo(1)},
+		{ID: "garage", Description: "Sort the garage", Urgency: models.PriorityLow, Importance: models.PriorityLow, CreatedAt: daysAgo(20)},
+	} {
+		task.Category, task.Status, task.UpdatedAt = "home", "pending", task.CreatedAt
+		db.CreateTask(&task)
+	}
+	db.CreateIdea(&models.Idea{ID: "blog", Summary: "Blog post on habits", Storage: "notes", ActionNow: true, Status: "captured", CreatedAt: daysAgo(0), UpdatedAt: daysAgo(0)})
+	db.CreateIdea(&models.Idea{ID: "podcast", Summary: "Start a podcast", Storage: "notes", Status: "captured", CreatedAt: daysAgo(40), UpdatedAt: daysAgo(40)})
+	db.CreateThread(&models.Thread{ID: "jobs", Name: "Job search", Mode: models.ThreadModeBackground, TimeScope: "month", Status: "active", CreatedAt: daysAgo(10), UpdatedAt: daysAgo(10)})
+	db.CreateThread(&models.Thread{ID: "chapter", Name: "Write chapter 3", Mode: models.ThreadModeForeground, TimeScope: "today", Status: "active", CreatedAt: daysAgo(0), UpdatedAt: daysAgo(0)})
+
+	t.Run("stub briefing", func(t *testing.T) {
+		resp := summarize(client)
+		if resp.Provider != "stub" || resp.Counts["loop"] != 3 || resp.Counts["task"] != 2 || resp.Counts["idea"] != 2 || resp.Counts["thread"] != 2 {
+			t.Fatalf("Expected every open item counted, got %+v", resp.Counts)
+		}
+
+		var order []string
+		for _, p := range resp.Briefing.Priorities {
+			order = append(order, p.ID)
+		}
+		if got := strings.Join(order, ","); got != "grant,invoice,blog,chapter" {
+			t.Errorf("Expected priorities grant,invoice,blog,chapter, got %s", got)
+		}
+		if !strings.Contains(resp.Briefing.Summary, "Start with: Finish the grant application") {
+			t.Errorf("Expected summary to lead with the top priority, got %q", resp.Briefing.Summary)
+		}
+
+		closures := make(map[string]string)
+		for _, c := range resp.Briefing.Closures {
+			closures[c.ID] = c.Action
+		}
+		expected := map[string]string{"rust": "kill", "tax": "archive", "garage": "drop", "podcast": "archive", "jobs": "terminate"}
+		if len(closures) != len(expected) {
+			t.Errorf("Expected closures %v, got %v", expected, closures)
+		}
+		for id, action := range expected {
+			if closures[id] != action {
+				t.Errorf("Expected %s to be suggested for %s, got %q", action, id, closures[id])
+			}
+		}
+
+		if again := summarize(client); again.Briefing.Summary != resp.Briefing.Summary || len(again.Briefing.Closures) != len(resp.Briefing.Closures) {
+			t.Errorf("Expected the stub briefing to be deterministic")
+		}
+	})
+
+	t.Run("provider replies are checked", func(t *testing.T) {
+		tests := []struct {
+			name               string
+			reply              string
+			expectedPriorities int
+			expectedMessage    string
+		}{
+			{"unknown items dropped", "```json\n{\"summary\": \"Busy week.\", \"priorities\": [{\"id\": \"invoice\", \"reason\": \"due\"}, {\"id\": \"made-up\", \"reason\": \"?\"}]}\n```", 1, "1 priority item(s)"},
+			{"prose kept as summary", "Focus on the invoice first.", 0, "not a structured briefing"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"choices": []map[string]interface{}{
+							{"message": map[string]string{"role": "assistant", "content": tt.reply}},
+						},
+					})
+				}))
+				defer server.Close()
+
+				router, offloads := setupAIRouter(db, ai.NewOpenAIProvider(server.URL, "", "test-model", 5*time.Second), services.OffloadPolicy{})
+				defer offloads.Stop(context.Background())
+
+				resp := summarize(aiClient{t, router})
+				if len(resp.Briefing.Priorities) != tt.expectedPriorities || !strings.Contains(resp.Message, tt.expectedMessage) {
+					t.Errorf("Expected %d priorities and message %q, got %+v", tt.expectedPriorities, tt.expectedMessage, resp)
+				}
+				if tt.expectedPriorities > 0 && resp.Briefing.Priorities[0].Text != "Pay the invoice" {
+					t.Errorf("Expected item text filled from the package, got %+v", resp.Briefing.Priorities[0])
+				}
+			})
+		}
+	})
+}
+
+// TestAssistSessions tests breaking assist tasks into steps, checking them
+// off, asking for help with stuck ones and the progress on the dashboard
+func TestAssistSessions(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	now := time.Now().UTC()
+	db.SetFocus(&models.FocusState{
+		ID: "focus-1", TaskName: "Beta launch", SuccessCriteria: "Beta is live",
+		Duration: "90m", Status: "active", StartedAt: now, CreatedAt: now, UpdatedAt: now,
+	})
+
+	router, offloads := setupAIRouter(db, ai.NewStubProvider(0), services.OffloadPolicy{})
+	defer offloads.Stop(context.Background())
+	client := aiClient{t, router}
+
+	getSession := func(id string) *models.AssistSession {
+		w := client.send("GET", "/api/v1/ai/assist/sessions/"+id, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp models.AssistSessionResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.Session
+	}
+	dashboard := func() models.CognitiveStatus {
+		var status models.CognitiveStatus
+		json.Unmarshal(client.send("GET", "/api/v1/dashboard/status", "").Body.Bytes(), &status)
+		return status
+	}
+
+	t.Run("template steps are ready at once", func(t *testing.T) {
+		session := client.assist(`{"task": "Expense report", "assistance_type": "checklist", "template": true}`)
+		if session.Status != "active" || session.Source != "template" || session.OffloadID != "" {
+			t.Fatalf("Expected an active template session, got %+v", session)
+		}
+		if len(session.Steps) != 5 || session.Steps[0].Description != "Get clear on what done looks like for: Expense report" {
+			t.Errorf("Expected the 5 template steps, got %+v", session.Steps)
+		}
+		if session.FocusID != "focus-1" {
+			t.Errorf("Expected the session linked to the current focus, got %q", session.FocusID)
+		}
+	})
+
+	var session *models.AssistSession
+	t.Run("AI breaks the task into steps", func(t *testing.T) {
+		started := client.assist(`{"task": "Ship the beta. Fix login; write release notes", "assistance_type": "sequencing"}`)
+		if started.Status != "planning" || started.OffloadID == "" || len(started.Steps) != 0 {
+			t.Fatalf("Expected a planning session with a breakdown request, got %+v", started)
+		}
+		client.await(started.OffloadID, "completed")
+
+		// The session takes its steps as soon as the breakdown finishes
+		deadline := time.Now().Add(5 * time.Second)
+		session = getSession(started.ID)
+		for session.Status == "planning" && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+			session = getSession(started.ID)
+		}
+		if session.Status != "active" || session.Source != "ai" {
+			t.Fatalf("Expected an active AI session, got %+v", session)
+		}
+		if len(session.Steps) != 5 || session.Steps[1].Description != "Fix login" || session.Steps[1].Position != 2 {
+			t.Errorf("Expected the stub's steps in order, got %+v", session.Steps)
+		}
+	})
+
+	t.Run("dashboard shows the newest session on the focus", func(t *testing.T) {
+		progress := dashboard().Assist
+		if progress == nil || progress.SessionID != session.ID || progress.StepsTotal != 5 || progress.StepsDone != 0 {
+			t.Fatalf("Expected the AI session's progress, got %+v", progress)
+		}
+		if progress.NextStep != session.Steps[0].Description {
+			t.Errorf("Expected the first step next, got %q", progress.NextStep)
+		}
+	})
+
+	base := "/api/v1/ai/assist/sessions/"
+	t.Run("check off a step", func(t *testing.T) {
+		if w := client.send("POST", base+session.ID+"/steps/1/done", ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := client.send("POST", base+session.ID+"/steps/1/done", ""); w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 for a step already done, got %d", w.Code)
+		}
+		if progress := dashboard().Assist; progress.StepsDone != 1 || progress.NextStep != "Fix login" {
+			t.Errorf("Expected 1 step done with Fix login next, got %+v", progress)
+		}
+	})
+
+	t.Run("stuck step gets help", func(t *testing.T) {
+		w := client.send("POST", base+session.ID+"/steps/2/stuck", `{"note": "The auth service keeps timing out"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp models.AssistSessionResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		step := resp.Session.Steps[1]
+		if step.Status != "stuck" || step.StuckOn != "The auth service keeps timing out" || step.HelpOffloadID == "" {
+			t.Fatalf("Expected a stuck step with a help request, got %+v", step)
+		}
+
+		help := client.await(step.HelpOffloadID, "completed")
+		if help.TaskType != "unstick" || !strings.Contains(help.Scope, "The auth service keeps timing out") {
+			t.Errorf("Expected an unstick request with the note, got %+v", help)
+		}
+		step = getSession(session.ID).Steps[1]
+		if step.HelpStatus != "completed" || !strings.Contains(step.Help, "Stuck on step 2 of 5: Fix login") {
+			t.Errorf("Expected the help on the step, got %+v", step)
+		}
+		if progress := dashboard().Assist; progress.StuckSteps != 1 {
+			t.Errorf("Expected 1 stuck step on the dashboard, got %+v", progress)
+		}
+	})
+
+	t.Run("invalid step requests", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			path           string
+			expectedStatus int
+		}{
+			{"unknown session", base + "missing/steps/1/done", http.StatusNotFound},
+			{"unknown step", base + session.ID + "/steps/9/done", http.StatusNotFound},
+			{"non-numeric step", base + session.ID + "/steps/first/done", http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if w := client.send("POST", tt.path, ""); w.Code != tt.expectedStatus {
+					t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+				}
+			})
+		}
+	})
+
+	t.Run("last step completes the session", func(t *testing.T) {
+		for _, position := range []string{"2", "3", "4", "5"} {
+			if w := client.send("POST", base+session.ID+"/steps/"+position+"/done", ""); w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+		}
+		done := getSession(session.ID)
+		if done.Status != "completed" || done.StepsDone != 5 || done.CompletedAt == nil {
+			t.Fatalf("Expected a completed session, got %+v", done)
+		}
+		helpRequests := func() int {
+			var resp models.AIOffloadResponse
+			json.Unmarshal(client.send("GET", "/api/v1/ai/offloads?task_type=unstick", "").Body.Bytes(), &resp)
+			return len(resp.Offloads)
+		}
+		before := helpRequests()
+		if w := client.send("POST", base+session.ID+"/steps/5/stuck", ""); w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 for a completed session, got %d", w.Code)
+		}
+		if after := helpRequests(); after != before {
+			t.Errorf("Expected no help request queued for a completed session, got %d more", after-before)
+		}
+
+		// The template session is now the focus's unfinished one
+		if progress := dashboard().Assist; progress == nil || progress.SessionID == session.ID {
+			t.Errorf("Expected the completed session off the dashboard, got %+v", progress)
+		}
+	})
+
+	t.Run("list by status", func(t *testing.T) {
+		w := client.send("GET", "/api/v1/ai/assist/sessions?status=completed", "")
+		var resp models.AssistSessionResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Sessions) != 1 || resp.Sessions[0].ID != session.ID {
+			t.Errorf("Expected the completed session, got %+v", resp.Sessions)
+		}
+	})
+
+	t.Run("cancelled breakdown falls back to the template", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+
+		router, offloads := setupAIRouter(db, ai.NewStubProvider(time.Minute), services.OffloadPolicy{})
+		defer offloads.Stop(context.Background())
+		client := aiClient{t, router}
+
+		started := client.assist(`{"task": "Quarterly taxes", "assistance_type": "checklist"}`)
+		client.await(started.OffloadID, "processing")
+		if w := client.send("POST", "/api/v1/ai/offloads/"+started.OffloadID+"/cancel", ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		client.await(started.OffloadID, "cancelled")
+
+		w := client.send("POST", "/api/v1/ai/assist/sessions/"+started.ID+"/plan", "")
+		var resp models.AssistSessionResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Session.Status != "active" || resp.Session.Source != "template" || len(resp.Session.Steps) != 5 {
+			t.Fatalf("Expected template steps after the breakdown was cancelled, got %+v", resp.Session)
+		}
+
+		// Asking for help again cancels the request still running
+		stuck := func() string {
+			w := client.send("POST", "/api/v1/ai/assist/sessions/"+started.ID+"/steps/1/stuck", "")
+			var resp models.AssistSessionResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			return resp.Session.Steps[0].HelpOffloadID
+		}
+		first := stuck()
+		client.await(first, "processing")
+		second := stuck()
+		client.await(first, "cancelled")
+		if second == first || client.get(second).Status == "cancelled" {
+			t.Errorf("Expected a new help request to replace the first, got %s", second)
+		}
+		client.send("POST", "/api/v1/ai/offloads/"+second+"/cancel", "")
+	})
+}
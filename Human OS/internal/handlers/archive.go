@@ -6,6 +6,8 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -64,3 +66,129 @@ func (h *ArchiveHandler) CommitArchive(c *gin.Context) {
 		Timestamp: now,
 	})
 }
+
+// SearchArchives handles GET /api/v1/archive/search?q=
+// Archives are only valuable if you can find them again. Search looks across
+// objects, summaries and lessons so past lessons surface when starting similar
+// work. Optional limit caps the number of results (default 20, max 100).
+func (h *ArchiveHandler) SearchArchives(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Missing search query",
+			"Provide the search terms in the 'q' query parameter",
+		))
+		return
+	}
+
+	limit := 20
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 100 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Invalid limit",
+				"Limit must be a number between 1 and 100",
+			))
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.db.SearchArchives(query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to search archives",
+			err.Error(),
+		))
+		return
+	}
+	if results == nil {
+		results = []models.ArchiveSearchResult{}
+	}
+
+	c.JSON(http.StatusOK, models.ArchiveSearchResponse{
+		Query:     query,
+		Count:     len(results),
+		Results:   results,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// GetArchive handles GET /api/v1/archive/:id
+// Reads back a single committed archive entry, including its lesson.
+func (h *ArchiveHandler) GetArchive(c *gin.Context) {
+	archive, err := h.db.GetArchive(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to get archive",
+			err.Error(),
+		))
+		return
+	}
+	if archive == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Archive not found",
+			"No archive exists with the provided ID",
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, archive)
+}
+
+// RecordLesson handles POST /api/v1/archive/:id/lesson
+// Automatic archives capture what was finished but not what was learned.
+// This follow-up step attaches the lesson once there's been time to reflect.
+func (h *ArchiveHandler) RecordLesson(c *gin.Context) {
+	var req models.ArchiveLessonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	archiveID := c.Param("id")
+	affected, err := h.db.UpdateArchiveLesson(archiveID, req.Lesson)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to record lesson",
+			err.Error(),
+		))
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Archive not found",
+			"No archive exists with the provided ID",
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ArchiveResponse{
+		Message:   "Lesson captured for future reference.",
+		ArchiveID: archiveID,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// PendingLessons handles GET /api/v1/archive/pending-lessons
+// Lists automatically archived work that is still waiting for a lesson.
+func (h *ArchiveHandler) PendingLessons(c *gin.Context) {
+	archives, err := h.db.GetArchivesAwaitingLesson(50)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list pending lessons",
+			err.Error(),
+		))
+		return
+	}
+	if archives == nil {
+		archives = []models.Archive{}
+	}
+
+	c.JSON(http.StatusOK, models.ArchiveListResponse{
+		Message:   "Finished work waiting for a lesson.",
+		Count:     len(archives),
+		Archives:  archives,
+		Timestamp: time.Now().UTC(),
+	})
+}
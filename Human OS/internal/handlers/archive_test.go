@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// setupArchiveRouter creates a test router with archive handlers
+func setupArchiveRouter(db *database.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	handler := NewArchiveHandler(db)
+
+	archive := router.Group("/api/v1/archive")
+	{
+		archive.POST("/commit", handler.CommitArchive)
+		archive.GET("/search", handler.SearchArchives)
+		archive.GET("/:id", handler.GetArchive)
+	}
+
+	return router
+}
+
+// TestArchiveSearch tests committing archives and reading them back via search
+func TestArchiveSearch(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupArchiveRouter(db)
+
+	commits := []models.ArchiveCommitRequest{
+		{Object: "Hail siding claim", Summary: "Contractor visit done", Lesson: "Document everything in writing"},
+		{Object: "Quarterly report", Summary: "Shipped Q3 analysis", Lesson: "Start data gathering earlier"},
+		{Object: "Basement claim paperwork", Summary: "Sent to adjuster"},
+	}
+	var firstID string
+	for _, commit := range commits {
+		body, _ := json.Marshal(commit)
+		req, _ := http.NewRequest("POST", "/api/v1/archive/commit", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Failed to commit archive: %s", w.Body.String())
+		}
+		if firstID == "" {
+			var resp models.ArchiveResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			firstID = resp.ArchiveID
+		}
+	}
+
+	t.Run("search matches across fields", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/archive/search?q=claim", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+		}
+		var resp models.ArchiveSearchResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Count != 2 {
+			t.Errorf("Expected 2 results for 'claim', got %d", resp.Count)
+		}
+		for _, r := range resp.Results {
+			if r.Snippet == "" {
+				t.Error("Expected snippet on every result")
+			}
+		}
+	})
+
+	t.Run("all terms must match", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/archive/search?q=claim+writing", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp models.ArchiveSearchResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Count != 1 || resp.Results[0].Archive.Object != "Hail siding claim" {
+			t.Errorf("Expected only 'Hail siding claim', got %+v", resp.Results)
+		}
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/archive/search", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("get by id", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/archive/"+firstID, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var archive models.Archive
+		json.Unmarshal(w.Body.Bytes(), &archive)
+		if archive.Lesson != "Document everything in writing" {
+			t.Errorf("Expected lesson to round-trip, got '%s'", archive.Lesson)
+		}
+	})
+
+	t.Run("get unknown id", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/archive/does-not-exist", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
+
+// TestArchiveSearchIndexTriggers tests that a database file carrying the
+// full-text index triggers, as left by a build with FTS5, still accepts and
+// finds archives whichever build opens it next
+func TestArchiveSearchIndexTriggers(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "humanos_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	path := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(path)
+
+	db, err := database.New(path)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	db.Close()
+
+	// Without FTS5 compiled in, recreate the triggers an FTS5 build leaves
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if _, err := conn.Exec(`
+		CREATE TRIGGER IF NOT EXISTS archives_fts_insert AFTER INSERT ON archives BEGIN
+			INSERT INTO archives_fts (archive_id, object, summary, lesson)
+			VALUES (new.id, new.object, new.summary, COALESCE(new.lesson, ''));
+		END;
+	`); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	conn.Close()
+
+	db, err = database.New(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+	router := setupArchiveRouter(db)
+
+	body, _ := json.Marshal(models.ArchiveCommitRequest{Object: "Roof claim", Summary: "Adjuster booked"})
+	req, _ := http.NewRequest("POST", "/api/v1/archive/commit", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected the archive committed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/archive/search?q=roof", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var resp models.ArchiveSearchResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Count != 1 {
+		t.Errorf("Expected the archive found, got %s", w.Body.String())
+	}
+}
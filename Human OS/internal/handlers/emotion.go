@@ -5,7 +5,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,18 +23,26 @@ import (
 
 // EmotionHandler handles emotion-related endpoints
 type EmotionHandler struct {
-	db *database.DB
+	db              *database.DB
+	emotions        *services.EmotionService
+	triggers        *services.TriggerService
+	recommendations *services.RecommendationService
 }
 
 // NewEmotionHandler creates a new emotion handler
-func NewEmotionHandler(db *database.DB) *EmotionHandler {
-	return &EmotionHandler{db: db}
+func NewEmotionHandler(db *database.DB, emotions *services.EmotionService, triggers *services.TriggerService, recommendations *services.RecommendationService) *EmotionHandler {
+	return &EmotionHandler{db: db, emotions: emotions, triggers: triggers, recommendations: recommendations}
 }
 
 // TagEmotion handles POST /api/v1/emotion/tag
 // Tagging an emotion is a mindfulness exercise - naming what you're feeling
 // and hypothesizing about its source. This creates distance and awareness,
-// which is the first step in emotional regulation.
+// which is the first step in emotional regulation. Synonyms are tagged as
+// their taxonomy label, and an optional 1-10 intensity scales emotional load.
+// The source guess is broken down into named triggers (people, projects,
+// contexts), which are returned so you can see what was recognized. The
+// response suggests a next action based on the whole current state, weighted
+// towards what this emotion usually calls for.
 func (h *EmotionHandler) TagEmotion(c *gin.Context) {
 	var req models.EmotionTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -37,11 +50,21 @@ func (h *EmotionHandler) TagEmotion(c *gin.Context) {
 		return
 	}
 
+	label, _, err := h.emotions.Resolve(req.Label)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to resolve emotion",
+			err.Error(),
+		))
+		return
+	}
+
 	now := time.Now().UTC()
 	state := &models.EmotionalState{
 		ID:          uuid.New().String(),
-		Label:       req.Label,
+		Label:       label,
 		SourceGuess: req.SourceGuess,
+		Intensity:   req.Intensity,
 		CreatedAt:   now,
 	}
 
@@ -53,34 +76,40 @@ func (h *EmotionHandler) TagEmotion(c *gin.Context) {
 		return
 	}
 
-	// Provide contextual feedback based on the emotion
-	var advice string
-	switch req.Label {
-	case "angry", "frustrated":
-		advice = "Acknowledgment is the first step. Consider what boundary was crossed."
-	case "anxious", "worried":
-		advice = "Noted. Is this about something in your control? If not, consider letting go."
-	case "tired":
-		advice = "Energy is finite. Prioritize rest when possible."
-	case "overwhelmed":
-		advice = "Too many open loops? Consider a reset or loop cleanup."
-	case "resentful":
-		advice = "Resentment often signals unmet needs. Worth examining."
-	default:
-		advice = "Emotion logged. Self-awareness is valuable."
-	}
-
-	c.JSON(http.StatusOK, models.EmotionResponse{
-		Message:   "Emotional state tagged: " + req.Label + ". Possible source: " + req.SourceGuess + ". " + advice,
+	resp := models.EmotionResponse{
+		Message:   "Emotional state tagged: " + label + ". Possible source: " + req.SourceGuess + ".",
 		ID:        state.ID,
 		Timestamp: now,
-	})
+	}
+
+	triggers, err := h.triggers.TagState(state)
+	if err != nil {
+		resp.Message += " Trigger detection failed: " + err.Error()
+	}
+	for _, trigger := range triggers {
+		resp.Triggers = append(resp.Triggers, trigger.Name)
+	}
+
+	// Tag first so the new emotion counts towards the load the
+	// recommendation is based on
+	recs, _, err := h.recommendations.Recommend(now, nil, label)
+	if err != nil {
+		resp.Message += " Recommendation failed: " + err.Error()
+	} else {
+		resp.Recommendation = &recs[0]
+		resp.Message += " Next: " + recs[0].Title + "."
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
 
 // Decompress handles POST /api/v1/emotion/decompress
 // Decompression is structured recovery time - not distraction or numbing,
 // but intentional restoration. The method (walk, music, shower, silence)
 // and duration create a bounded "recovery window" for emotional reset.
+// An optional check_in tags how you feel going in, so the session's effect
+// can be measured against the check-in when it finishes. The response says
+// how this method has worked before and names one that has worked better.
 func (h *EmotionHandler) Decompress(c *gin.Context) {
 	var req models.EmotionDecompressRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -109,6 +138,16 @@ func (h *EmotionHandler) Decompress(c *gin.Context) {
 		CreatedAt: now,
 	}
 
+	if req.CheckIn != nil {
+		if session.PreCheckIn, err = h.newCheckIn(req.CheckIn, "before decompression: "+req.Method, now); err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				"Failed to record check-in",
+				err.Error(),
+			))
+			return
+		}
+	}
+
 	if err := h.db.CreateDecompressSession(session); err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			"Failed to start decompression",
@@ -116,24 +155,450 @@ func (h *EmotionHandler) Decompress(c *gin.Context) {
 		))
 		return
 	}
+	if session.PreCheckIn != nil {
+		if _, err := h.triggers.TagState(session.PreCheckIn); err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				"Failed to tag check-in triggers",
+				err.Error(),
+			))
+			return
+		}
+	}
 
-	var methodAdvice string
-	switch req.Method {
-	case "walk":
-		methodAdvice = "Movement helps process emotions. Don't rush."
-	case "music":
-		methodAdvice = "Let the music work. No need to solve anything right now."
-	case "shower":
-		methodAdvice = "Water and warmth are restorative. Be present."
-	case "silence":
-		methodAdvice = "Silence allows the mind to settle. Resist the urge to fill it."
-	default:
-		methodAdvice = "Recovery time activated."
+	// Compare the method with how it and the others have worked before
+	insight, err := h.recommendations.MethodInsight(req.Method, now)
+	if err != nil {
+		insight = "Method history unavailable: " + err.Error()
 	}
 
 	c.JSON(http.StatusOK, models.EmotionResponse{
-		Message:   "Decompression session started: " + req.Method + " for " + req.Duration + ". " + methodAdvice,
+		Message:   "Decompression session started: " + req.Method + " for " + req.Duration + ". " + insight,
 		ID:        session.ID,
 		Timestamp: now,
 	})
 }
+
+// GetDecompression handles GET /api/v1/emotion/decompress/:id
+// Returns a decompression session with its check-ins. Sessions past their
+// planned end are completed automatically.
+func (h *EmotionHandler) GetDecompression(c *gin.Context) {
+	session, ok := h.loadDecompressSession(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DecompressResponse{
+		Message:   "Decompression session " + session.Status + ".",
+		Session:   session,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// EndDecompression handles POST /api/v1/emotion/decompress/:id/end
+// Ends a session early. Stopping before the timer is fine - recovery does
+// not always need the full window - and it still counts towards the method.
+func (h *EmotionHandler) EndDecompression(c *gin.Context) {
+	h.finishDecompression(c, "ended")
+}
+
+// CompleteDecompression handles POST /api/v1/emotion/decompress/:id/complete
+// Marks a session as done. A session completed automatically when its time
+// ran out can still be given its post-session check-in here.
+func (h *EmotionHandler) CompleteDecompression(c *gin.Context) {
+	h.finishDecompression(c, "completed")
+}
+
+// CancelDecompression handles POST /api/v1/emotion/decompress/:id/cancel
+// Abandons a session that didn't really happen. Cancelled sessions are left
+// out of the effectiveness report.
+func (h *EmotionHandler) CancelDecompression(c *gin.Context) {
+	h.finishDecompression(c, "cancelled")
+}
+
+// finishDecompression moves an active session to status, recording the
+// optional post-session check-in from the request body
+func (h *EmotionHandler) finishDecompression(c *gin.Context, status string) {
+	var req models.DecompressFinishRequest
+	// The body is optional; an empty request finishes without a check-in
+	if err := bindOptionalJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	session, ok := h.loadDecompressSession(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case session.Status == "active":
+		affected, err := h.db.EndDecompressSession(session.ID, status, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				"Failed to finish decompression",
+				err.Error(),
+			))
+			return
+		}
+		if affected == 0 {
+			c.JSON(http.StatusConflict, models.NewErrorResponse(
+				"Decompression session not active",
+				"The session finished before this request was applied",
+			))
+			return
+		}
+	case session.Status == "completed" && status == "completed" &&
+		session.PostCheckIn == nil && req.CheckIn != nil:
+		// Completed automatically; only the check-in is still missing
+	default:
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			"Decompression session not active",
+			"This session is already "+session.Status,
+		))
+		return
+	}
+
+	if req.CheckIn != nil && session.PostCheckIn == nil {
+		state, err := h.newCheckIn(req.CheckIn, "after decompression: "+session.Method, now)
+		var linked int64
+		if err == nil {
+			linked, err = h.db.SetDecompressPostCheckIn(session.ID, state)
+		}
+		if err == nil && linked > 0 {
+			_, err = h.triggers.TagState(state)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				"Failed to record check-in",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	finished, err := h.db.GetDecompressSession(session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to get decompression session",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DecompressResponse{
+		Message:   "Decompression session " + finished.Status + ": " + finished.Method + ".",
+		Session:   finished,
+		Timestamp: now,
+	})
+}
+
+// loadDecompressSession completes expired sessions, then fetches the session
+// named by the :id parameter, writing the error response when it fails
+func (h *EmotionHandler) loadDecompressSession(c *gin.Context) (*models.DecompressSession, bool) {
+	if _, err := h.db.CompleteExpiredDecompressSessions(time.Now().UTC()); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to complete expired sessions",
+			err.Error(),
+		))
+		return nil, false
+	}
+	session, err := h.db.GetDecompressSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to find decompression session",
+			err.Error(),
+		))
+		return nil, false
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Decompression session not found",
+			"No decompression session exists with the provided ID",
+		))
+		return nil, false
+	}
+	return session, true
+}
+
+// newCheckIn turns a decompression check-in into an emotional state, so it
+// also counts towards emotional load like any other tag. The state is stored
+// with the session it belongs to; its triggers are tagged once it is.
+func (h *EmotionHandler) newCheckIn(checkIn *models.EmotionCheckIn, defaultSource string, now time.Time) (*models.EmotionalState, error) {
+	label, _, err := h.emotions.Resolve(checkIn.Label)
+	if err != nil {
+		return nil, err
+	}
+	source := strings.TrimSpace(checkIn.SourceGuess)
+	if source == "" {
+		source = defaultSource
+	}
+
+	return &models.EmotionalState{
+		ID:          uuid.New().String(),
+		Label:       label,
+		SourceGuess: source,
+		Intensity:   checkIn.Intensity,
+		CreatedAt:   now,
+	}, nil
+}
+
+// bindOptionalJSON binds a JSON body that may be left out. A missing or
+// empty body - chunked ones included, which carry no Content-Length - leaves
+// obj as it is.
+func bindOptionalJSON(c *gin.Context, obj interface{}) error {
+	if c.Request.Body == nil {
+		return nil
+	}
+	if err := c.ShouldBindJSON(obj); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// GetTaxonomy handles GET /api/v1/emotion/taxonomy
+// The taxonomy defines each known emotion's valence, arousal, load weight
+// and synonyms. It drives how tags are normalized and how much they weigh.
+func (h *EmotionHandler) GetTaxonomy(c *gin.Context) {
+	taxonomy, err := h.db.GetEmotionTaxonomy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to get emotion taxonomy",
+			err.Error(),
+		))
+		return
+	}
+	if taxonomy == nil {
+		taxonomy = []models.EmotionDefinition{}
+	}
+
+	c.JSON(http.StatusOK, models.EmotionTaxonomyResponse{
+		Message:   "Emotion taxonomy retrieved.",
+		Count:     len(taxonomy),
+		Emotions:  taxonomy,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// PutEmotion handles PUT /api/v1/emotion/taxonomy/:label
+// Adds an emotion to the taxonomy or replaces its definition. Everyone's
+// emotional vocabulary is different; the load weights should match yours.
+func (h *EmotionHandler) PutEmotion(c *gin.Context) {
+	var req models.EmotionDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	now := time.Now().UTC()
+	def := &models.EmotionDefinition{
+		Label:      strings.ToLower(strings.TrimSpace(c.Param("label"))),
+		Valence:    *req.Valence,
+		Arousal:    *req.Arousal,
+		LoadWeight: *req.LoadWeight,
+		Synonyms:   []string{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	for _, synonym := range req.Synonyms {
+		synonym = strings.ToLower(strings.TrimSpace(synonym))
+		if strings.Contains(synonym, ",") {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Invalid synonym",
+				"Synonyms cannot contain commas: "+synonym,
+			))
+			return
+		}
+		if synonym != "" && synonym != def.Label {
+			def.Synonyms = append(def.Synonyms, synonym)
+		}
+	}
+
+	if err := h.emotions.ValidateDefinition(def); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+	if err := h.db.UpsertEmotionDefinition(def); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to save emotion",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.EmotionTaxonomyResponse{
+		Message:   "Emotion saved: " + def.Label,
+		Count:     1,
+		Emotions:  []models.EmotionDefinition{*def},
+		Timestamp: now,
+	})
+}
+
+// DeleteEmotion handles DELETE /api/v1/emotion/taxonomy/:label
+// Removes an emotion from the taxonomy. Past tags keep their label but no
+// longer add to emotional load.
+func (h *EmotionHandler) DeleteEmotion(c *gin.Context) {
+	label := strings.ToLower(strings.TrimSpace(c.Param("label")))
+	affected, err := h.db.DeleteEmotionDefinition(label)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to delete emotion",
+			err.Error(),
+		))
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Emotion not found",
+			"No taxonomy entry exists for: "+label,
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.EmotionResponse{
+		Message:   "Emotion removed from taxonomy: " + label,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// defaultEmotionRange is how far back history and trends look when no range is given
+const defaultEmotionRange = 30 * 24 * time.Hour
+
+// maxEmotionRange bounds the range accepted by history and trends
+const maxEmotionRange = 366 * 24 * time.Hour
+
+// History handles GET /api/v1/emotion/history
+// Returns the emotions tagged over a time range, newest first. Looking back
+// at what you actually felt is more reliable than remembering it. Optional
+// label filters to one emotion (synonyms resolve) and limit caps the results
+// (default 100, max 1000).
+func (h *EmotionHandler) History(c *gin.Context) {
+	from, to, _, err := parseEmotionRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid time range", err.Error()))
+		return
+	}
+
+	limit := 100
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Invalid limit",
+				"Limit must be a number between 1 and 1000",
+			))
+			return
+		}
+		limit = parsed
+	}
+
+	var label string
+	if raw := c.Query("label"); raw != "" {
+		if label, _, err = h.emotions.Resolve(raw); err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				"Failed to resolve emotion",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	states, err := h.db.GetEmotionalStates(from, to, label, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to get emotion history",
+			err.Error(),
+		))
+		return
+	}
+	if states == nil {
+		states = []models.EmotionalState{}
+	}
+
+	c.JSON(http.StatusOK, models.EmotionHistoryResponse{
+		From:      from,
+		To:        to,
+		Count:     len(states),
+		States:    states,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// Trends handles GET /api/v1/emotion/trends
+// Patterns only show up in aggregate: which emotions recur, what you blame
+// them on, when in the day and week they arrive, and whether heavy days
+// track a pile of open loops or abandoned focus sessions. Days, hours and
+// weekdays use the optional tz (IANA name, default UTC).
+func (h *EmotionHandler) Trends(c *gin.Context) {
+	from, to, loc, err := parseEmotionRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid time range", err.Error()))
+		return
+	}
+
+	trends, err := h.emotions.Trends(from, to, loc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to compute emotion trends",
+			err.Error(),
+		))
+		return
+	}
+	trends.Timestamp = time.Now().UTC()
+
+	c.JSON(http.StatusOK, trends)
+}
+
+// parseEmotionRange reads the from, to and tz query parameters. Times may be
+// RFC3339 or YYYY-MM-DD; a date-only to includes that whole day. The range
+// defaults to the last 30 days and is returned in UTC.
+func parseEmotionRange(c *gin.Context) (time.Time, time.Time, *time.Location, error) {
+	loc := time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return time.Time{}, time.Time{}, nil, fmt.Errorf("unknown time zone %q", tz)
+		}
+	}
+
+	parse := func(name string, endOfDay bool) (time.Time, bool, error) {
+		raw := c.Query(name)
+		if raw == "" {
+			return time.Time{}, false, nil
+		}
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t.UTC(), true, nil
+		}
+		t, err := time.ParseInLocation("2006-01-02", raw, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%s must be RFC3339 or YYYY-MM-DD, got %q", name, raw)
+		}
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t.UTC(), true, nil
+	}
+
+	to, ok, err := parse("to", true)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, err
+	}
+	if !ok {
+		to = time.Now().UTC()
+	}
+	from, ok, err := parse("from", false)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, err
+	}
+	if !ok {
+		from = to.Add(-defaultEmotionRange)
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("from must be before to")
+	}
+	if to.Sub(from) > maxEmotionRange {
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("range cannot exceed 366 days")
+	}
+	return from, to, loc, nil
+}
@@ -0,0 +1,558 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/ai"
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// setupEmotionRouter creates a test router with emotion, trigger, dashboard,
+// recommendation and emotion report routes
+func setupEmotionRouter(db *database.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	emotions := services.NewEmotionService(db, 4*time.Hour)
+	triggers := services.NewTriggerService(db, emotions)
+	cognitive := services.NewCognitiveStateService(
+		db,
+		services.NewRuminationGuard(db, services.RuminationPolicy{}),
+		emotions,
+		services.NewAssistService(db, services.NewOffloadService(db, ai.NewStubProvider(0), services.OffloadPolicy{})),
+	)
+	reports := services.NewReportService(db, emotions)
+	recommendations := services.NewRecommendationService(db, cognitive, emotions, reports, time.UTC)
+	handler := NewEmotionHandler(db, emotions, triggers, recommendations)
+	triggerHandler := NewTriggerHandler(db, triggers)
+	focusHandler := NewFocusHandler(db, cognitive, services.NewArchiver(db, false), services.NewLessonService(db))
+	reportHandler := NewReportHandler(
+		reports,
+		services.NewRuminationGuard(db, services.RuminationPolicy{}),
+		triggers,
+	)
+	recommendationHandler := NewRecommendationHandler(recommendations)
+
+	router.POST("/api/v1/emotion/tag", handler.TagEmotion)
+	router.GET("/api/v1/emotion/taxonomy", handler.GetTaxonomy)
+	router.PUT("/api/v1/emotion/taxonomy/:label", handler.PutEmotion)
+	router.DELETE("/api/v1/emotion/taxonomy/:label", handler.DeleteEmotion)
+	router.GET("/api/v1/emotion/history", handler.History)
+	router.GET("/api/v1/emotion/trends", handler.Trends)
+	router.POST("/api/v1/emotion/decompress", handler.Decompress)
+	router.GET("/api/v1/emotion/decompress/:id", handler.GetDecompression)
+	router.POST("/api/v1/emotion/decompress/:id/end", handler.EndDecompression)
+	router.POST("/api/v1/emotion/decompress/:id/complete", handler.CompleteDecompression)
+	router.POST("/api/v1/emotion/decompress/:id/cancel", handler.CancelDecompression)
+	router.GET("/api/v1/reports/decompression", reportHandler.Decompression)
+	router.GET("/api/v1/reports/triggers", reportHandler.Triggers)
+	router.GET("/api/v1/triggers", triggerHandler.ListTriggers)
+	router.POST("/api/v1/triggers", triggerHandler.CreateTrigger)
+	router.POST("/api/v1/triggers/backfill", triggerHandler.Backfill)
+	router.PUT("/api/v1/triggers/:id", triggerHandler.UpdateTrigger)
+	router.DELETE("/api/v1/triggers/:id", triggerHandler.DeleteTrigger)
+	router.GET("/api/v1/dashboard/status", focusHandler.GetDashboardStatus)
+	router.GET("/api/v1/recommendations", recommendationHandler.GetRecommendations)
+
+	return router
+}
+
+// TestEmotionalLoad tests synonym resolution, intensity validation and the
+// weighted emotional load shown on the dashboard
+func TestEmotionalLoad(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupEmotionRouter(db)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	load := func() models.LoadLevel {
+		w := send("GET", "/api/v1/dashboard/status", "")
+		var status models.CognitiveStatus
+		json.Unmarshal(w.Body.Bytes(), &status)
+		return status.EmotionalLoad
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"intensity too high", `{"label": "angry", "source_guess": "traffic", "intensity": 11}`, http.StatusBadRequest},
+		{"intensity too low", `{"label": "angry", "source_guess": "traffic", "intensity": 0}`, http.StatusBadRequest},
+		{"low-weight emotion", `{"label": "relaxed", "source_guess": "walk", "intensity": 9}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := send("POST", "/api/v1/emotion/tag", tt.body); w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+	if got := load(); got != models.LoadLevelLow {
+		t.Errorf("Expected low load after calm tag, got %s", got)
+	}
+
+	// "furious" is a synonym of angry (weight 1.0): 1.0 * 7/10 = 0.7 -> medium
+	send("POST", "/api/v1/emotion/tag", `{"label": "Furious", "source_guess": "email", "intensity": 7}`)
+	states, _ := db.GetRecentEmotionalStates(1)
+	if len(states) != 1 || states[0].Label != "angry" {
+		t.Fatalf("Expected synonym stored as angry, got %+v", states)
+	}
+	if got := load(); got != models.LoadLevelMedium {
+		t.Errorf("Expected medium load, got %s", got)
+	}
+
+	// An old, intense tag has decayed to almost nothing
+	old := 10
+	db.CreateEmotionalState(&models.EmotionalState{
+		ID: "old", Label: "overwhelmed", SourceGuess: "launch", Intensity: &old,
+		CreatedAt: time.Now().UTC().Add(-16 * time.Hour),
+	})
+	if got := load(); got != models.LoadLevelMedium {
+		t.Errorf("Expected decayed tag not to raise load, got %s", got)
+	}
+
+	send("POST", "/api/v1/emotion/tag", `{"label": "stressed", "source_guess": "deadline"}`)
+	if got := load(); got != models.LoadLevelHigh {
+		t.Errorf("Expected high load, got %s", got)
+	}
+
+	t.Run("taxonomy synonym conflict", func(t *testing.T) {
+		w := send("PUT", "/api/v1/emotion/taxonomy/irate",
+			`{"valence": -0.8, "arousal": 0.9, "load_weight": 1, "synonyms": ["furious"]}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("taxonomy update", func(t *testing.T) {
+		w := send("PUT", "/api/v1/emotion/taxonomy/stressed",
+			`{"valence": -0.6, "arousal": 0.8, "load_weight": 0, "synonyms": ["pressured"]}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if got := load(); got != models.LoadLevelMedium {
+			t.Errorf("Expected load to drop after zeroing stressed weight, got %s", got)
+		}
+	})
+
+	t.Run("taxonomy delete", func(t *testing.T) {
+		if w := send("DELETE", "/api/v1/emotion/taxonomy/angry", ""); w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+		if w := send("DELETE", "/api/v1/emotion/taxonomy/angry", ""); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
+
+// TestEmotionTrends tests history filtering, trend breakdowns and the
+// correlation of daily load with open loops and focus completion
+func TestEmotionTrends(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupEmotionRouter(db)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Monday to Wednesday: load and open loops rise together,
+	// while the last day's focus session is abandoned
+	base := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	for i, intensity := range []int{2, 5, 9} {
+		day := base.AddDate(0, 0, i)
+		suffix := string(rune('a' + i))
+		intensity := intensity
+		db.CreateEmotionalState(&models.EmotionalState{
+			ID: "state-" + suffix, Label: "angry", SourceGuess: []string{"Email", " email ", "boss"}[i],
+			Intensity: &intensity, CreatedAt: day.Add(10 * time.Hour),
+		})
+		db.CreateLoop(&models.Loop{
+			ID: "loop-" + suffix, Description: "loop", Priority: models.PriorityMedium,
+			Queue: models.QueueAction, Owner: "me", Status: "open",
+			CreatedAt: day.Add(time.Hour), UpdatedAt: day.Add(time.Hour),
+		})
+		focusID := "focus-" + suffix
+		db.SetFocus(&models.FocusState{
+			ID: focusID, TaskName: "work", Duration: "1h", SuccessCriteria: "done",
+			StartedAt: day.Add(9 * time.Hour), EndsAt: day.Add(10 * time.Hour),
+			CreatedAt: day.Add(9 * time.Hour), UpdatedAt: day.Add(9 * time.Hour),
+		})
+		if i < 2 {
+			db.CompleteFocus(focusID, day.Add(10*time.Hour))
+		}
+	}
+
+	t.Run("history with synonym and limit", func(t *testing.T) {
+		w := get("/api/v1/emotion/history?from=2026-03-02&to=2026-03-04&label=furious&limit=2")
+		var resp models.EmotionHistoryResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || resp.Count != 2 || resp.States[0].ID != "state-c" {
+			t.Errorf("Expected the 2 newest angry states, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	invalid := []struct {
+		name  string
+		query string
+	}{
+		{"from after to", "?from=2026-03-04&to=2026-03-02"},
+		{"bad date", "?from=March"},
+		{"unknown time zone", "?tz=Mars/Olympus"},
+		{"range too long", "?from=2024-01-01&to=2026-03-04"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := get("/api/v1/emotion/trends" + tt.query); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+
+	w := get("/api/v1/emotion/trends?from=2026-03-02&to=2026-03-04")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var trends models.EmotionTrends
+	json.Unmarshal(w.Body.Bytes(), &trends)
+
+	if trends.Total != 3 || trends.ByLabel["angry"] != 3 || trends.BySource["email"] != 2 {
+		t.Errorf("Unexpected breakdown: %+v", trends)
+	}
+	if trends.ByHour[10] != 3 || trends.ByWeekday["Monday"] != 1 {
+		t.Errorf("Unexpected hour/weekday counts: %v %v", trends.ByHour, trends.ByWeekday)
+	}
+	if len(trends.Daily) != 3 || trends.Daily[2].OpenLoops != 3 || trends.Daily[2].FocusCompleted != 0 {
+		t.Fatalf("Unexpected daily series: %+v", trends.Daily)
+	}
+	if r := trends.Correlations.OpenLoops; r == nil || *r < 0.9 {
+		t.Errorf("Expected strong positive load/open-loop correlation, got %v", r)
+	}
+	if r := trends.Correlations.FocusCompletion; r == nil || *r > -0.9 {
+		t.Errorf("Expected strong negative load/focus-completion correlation, got %v", r)
+	}
+
+	t.Run("time zone shifts hours", func(t *testing.T) {
+		w := get("/api/v1/emotion/trends?from=2026-03-02&to=2026-03-04&tz=America/New_York")
+		var local models.EmotionTrends
+		json.Unmarshal(w.Body.Bytes(), &local)
+		if local.ByHour[5] != 3 {
+			t.Errorf("Expected tags at 05:00 New York time, got %v", local.ByHour)
+		}
+	})
+}
+
+// TestDecompressionLifecycle tests ending, completing and cancelling
+// decompression sessions, automatic completion and the effectiveness report
+func TestDecompressionLifecycle(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupEmotionRouter(db)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	start := func(body string) string {
+		w := send("POST", "/api/v1/emotion/decompress", body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Failed to start decompression: %s", w.Body.String())
+		}
+		var resp models.EmotionResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.ID
+	}
+	base := "/api/v1/emotion/decompress/"
+
+	walk := start(`{"method": "walk", "duration": "15m", "check_in": {"label": "furious", "intensity": 9}}`)
+	music := start(`{"method": "music", "duration": "10m", "check_in": {"label": "anxious", "intensity": 6}}`)
+	shower := start(`{"method": "shower", "duration": "10m"}`)
+
+	// A silence session whose timer ran out an hour ago
+	pre := 10
+	preState := &models.EmotionalState{
+		ID: "pre-silence", Label: "stressed", SourceGuess: "deadline", Intensity: &pre,
+		CreatedAt: time.Now().UTC().Add(-2 * time.Hour),
+	}
+	db.CreateDecompressSession(&models.DecompressSession{
+		ID: "silence", Method: "silence", Duration: "1h", Status: "active",
+		StartedAt: preState.CreatedAt, EndsAt: preState.CreatedAt.Add(time.Hour),
+		PreCheckIn: preState, CreatedAt: preState.CreatedAt,
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+		expectedState  string
+	}{
+		{"complete with check-in", walk + "/complete", `{"check_in": {"label": "calm", "intensity": 3}}`, http.StatusOK, "completed"},
+		{"complete twice", walk + "/complete", "", http.StatusConflict, ""},
+		{"end early", music + "/end", `{"check_in": {"label": "anxious", "intensity": 6}}`, http.StatusOK, "ended"},
+		{"invalid check-in", shower + "/cancel", `{"check_in": {"label": "calm", "intensity": 12}}`, http.StatusBadRequest, ""},
+		{"cancel", shower + "/cancel", "", http.StatusOK, "cancelled"},
+		{"end after cancel", shower + "/end", "", http.StatusConflict, ""},
+		{"check-in after auto-complete", "silence/complete", `{"check_in": {"label": "tired", "intensity": 4}}`, http.StatusOK, "completed"},
+		{"second post check-in", "silence/complete", `{"check_in": {"label": "calm"}}`, http.StatusConflict, ""},
+		{"unknown session", "nope/end", "", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send("POST", base+tt.path, tt.body)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedState == "" {
+				return
+			}
+			var resp models.DecompressResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Session.Status != tt.expectedState || resp.Session.EndedAt == nil {
+				t.Errorf("Expected %s session with end time, got %+v", tt.expectedState, resp.Session)
+			}
+		})
+	}
+
+	t.Run("check-ins recorded", func(t *testing.T) {
+		var resp models.DecompressResponse
+		json.Unmarshal(send("GET", base+walk, "").Body.Bytes(), &resp)
+		if resp.Session.PreCheckIn == nil || resp.Session.PreCheckIn.Label != "angry" ||
+			resp.Session.PostCheckIn == nil || resp.Session.PostCheckIn.Label != "calm" {
+			t.Errorf("Expected angry -> calm check-ins, got %+v", resp.Session)
+		}
+	})
+
+	w := send("GET", "/api/v1/reports/decompression", "")
+	var report models.DecompressionReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if len(report.Methods) != 4 {
+		t.Fatalf("Expected 4 methods, got %s", w.Body.String())
+	}
+
+	// walk: 1.0*0.9 -> 0; silence: 1.0*1.0 -> 0.5*0.4; music: unchanged; shower: cancelled
+	expected := []struct {
+		method    string
+		reduction float64
+	}{{"walk", 0.9}, {"silence", 0.8}, {"music", 0}}
+	for i, e := range expected {
+		m := report.Methods[i]
+		if m.Method != e.method || m.AvgReduction == nil || math.Abs(*m.AvgReduction-e.reduction) > 1e-9 {
+			t.Errorf("Expected %s with reduction %.1f at %d, got %+v", e.method, e.reduction, i, m)
+		}
+	}
+	if shower := report.Methods[3]; shower.Method != "shower" || shower.Cancelled != 1 || shower.AvgReduction != nil {
+		t.Errorf("Expected unmeasured cancelled shower last, got %+v", shower)
+	}
+}
+
+// TestDecompressionCheckInWrites tests that a check-in is only stored along
+// with its session, and that a chunked finish body is read
+func TestDecompressionCheckInWrites(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupEmotionRouter(db)
+
+	t.Run("failed session leaves no check-in", func(t *testing.T) {
+		now := time.Now().UTC()
+		session := func(stateID string) *models.DecompressSession {
+			return &models.DecompressSession{
+				ID: "walk", Method: "walk", Duration: "15m", Status: "active", StartedAt: now, EndsAt: now.Add(15 * time.Minute),
+				PreCheckIn: &models.EmotionalState{ID: stateID, Label: "stressed", CreatedAt: now}, CreatedAt: now,
+			}
+		}
+		if err := db.CreateDecompressSession(session("first")); err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+		if err := db.CreateDecompressSession(session("second")); err == nil {
+			t.Fatal("Expected a duplicate session to fail")
+		}
+		if states, _ := db.GetRecentEmotionalStates(10); len(states) != 1 || states[0].ID != "first" {
+			t.Errorf("Expected only the first check-in stored, got %+v", states)
+		}
+	})
+
+	t.Run("chunked finish body", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v1/emotion/decompress", bytes.NewBufferString(`{"method": "music", "duration": "10m"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var started models.EmotionResponse
+		json.Unmarshal(w.Body.Bytes(), &started)
+
+		req, _ = http.NewRequest("POST", "/api/v1/emotion/decompress/"+started.ID+"/end",
+			io.NopCloser(strings.NewReader(`{"check_in": {"label": "calm", "intensity": 2}}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = -1
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp models.DecompressResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || resp.Session.PostCheckIn == nil || resp.Session.PostCheckIn.Label != "calm" {
+			t.Errorf("Expected the chunked check-in recorded, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+// TestTriggers tests trigger detection from source guesses, kind guessing,
+// merging through aliases and the trigger report
+func TestTriggers(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupEmotionRouter(db)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	listTriggers := func() map[string]models.Trigger {
+		var resp models.TriggerResponse
+		json.Unmarshal(send("GET", "/api/v1/triggers", "").Body.Bytes(), &resp)
+		byName := make(map[string]models.Trigger)
+		for _, trigger := range resp.Triggers {
+			byName[trigger.Name] = trigger
+		}
+		return byName
+	}
+
+	earlier := time.Now().UTC().Add(-2 * time.Hour)
+	db.CreateLoop(&models.Loop{
+		ID: "deck", Description: "Finish Q3 launch deck", Priority: models.PriorityHigh,
+		Queue: models.QueueAction, Owner: "me", Status: "open", CreatedAt: earlier, UpdatedAt: earlier,
+	})
+	db.CreateThread(&models.Thread{
+		ID: "hiring", Name: "Hiring plan", Mode: models.ThreadModeBackground, TimeScope: "this quarter",
+		Status: "active", CreatedAt: earlier, UpdatedAt: earlier,
+	})
+
+	w := send("POST", "/api/v1/triggers", `{"name": "Sarah", "kind": "person", "aliases": ["my manager"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create trigger: %s", w.Body.String())
+	}
+	var sarah models.TriggerResponse
+	json.Unmarshal(w.Body.Bytes(), &sarah)
+
+	w = send("POST", "/api/v1/emotion/tag", `{"label": "angry", "source_guess": "Meeting with Sam about the Q3 launch"}`)
+	var tagged models.EmotionResponse
+	json.Unmarshal(w.Body.Bytes(), &tagged)
+	if strings.Join(tagged.Triggers, ",") != "meeting,sam,q3 launch" {
+		t.Errorf("Expected meeting, sam and q3 launch, got %v", tagged.Triggers)
+	}
+	send("POST", "/api/v1/emotion/tag", `{"label": "anxious", "source_guess": "Manager, Q3 launch!"}`)
+	send("POST", "/api/v1/emotion/tag", `{"label": "calm", "source_guess": "walk"}`)
+
+	// Tagged before trigger detection existed; picked up by the backfill
+	db.CreateEmotionalState(&models.EmotionalState{
+		ID: "legacy", Label: "stressed", SourceGuess: "hiring plan", CreatedAt: earlier.Add(time.Hour),
+	})
+
+	t.Run("kinds guessed", func(t *testing.T) {
+		triggers := listTriggers()
+		expected := map[string]models.TriggerKind{
+			"meeting":   models.TriggerKindContext,
+			"sam":       models.TriggerKindPerson,
+			"q3 launch": models.TriggerKindProject,
+			"walk":      models.TriggerKindContext,
+		}
+		for name, kind := range expected {
+			if triggers[name].Kind != kind {
+				t.Errorf("Expected %s to be a %s, got %+v", name, kind, triggers[name])
+			}
+		}
+		if triggers["sarah"].Tags != 1 || triggers["q3 launch"].Tags != 2 {
+			t.Errorf("Unexpected tag counts: %+v", triggers)
+		}
+	})
+
+	w = send("POST", "/api/v1/triggers/backfill", "")
+	var backfill models.TriggerResponse
+	json.Unmarshal(w.Body.Bytes(), &backfill)
+	if w.Code != http.StatusOK || backfill.Linked != 1 {
+		t.Fatalf("Expected the legacy emotion backfilled, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = send("GET", "/api/v1/reports/triggers", "")
+	var report models.TriggerReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if w.Code != http.StatusOK || report.Emotions != 4 || report.HighLoad != 3 {
+		t.Fatalf("Unexpected report: %s", w.Body.String())
+	}
+	if top := report.Triggers[0]; top.Name != "q3 launch" || top.HighLoadTags != 2 {
+		t.Errorf("Expected q3 launch as the top trigger, got %+v", top)
+	}
+	if len(report.Loops) != 1 || report.Loops[0].ID != "deck" || report.Loops[0].HighLoadEmotions != 2 {
+		t.Errorf("Expected the deck loop before 2 high-load emotions, got %+v", report.Loops)
+	}
+	if len(report.Threads) != 1 || report.Threads[0].ID != "hiring" || report.Threads[0].HighLoadEmotions != 1 {
+		t.Errorf("Expected the hiring thread before 1 high-load emotion, got %+v", report.Threads)
+	}
+	if listTriggers()["hiring plan"].Kind != models.TriggerKindProject {
+		t.Errorf("Expected backfilled hiring plan trigger to be a project")
+	}
+
+	t.Run("merge through alias", func(t *testing.T) {
+		w := send("PUT", "/api/v1/triggers/"+sarah.Trigger.ID, `{"name": "Sarah", "kind": "person", "aliases": ["manager", "Sam"]}`)
+		var resp models.TriggerResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || len(resp.Merged) != 1 || resp.Trigger.Tags != 2 {
+			t.Fatalf("Expected sam merged into sarah, got %d: %s", w.Code, w.Body.String())
+		}
+		if _, ok := listTriggers()["sam"]; ok {
+			t.Errorf("Expected merged trigger to be removed")
+		}
+	})
+
+	invalid := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"name used as alias", "POST", "/api/v1/triggers", `{"name": "Manager", "kind": "person"}`, http.StatusBadRequest},
+		{"duplicate name", "POST", "/api/v1/triggers", `{"name": "the walk", "kind": "context"}`, http.StatusBadRequest},
+		{"invalid kind", "POST", "/api/v1/triggers", `{"name": "rain", "kind": "weather"}`, http.StatusBadRequest},
+		{"unknown trigger", "DELETE", "/api/v1/triggers/nope", "", http.StatusNotFound},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := send(tt.method, tt.path, tt.body); w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
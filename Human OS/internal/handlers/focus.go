@@ -17,15 +17,24 @@ import (
 
 // FocusHandler handles focus-related endpoints
 type FocusHandler struct {
-	db      *database.DB
-	service *services.CognitiveStateService
+	db       *database.DB
+	service  *services.CognitiveStateService
+	archiver *services.Archiver
+	lessons  *services.LessonService
 }
 
 // NewFocusHandler creates a new focus handler
-func NewFocusHandler(db *database.DB, service *services.CognitiveStateService) *FocusHandler {
+func NewFocusHandler(
+	db *database.DB,
+	service *services.CognitiveStateService,
+	archiver *services.Archiver,
+	lessons *services.LessonService,
+) *FocusHandler {
 	return &FocusHandler{
-		db:      db,
-		service: service,
+		db:       db,
+		service:  service,
+		archiver: archiver,
+		lessons:  lessons,
 	}
 }
 
@@ -33,12 +42,17 @@ func NewFocusHandler(db *database.DB, service *services.CognitiveStateService) *
 // This endpoint is the primary way to direct attention to a specific task.
 // Setting focus creates a time-bounded commitment to work on something,
 // with clear success criteria for knowing when you're done.
+// Lessons from related archived work are returned alongside the new focus.
 func (h *FocusHandler) SetFocus(c *gin.Context) {
 	var req models.FocusSetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
 		return
 	}
+	goalID, ok := resolveGoal(c, h.db, req.GoalID)
+	if !ok {
+		return
+	}
 
 	// Parse duration to calculate end time
 	now := time.Now().UTC()
@@ -60,6 +74,7 @@ func (h *FocusHandler) SetFocus(c *gin.Context) {
 		IsLocked:        false,
 		StartedAt:       now,
 		EndsAt:          endTime,
+		GoalID:          goalID,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
@@ -72,9 +87,15 @@ func (h *FocusHandler) SetFocus(c *gin.Context) {
 		return
 	}
 
+	// Resurfacing is best-effort; it must never block setting focus
+	lessons, _ := h.lessons.Resurface(
+		models.LessonContextFocus, focus.ID, focus.TaskName+" "+focus.SuccessCriteria,
+	)
+
 	c.JSON(http.StatusOK, models.FocusResponse{
 		Message:   "Focus set successfully. Deep work mode activated.",
 		Focus:     focus,
+		Lessons:   lessons,
 		Timestamp: now,
 	})
 }
@@ -155,6 +176,74 @@ func (h *FocusHandler) LockFocus(c *gin.Context) {
 	})
 }
 
+// CompleteFocus handles POST /api/v1/focus/complete
+// Completing focus closes the deep work session explicitly instead of letting
+// it fade out. The session is archived automatically (unless disabled) so
+// the archive holds a record of finished work and a prompt for its lesson.
+func (h *FocusHandler) CompleteFocus(c *gin.Context) {
+	var req models.FocusCompleteRequest
+	// The body is optional; an empty request completes with defaults
+	if err := bindOptionalJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	focus, err := h.db.GetCurrentFocus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to get current focus",
+			err.Error(),
+		))
+		return
+	}
+	if focus == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"No active focus",
+			"There is no active focus session to complete",
+		))
+		return
+	}
+
+	now := time.Now().UTC()
+	completed, err := h.db.CompleteFocus(focus.ID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to complete focus",
+			err.Error(),
+		))
+		return
+	}
+	if completed == 0 {
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			"Focus already ended",
+			"The focus session ended before it could be completed",
+		))
+		return
+	}
+	focus.Status = "completed"
+	focus.CompletedAt = &now
+	focus.UpdatedAt = now
+
+	resp := models.FocusResponse{
+		Message:   "Focus session COMPLETE. Attention released.",
+		Focus:     focus,
+		Timestamp: now,
+	}
+
+	if h.archiver.ShouldArchive(req.Archive) {
+		archive, err := h.archiver.ArchiveFocus(focus, req.Outcome)
+		if err != nil {
+			resp.Message += " Automatic archive failed: " + err.Error()
+		} else {
+			resp.ArchiveID = archive.ID
+			resp.LessonPrompt = services.LessonPrompt(archive.ID)
+			resp.Message += " Archived."
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetDashboardStatus handles GET /api/v1/dashboard/status
 // The dashboard provides a complete view of your current cognitive state,
 // including active threads, open loops, emotional load, and energy level.
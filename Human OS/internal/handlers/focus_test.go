@@ -8,9 +8,11 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"humanos-api/internal/ai"
 	"humanos-api/internal/database"
 	"humanos-api/internal/models"
 	"humanos-api/internal/services"
@@ -49,8 +51,13 @@ func setupFocusRouter(db *database.DB) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
-	service := services.NewCognitiveStateService(db)
-	handler := NewFocusHandler(db, service)
+	service := services.NewCognitiveStateService(
+		db,
+		services.NewRuminationGuard(db, services.RuminationPolicy{}),
+		services.NewEmotionService(db, 4*time.Hour),
+		services.NewAssistService(db, services.NewOffloadService(db, ai.NewStubProvider(0), services.OffloadPolicy{})),
+	)
+	handler := NewFocusHandler(db, service, services.NewArchiver(db, true), services.NewLessonService(db))
 
 	v1 := router.Group("/api/v1")
 	{
@@ -58,6 +65,7 @@ func setupFocusRouter(db *database.DB) *gin.Engine {
 		{
 			focus.POST("/set", handler.SetFocus)
 			focus.POST("/lock", handler.LockFocus)
+			focus.POST("/complete", handler.CompleteFocus)
 		}
 		dashboard := v1.Group("/dashboard")
 		{
@@ -279,6 +287,129 @@ func TestLockFocus(t *testing.T) {
 	})
 }
 
+// TestCompleteFocus tests the POST /api/v1/focus/complete endpoint
+func TestCompleteFocus(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupFocusRouter(db)
+
+	t.Run("no active focus", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v1/focus/complete", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("complete and archive", func(t *testing.T) {
+		setBody, _ := json.Marshal(models.FocusSetRequest{
+			TaskName:        "Write archive docs",
+			Duration:        "25m",
+			SuccessCriteria: "Section drafted",
+		})
+		setReq, _ := http.NewRequest("POST", "/api/v1/focus/set", bytes.NewBuffer(setBody))
+		setReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), setReq)
+
+		req, _ := http.NewRequest("POST", "/api/v1/focus/complete",
+			bytes.NewBufferString(`{"outcome": "Drafted and reviewed"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+		}
+
+		var resp models.FocusResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.ArchiveID == "" || resp.LessonPrompt == "" {
+			t.Fatal("Expected archive_id and lesson_prompt in response")
+		}
+
+		archive, err := db.GetArchive(resp.ArchiveID)
+		if err != nil || archive == nil {
+			t.Fatalf("Expected archive to exist: %v", err)
+		}
+		if archive.SourceType != models.ArchiveSourceFocus || archive.SourceID != resp.Focus.ID {
+			t.Errorf("Expected archive to reference focus %s, got %s:%s",
+				resp.Focus.ID, archive.SourceType, archive.SourceID)
+		}
+
+		// Completed focus is no longer current
+		current, _ := db.GetCurrentFocus()
+		if current != nil {
+			t.Error("Expected no active focus after completion")
+		}
+	})
+}
+
+// TestFocusSuperseded tests that setting a new focus retires the previous
+// one, so completing the newest leaves nothing current, the earlier session
+// only counts the time until it was replaced and neither can be completed
+// again
+func TestFocusSuperseded(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupFocusRouter(db)
+
+	for _, req := range []models.FocusSetRequest{
+		{TaskName: "Plan the quarter", Duration: "90m", SuccessCriteria: "Draft plan"},
+		{TaskName: "Answer the auditor", Duration: "25m", SuccessCriteria: "Reply sent"},
+	} {
+		body, _ := json.Marshal(req)
+		setReq, _ := http.NewRequest("POST", "/api/v1/focus/set", bytes.NewBuffer(body))
+		setReq.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, setReq)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Failed to set focus: %s", w.Body.String())
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "/api/v1/focus/complete", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	if current, _ := db.GetCurrentFocus(); current != nil {
+		t.Errorf("Expected no current focus, got %q", current.TaskName)
+	}
+
+	now := time.Now().UTC()
+	sessions, _ := db.GetFocusSessions(now.Add(-time.Hour), now.Add(time.Hour))
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+	// Hours later, the superseded 90m session still counts only until the
+	// second one started
+	var total time.Duration
+	statuses := map[string]string{}
+	for _, session := range sessions {
+		statuses[session.TaskName] = session.Status
+		total += services.FocusTimeSpent(&session, now.Add(3*time.Hour))
+	}
+	if statuses["Plan the quarter"] != "superseded" || statuses["Answer the auditor"] != "completed" {
+		t.Errorf("Expected the first session superseded and the second completed, got %v", statuses)
+	}
+	if total > time.Minute {
+		t.Errorf("Expected under a minute of focus, got %v", total)
+	}
+
+	// Sessions that already ended cannot be completed again
+	for _, session := range sessions {
+		if completed, err := db.CompleteFocus(session.ID, now.Add(time.Hour)); err != nil || completed != 0 {
+			t.Errorf("Expected %s (%s) left alone, got %d, %v", session.TaskName, session.Status, completed, err)
+		}
+	}
+}
+
 // TestGetDashboardStatus tests the GET /api/v1/dashboard/status endpoint
 func TestGetDashboardStatus(t *testing.T) {
 	db, cleanup := testDB(t)
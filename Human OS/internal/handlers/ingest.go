@@ -13,37 +13,60 @@ import (
 
 	"humanos-api/internal/database"
 	"humanos-api/internal/models"
+	"humanos-api/internal/services"
 )
 
 // IngestHandler handles ingestion-related endpoints
 type IngestHandler struct {
-	db *database.DB
+	db         *database.DB
+	classifier *services.TaskClassifier
 }
 
 // NewIngestHandler creates a new ingest handler
-func NewIngestHandler(db *database.DB) *IngestHandler {
-	return &IngestHandler{db: db}
+func NewIngestHandler(db *database.DB, classifier *services.TaskClassifier) *IngestHandler {
+	return &IngestHandler{
+		db:         db,
+		classifier: classifier,
+	}
 }
 
 // IngestTask handles POST /api/v1/ingest/task
 // Tasks are actionable items that need to be processed. The Eisenhower matrix
 // (urgency x importance) helps prioritize: high/high = do first, high/low = delegate,
 // low/high = schedule, low/low = drop or backlog.
+// Category, urgency and importance may be omitted; the classification rules
+// fill them in and the response reports which rule fired.
 func (h *IngestHandler) IngestTask(c *gin.Context) {
 	var req models.IngestTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
 		return
 	}
+	goalID, ok := resolveGoal(c, h.db, req.GoalID)
+	if !ok {
+		return
+	}
+
+	classification, err := h.classifier.Classify(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to classify task",
+			err.Error(),
+		))
+		return
+	}
 
 	now := time.Now().UTC()
 	task := &models.Task{
 		ID:          uuid.New().String(),
 		Description: req.Description,
-		Category:    req.Category,
-		Urgency:     req.Urgency,
-		Importance:  req.Importance,
+		Category:    classification.Category,
+		Urgency:     classification.Urgency,
+		Importance:  classification.Importance,
+		Queue:       classification.Queue,
+		RuleID:      classification.RuleID,
 		Status:      "pending",
+		GoalID:      goalID,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -59,20 +82,27 @@ func (h *IngestHandler) IngestTask(c *gin.Context) {
 	// Provide context-aware message based on urgency/importance
 	var priorityAdvice string
 	switch {
-	case req.Urgency == models.PriorityHigh && req.Importance == models.PriorityHigh:
+	case task.Urgency == models.PriorityHigh && task.Importance == models.PriorityHigh:
 		priorityAdvice = "CRITICAL: Schedule immediately or do now."
-	case req.Urgency == models.PriorityHigh && req.Importance != models.PriorityHigh:
+	case task.Urgency == models.PriorityHigh && task.Importance != models.PriorityHigh:
 		priorityAdvice = "URGENT but not critical: Consider delegating or timeboxing."
-	case req.Urgency != models.PriorityHigh && req.Importance == models.PriorityHigh:
+	case task.Urgency != models.PriorityHigh && task.Importance == models.PriorityHigh:
 		priorityAdvice = "IMPORTANT: Schedule dedicated time for this."
 	default:
 		priorityAdvice = "LOW priority: Backlog or consider dropping."
 	}
 
+	message := "Task captured in category '" + task.Category + "'. " + priorityAdvice
+	if classification.RuleName != "" {
+		message += " Classified by rule '" + classification.RuleName + "'."
+	}
+
 	c.JSON(http.StatusCreated, models.IngestResponse{
-		Message:   "Task captured in category '" + req.Category + "'. " + priorityAdvice,
-		ID:        task.ID,
-		Timestamp: now,
+		Message:        message,
+		ID:             task.ID,
+		Classification: classification,
+		GoalID:         task.GoalID,
+		Timestamp:      now,
 	})
 }
 
@@ -119,3 +149,151 @@ func (h *IngestHandler) IngestIdea(c *gin.Context) {
 		Timestamp: now,
 	})
 }
+
+// ListRules handles GET /api/v1/ingest/rules
+// Rules are returned in evaluation order - the first enabled rule whose
+// pattern matches a task description decides its classification.
+func (h *IngestHandler) ListRules(c *gin.Context) {
+	rules, err := h.db.GetClassificationRules(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list classification rules",
+			err.Error(),
+		))
+		return
+	}
+	if rules == nil {
+		rules = []models.ClassificationRule{}
+	}
+
+	c.JSON(http.StatusOK, models.ClassificationRuleResponse{
+		Message:   "Classification rules in evaluation order.",
+		Rules:     rules,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// CreateRule handles POST /api/v1/ingest/rules
+// A rule turns a recurring classification decision into a reflex: once
+// "invoice" always means admin/high/action, you never have to decide it again.
+func (h *IngestHandler) CreateRule(c *gin.Context) {
+	var req models.ClassificationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+	if err := services.ValidateRulePattern(req.MatchType, req.Pattern); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid rule pattern", err.Error()))
+		return
+	}
+
+	now := time.Now().UTC()
+	rule := &models.ClassificationRule{
+		ID:         uuid.New().String(),
+		Name:       req.Name,
+		MatchType:  req.MatchType,
+		Pattern:    req.Pattern,
+		Category:   req.Category,
+		Urgency:    req.Urgency,
+		Importance: req.Importance,
+		Queue:      req.Queue,
+		Position:   req.Position,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := h.db.CreateClassificationRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to create classification rule",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ClassificationRuleResponse{
+		Message:   "Classification rule created: " + rule.Name + ".",
+		Rule:      rule,
+		Timestamp: now,
+	})
+}
+
+// UpdateRule handles PUT /api/v1/ingest/rules/:id
+// Replaces a rule's pattern, classification and position.
+func (h *IngestHandler) UpdateRule(c *gin.Context) {
+	var req models.ClassificationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+	if err := services.ValidateRulePattern(req.MatchType, req.Pattern); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid rule pattern", err.Error()))
+		return
+	}
+
+	rule, err := h.db.GetClassificationRule(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to find classification rule",
+			err.Error(),
+		))
+		return
+	}
+	if rule == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Rule not found",
+			"No classification rule exists with the provided ID",
+		))
+		return
+	}
+
+	rule.Name = req.Name
+	rule.MatchType = req.MatchType
+	rule.Pattern = req.Pattern
+	rule.Category = req.Category
+	rule.Urgency = req.Urgency
+	rule.Importance = req.Importance
+	rule.Queue = req.Queue
+	rule.Position = req.Position
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if _, err := h.db.UpdateClassificationRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to update classification rule",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ClassificationRuleResponse{
+		Message:   "Classification rule updated: " + rule.Name + ".",
+		Rule:      rule,
+		Timestamp: rule.UpdatedAt,
+	})
+}
+
+// DeleteRule handles DELETE /api/v1/ingest/rules/:id
+func (h *IngestHandler) DeleteRule(c *gin.Context) {
+	affected, err := h.db.DeleteClassificationRule(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to delete classification rule",
+			err.Error(),
+		))
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Rule not found",
+			"No classification rule exists with the provided ID",
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ClassificationRuleResponse{
+		Message:   "Classification rule deleted.",
+		Timestamp: time.Now().UTC(),
+	})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// setupIngestRouter creates a test router with ingest handlers
+func setupIngestRouter(db *database.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	handler := NewIngestHandler(db, services.NewTaskClassifier(db))
+
+	ingest := router.Group("/api/v1/ingest")
+	{
+		ingest.POST("/task", handler.IngestTask)
+		ingest.GET("/rules", handler.ListRules)
+		ingest.POST("/rules", handler.CreateRule)
+		ingest.PUT("/rules/:id", handler.UpdateRule)
+		ingest.DELETE("/rules/:id", handler.DeleteRule)
+	}
+
+	return router
+}
+
+// TestIngestTaskClassification tests rule-based filling of task fields
+func TestIngestTaskClassification(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupIngestRouter(db)
+
+	// Create a keyword rule and a regex rule; position decides precedence
+	rules := []models.ClassificationRuleRequest{
+		{
+			Name: "invoices", MatchType: models.RuleMatchKeyword, Pattern: "invoice, receipt",
+			Category: "admin", Urgency: models.PriorityHigh, Importance: models.PriorityMedium,
+			Queue: models.QueueAction, Position: 1,
+		},
+		{
+			Name: "claims", MatchType: models.RuleMatchRegex, Pattern: `(?i)claim #\d+`,
+			Category: "claim", Urgency: models.PriorityMedium, Importance: models.PriorityHigh,
+			Queue: models.QueueReference, Position: 2,
+		},
+	}
+	for _, rule := range rules {
+		body, _ := json.Marshal(rule)
+		req, _ := http.NewRequest("POST", "/api/v1/ingest/rules", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Failed to create rule: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name             string
+		body             string
+		expectedRule     string
+		expectedCategory string
+		expectedUrgency  models.Priority
+		expectedQueue    models.QueueType
+	}{
+		{
+			name:             "keyword rule fills all fields",
+			body:             `{"description": "Pay the plumber INVOICE"}`,
+			expectedRule:     "invoices",
+			expectedCategory: "admin",
+			expectedUrgency:  models.PriorityHigh,
+			expectedQueue:    models.QueueAction,
+		},
+		{
+			name:             "regex rule fills all fields",
+			body:             `{"description": "Follow up on Claim #4411"}`,
+			expectedRule:     "claims",
+			expectedCategory: "claim",
+			expectedUrgency:  models.PriorityMedium,
+			expectedQueue:    models.QueueReference,
+		},
+		{
+			name:             "explicit fields win over rule",
+			body:             `{"description": "Scan receipt", "category": "home", "urgency": "low"}`,
+			expectedRule:     "invoices",
+			expectedCategory: "home",
+			expectedUrgency:  models.PriorityLow,
+			expectedQueue:    models.QueueAction,
+		},
+		{
+			name:             "no rule falls back to defaults",
+			body:             `{"description": "Call grandma"}`,
+			expectedRule:     "",
+			expectedCategory: services.DefaultTaskCategory,
+			expectedUrgency:  services.DefaultTaskUrgency,
+			expectedQueue:    services.DefaultTaskQueue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/api/v1/ingest/task", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+			}
+
+			var resp models.IngestResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Classification == nil {
+				t.Fatal("Expected classification in response")
+			}
+			if resp.Classification.RuleName != tt.expectedRule {
+				t.Errorf("Expected rule '%s', got '%s'", tt.expectedRule, resp.Classification.RuleName)
+			}
+			if resp.Classification.Category != tt.expectedCategory {
+				t.Errorf("Expected category '%s', got '%s'", tt.expectedCategory, resp.Classification.Category)
+			}
+			if resp.Classification.Urgency != tt.expectedUrgency {
+				t.Errorf("Expected urgency '%s', got '%s'", tt.expectedUrgency, resp.Classification.Urgency)
+			}
+			if resp.Classification.Queue != tt.expectedQueue {
+				t.Errorf("Expected queue '%s', got '%s'", tt.expectedQueue, resp.Classification.Queue)
+			}
+		})
+	}
+}
+
+// TestCreateRuleValidation tests that broken patterns are rejected up front
+func TestCreateRuleValidation(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupIngestRouter(db)
+
+	body := `{"name": "broken", "match_type": "regex", "pattern": "([", "category": "x",
+	          "urgency": "low", "importance": "low", "queue": "action"}`
+	req, _ := http.NewRequest("POST", "/api/v1/ingest/rules", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,63 @@
+// Package handlers contains HTTP request handlers for the Human OS Cognitive API.
+// Lesson handlers close the feedback loop on resurfaced lessons. When focus,
+// threads or loops start, related archived lessons are shown; rating them
+// teaches the system which lessons are actually worth surfacing again.
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// LessonHandler handles lesson-related endpoints
+type LessonHandler struct {
+	lessons *services.LessonService
+}
+
+// NewLessonHandler creates a new lesson handler
+func NewLessonHandler(lessons *services.LessonService) *LessonHandler {
+	return &LessonHandler{lessons: lessons}
+}
+
+// RateLesson handles POST /api/v1/lessons/:id/rate
+// The ID is the resurfacing_id returned with a lesson. Useful lessons rank
+// higher the next time related work starts; unhelpful ones fade.
+func (h *LessonHandler) RateLesson(c *gin.Context) {
+	var req models.LessonRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	feedback, err := h.lessons.Rate(c.Param("id"), *req.Useful)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to rate lesson",
+			err.Error(),
+		))
+		return
+	}
+	if feedback == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Resurfaced lesson not found",
+			"No resurfaced lesson exists with the provided ID",
+		))
+		return
+	}
+
+	message := "Lesson marked not useful. It will rank lower next time."
+	if *req.Useful {
+		message = "Lesson marked useful. It will rank higher next time."
+	}
+
+	c.JSON(http.StatusOK, models.LessonResponse{
+		Message:   message,
+		Feedback:  feedback,
+		Timestamp: time.Now().UTC(),
+	})
+}
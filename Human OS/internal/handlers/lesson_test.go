@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// TestLessonResurfacing tests that related lessons come back when a loop is
+// authorized and that ratings change their ranking
+func TestLessonResurfacing(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	lessons := services.NewLessonService(db)
+	loopHandler := NewLoopHandler(db, services.NewArchiver(db, false), lessons)
+	lessonHandler := NewLessonHandler(lessons)
+	router.POST("/api/v1/loop/authorize", loopHandler.AuthorizeLoop)
+	router.POST("/api/v1/lessons/:id/rate", lessonHandler.RateLesson)
+
+	archives := []models.Archive{
+		{ID: "a1", Object: "Contractor siding claim", Summary: "Settled", Lesson: "Get contractor quotes in writing"},
+		{ID: "a2", Object: "Roof contractor", Summary: "Repaired", Lesson: "Check contractor license first"},
+		{ID: "a3", Object: "Quarterly report", Summary: "Shipped", Lesson: "Start early"},
+		{ID: "a4", Object: "Contractor fence", Summary: "No lesson recorded"},
+	}
+	for i := range archives {
+		archives[i].CreatedAt = time.Now().UTC()
+		if err := db.CreateArchive(&archives[i]); err != nil {
+			t.Fatalf("Failed to create archive: %v", err)
+		}
+	}
+
+	authorize := func() models.LoopResponse {
+		body, _ := json.Marshal(models.LoopAuthorizeRequest{
+			Description: "Call the contractor about the deck",
+			Priority:    models.PriorityMedium,
+			Queue:       models.QueueAction,
+			Owner:       "me",
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/loop/authorize", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Failed to authorize loop: %s", w.Body.String())
+		}
+		var resp models.LoopResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	first := authorize()
+	if len(first.Lessons) != 2 {
+		t.Fatalf("Expected 2 contractor lessons (archives without lessons excluded), got %d", len(first.Lessons))
+	}
+
+	// Rate whichever lesson ranked second as useful several times and the
+	// first as not useful; the order should flip
+	top, second := first.Lessons[0], first.Lessons[1]
+	rate := func(id string, useful bool) {
+		body, _ := json.Marshal(map[string]bool{"useful": useful})
+		req, _ := http.NewRequest("POST", "/api/v1/lessons/"+id+"/rate", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Failed to rate lesson: %s", w.Body.String())
+		}
+	}
+	rate(top.ResurfacingID, false)
+	rate(second.ResurfacingID, true)
+
+	again := authorize()
+	if len(again.Lessons) == 0 || again.Lessons[0].ArchiveID != second.ArchiveID {
+		t.Errorf("Expected rated-useful lesson %s to rank first, got %+v", second.ArchiveID, again.Lessons)
+	}
+
+	t.Run("rate unknown resurfacing", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v1/lessons/missing/rate", bytes.NewBufferString(`{"useful": true}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
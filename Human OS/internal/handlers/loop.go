@@ -12,28 +12,40 @@ import (
 
 	"humanos-api/internal/database"
 	"humanos-api/internal/models"
+	"humanos-api/internal/services"
 )
 
 // LoopHandler handles loop-related endpoints
 type LoopHandler struct {
-	db *database.DB
+	db       *database.DB
+	archiver *services.Archiver
+	lessons  *services.LessonService
 }
 
 // NewLoopHandler creates a new loop handler
-func NewLoopHandler(db *database.DB) *LoopHandler {
-	return &LoopHandler{db: db}
+func NewLoopHandler(db *database.DB, archiver *services.Archiver, lessons *services.LessonService) *LoopHandler {
+	return &LoopHandler{
+		db:       db,
+		archiver: archiver,
+		lessons:  lessons,
+	}
 }
 
 // AuthorizeLoop handles POST /api/v1/loop/authorize
 // Authorizing a loop means formally acknowledging it and deciding where it
 // belongs. This is the first step in GTD-style capture: you recognize the
 // open loop and place it in the appropriate queue (action, reference, or backburner).
+// Lessons from related archived work are returned alongside the new loop.
 func (h *LoopHandler) AuthorizeLoop(c *gin.Context) {
 	var req models.LoopAuthorizeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
 		return
 	}
+	goalID, ok := resolveGoal(c, h.db, req.GoalID)
+	if !ok {
+		return
+	}
 
 	now := time.Now().UTC()
 	loop := &models.Loop{
@@ -43,6 +55,7 @@ func (h *LoopHandler) AuthorizeLoop(c *gin.Context) {
 		Queue:       req.Queue,
 		Owner:       req.Owner,
 		Status:      "open",
+		GoalID:      goalID,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -55,10 +68,14 @@ func (h *LoopHandler) AuthorizeLoop(c *gin.Context) {
 		return
 	}
 
+	// Resurfacing is best-effort; it must never block authorizing the loop
+	lessons, _ := h.lessons.Resurface(models.LessonContextLoop, loop.ID, loop.Description)
+
 	c.JSON(http.StatusCreated, models.LoopResponse{
 		Message:   "Loop authorized and tracked. Cognitive load acknowledged.",
 		LoopID:    loop.ID,
 		Loop:      loop,
+		Lessons:   lessons,
 		Timestamp: now,
 	})
 }
@@ -68,6 +85,7 @@ func (h *LoopHandler) AuthorizeLoop(c *gin.Context) {
 // - "done": The commitment is fulfilled
 // - "paused": Intentionally set aside with a clear next step
 // - "abandoned": Deliberately dropped (this is valid and healthy!)
+// Loops closed as "done" are archived automatically unless disabled.
 func (h *LoopHandler) CloseLoop(c *gin.Context) {
 	var req models.LoopCloseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -118,11 +136,25 @@ func (h *LoopHandler) CloseLoop(c *gin.Context) {
 		message = "Loop ABANDONED - consciously dropped. This is a valid choice."
 	}
 
-	c.JSON(http.StatusOK, models.LoopResponse{
+	resp := models.LoopResponse{
 		Message:   message,
 		LoopID:    req.LoopID,
 		Timestamp: time.Now().UTC(),
-	})
+	}
+
+	if req.ClosureType == models.ClosureDone && h.archiver.ShouldArchive(req.Archive) {
+		archive, err := h.archiver.ArchiveLoop(loop, req.NextStep)
+		if err != nil {
+			// The loop is closed either way; report the archive failure without failing the close
+			resp.Message += " Automatic archive failed: " + err.Error()
+		} else {
+			resp.ArchiveID = archive.ID
+			resp.LessonPrompt = services.LessonPrompt(archive.ID)
+			resp.Message += " Archived."
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
 
 // KillLoop handles DELETE /api/v1/loop/kill
@@ -1,35 +1,52 @@
 // Package handlers contains HTTP request handlers for the Human OS Cognitive API.
 // Mode handlers manage system-wide resets and recovery. Soft reset clears
 // active state while preserving history; hard reset is a complete wipe.
-// These are essential for getting "unstuck" and starting fresh.
+// These are essential for getting "unstuck" and starting fresh. Every reset
+// is snapshotted first, so a fresh start that went too far can be undone.
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"humanos-api/internal/database"
 	"humanos-api/internal/models"
+	"humanos-api/internal/services"
 )
 
 // ModeHandler handles mode/reset-related endpoints
 type ModeHandler struct {
-	db *database.DB
+	db     *database.DB
+	resets *services.ResetService
 }
 
 // NewModeHandler creates a new mode handler
-func NewModeHandler(db *database.DB) *ModeHandler {
-	return &ModeHandler{db: db}
+func NewModeHandler(db *database.DB, resets *services.ResetService) *ModeHandler {
+	return &ModeHandler{db: db, resets: resets}
 }
 
 // SoftReset handles POST /api/v1/mode/reset-soft
-// A soft reset clears active cognitive state (focus, active threads,
-// running predictions) while preserving historical data (archives, closed loops).
-// Use this for a "fresh start" to the day without losing accumulated wisdom.
+// A soft reset clears active cognitive state (focus, open loops, active
+// threads, running predictions) while preserving historical data (archives,
+// closed loops). Use this for a "fresh start" to the day without losing
+// accumulated wisdom. The optional body scopes the reset to some domains,
+// loop queues or thread modes and can keep high-priority loops open; with
+// dry_run the response lists what would change and nothing is touched.
 func (h *ModeHandler) SoftReset(c *gin.Context) {
-	if err := h.db.SoftReset(); err != nil {
+	var req models.SoftResetRequest
+	// The body is optional; an empty request resets everything
+	if err := bindOptionalJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	now := time.Now().UTC()
+	changes, snapshot, err := h.resets.SoftReset(req, now)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			"Failed to perform soft reset",
 			err.Error(),
@@ -37,29 +54,149 @@ func (h *ModeHandler) SoftReset(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.ResetResponse{
-		Message:   "SOFT RESET complete. Active focus cleared, threads terminated, loops closed. Archives preserved. Fresh cognitive slate.",
+	message := "SOFT RESET complete: " + services.DescribeSoftReset(changes) + ". Archives preserved. Fresh cognitive slate."
+	if req.DryRun {
+		message = "DRY RUN: nothing changed. Resetting would mean: " + services.DescribeSoftReset(changes) + "."
+	}
+	resp := models.ResetResponse{
+		Message:   message,
 		ResetType: "soft",
-		Timestamp: time.Now().UTC(),
-	})
+		DryRun:    req.DryRun,
+		Changes:   changes,
+		Timestamp: now,
+	}
+	if snapshot != nil {
+		resp.Message += " Undo with POST /api/v1/mode/restore/" + snapshot.ID + "."
+		resp.SnapshotID = snapshot.ID
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // HardReset handles POST /api/v1/mode/reset-hard
 // A hard reset wipes EVERYTHING - all state, all history, all archives.
 // This is the nuclear option. Use only when you need to start completely
 // from zero, perhaps after a major life change or system corruption.
+// It takes two calls: the first returns a confirmation token and changes
+// nothing; the second passes it back as confirm_token within five minutes.
+// All tables are snapshotted before the wipe, so even a confirmed reset can
+// be undone with POST /api/v1/mode/restore/:id.
 func (h *ModeHandler) HardReset(c *gin.Context) {
-	if err := h.db.HardReset(); err != nil {
+	var req models.HardResetRequest
+	// The body is optional; an empty request asks for a confirmation token
+	if err := bindOptionalJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	now := time.Now().UTC()
+	if req.ConfirmToken == "" {
+		token, expires := h.resets.RequestHardReset(now)
+		c.JSON(http.StatusPreconditionRequired, models.ResetResponse{
+			Message:      "HARD RESET not performed. It wipes everything; repeat the request with this confirm_token to go ahead.",
+			ResetType:    "hard",
+			ConfirmToken: token,
+			ConfirmBy:    &expires,
+			Timestamp:    now,
+		})
+		return
+	}
+
+	snapshot, confirmed, err := h.resets.HardReset(req.ConfirmToken, now)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			"Failed to perform hard reset",
 			err.Error(),
 		))
 		return
 	}
+	if !confirmed {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Invalid confirmation token",
+			"The token is unknown, expired or already used; request a new one by calling without confirm_token",
+		))
+		return
+	}
 
 	c.JSON(http.StatusOK, models.ResetResponse{
-		Message:   "HARD RESET complete. All cognitive state wiped. Total restart. Use wisely.",
-		ResetType: "hard",
+		Message:    "HARD RESET complete. All cognitive state wiped. Total restart. Undo with POST /api/v1/mode/restore/" + snapshot.ID + ".",
+		ResetType:  "hard",
+		SnapshotID: snapshot.ID,
+		Timestamp:  now,
+	})
+}
+
+// ListSnapshots handles GET /api/v1/mode/snapshots
+// Snapshots are taken automatically before every reset and restore, newest
+// first, with the number of rows copied from each table. The newest
+// SNAPSHOT_RETENTION are kept.
+func (h *ModeHandler) ListSnapshots(c *gin.Context) {
+	limit := 50
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 500 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Invalid limit",
+				"Limit must be a number between 1 and 500",
+			))
+			return
+		}
+		limit = parsed
+	}
+
+	snapshots, err := h.db.GetSnapshots(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list snapshots",
+			err.Error(),
+		))
+		return
+	}
+	if snapshots == nil {
+		snapshots = []models.StateSnapshot{}
+	}
+
+	c.JSON(http.StatusOK, models.SnapshotResponse{
+		Message:   fmt.Sprintf("%d snapshot(s).", len(snapshots)),
+		Snapshots: snapshots,
 		Timestamp: time.Now().UTC(),
 	})
 }
+
+// RestoreSnapshot handles POST /api/v1/mode/restore/:id
+// Puts every table back as it was when the snapshot was taken, undoing the
+// reset that followed it and everything done since. The current state is
+// snapshotted first, so a restore can be undone the same way.
+func (h *ModeHandler) RestoreSnapshot(c *gin.Context) {
+	snapshot, err := h.db.GetSnapshot(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to get snapshot",
+			err.Error(),
+		))
+		return
+	}
+	if snapshot == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Snapshot not found",
+			"No snapshot exists with ID "+c.Param("id"),
+		))
+		return
+	}
+
+	now := time.Now().UTC()
+	undo, err := h.resets.Restore(snapshot.ID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to restore snapshot",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SnapshotResponse{
+		Message:        "State restored to " + snapshot.CreatedAt.Format(time.RFC3339) + ". Undo with POST /api/v1/mode/restore/" + undo.ID + ".",
+		Snapshot:       snapshot,
+		UndoSnapshotID: undo.ID,
+		Timestamp:      now,
+	})
+}
@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// setupModeRouter creates a test router with reset handlers keeping the
+// newest three snapshots
+func setupModeRouter(db *database.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	handler := NewModeHandler(db, services.NewResetService(db, 3))
+
+	mode := router.Group("/api/v1/mode")
+	{
+		mode.POST("/reset-soft", handler.SoftReset)
+		mode.POST("/reset-hard", handler.HardReset)
+		mode.GET("/snapshots", handler.ListSnapshots)
+		mode.POST("/restore/:id", handler.RestoreSnapshot)
+	}
+
+	return router
+}
+
+// seedResetState creates a focus, loops in every queue and priority, a
+// thread in each mode and a running prediction
+func seedResetState(t *testing.T, db *database.DB) {
+	now := time.Now().UTC()
+	if err := db.SetFocus(&models.FocusState{
+		ID: "focus", TaskName: "Draft proposal", Duration: "50m", SuccessCriteria: "Outline done",
+		StartedAt: now, EndsAt: now.Add(50 * time.Minute), CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to set focus: %v", err)
+	}
+	for _, loop := range []models.Loop{
+		{ID: "action-high", Priority: models.PriorityHigh, Queue: models.QueueAction},
+		{ID: "action-low", Priority: models.PriorityLow, Queue: models.QueueAction},
+		{ID: "reference", Priority: models.PriorityMedium, Queue: models.QueueReference},
+		{ID: "backburner-high", Priority: models.PriorityHigh, Queue: models.QueueBackburner},
+		{ID: "backburner-low", Priority: models.PriorityLow, Queue: models.QueueBackburner},
+	} {
+		loop.Description, loop.Owner, loop.Status, loop.CreatedAt, loop.UpdatedAt = "Loop "+loop.ID, "me", "open", now, now
+		if err := db.CreateLoop(&loop); err != nil {
+			t.Fatalf("Failed to create loop: %v", err)
+		}
+	}
+	for _, thread := range []models.Thread{
+		{ID: "fg", Name: "Proposal", Mode: models.ThreadModeForeground},
+		{ID: "bg", Name: "Career direction", Mode: models.ThreadModeBackground},
+	} {
+		thread.TimeScope, thread.Status, thread.CreatedAt, thread.UpdatedAt = "week", "active", now, now
+		if err := db.CreateThread(&thread); err != nil {
+			t.Fatalf("Failed to create thread: %v", err)
+		}
+	}
+	if _, _, err := db.CreatePredictionWithinLimit(&models.Prediction{
+		ID: "pred", Scenario: "What if the client says no?", Topic: "client",
+		TimeHorizon: "1 week", Depth: "low", Status: "running", CreatedAt: now, UpdatedAt: now,
+	}, 0); err != nil {
+		t.Fatalf("Failed to create prediction: %v", err)
+	}
+}
+
+// TestScopedSoftReset tests dry runs, scoping by domain, queue and mode, and
+// protection of high-priority loops
+func TestScopedSoftReset(t *testing.T) {
+	ids := func(loops []models.Loop) []string {
+		out := []string{}
+		for _, loop := range loops {
+			out = append(out, loop.ID)
+		}
+		return out
+	}
+	reset := func(t *testing.T, router *gin.Engine, body string) (int, models.ResetResponse) {
+		req, _ := http.NewRequest("POST", "/api/v1/mode/reset-soft", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp models.ResetResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	t.Run("dry run lists changes without making them", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+		seedResetState(t, db)
+		router := setupModeRouter(db)
+
+		code, resp := reset(t, router, `{"dry_run": true, "protect_high_priority": true}`)
+		if code != http.StatusOK || !resp.DryRun || resp.Changes == nil {
+			t.Fatalf("Expected a dry run, got %d: %+v", code, resp)
+		}
+		changes := resp.Changes
+		if len(changes.Focus) != 1 || len(changes.Loops) != 3 || len(changes.ProtectedLoops) != 2 ||
+			len(changes.Threads) != 2 || len(changes.Predictions) != 1 {
+			t.Fatalf("Expected focus, 3 loops (2 protected), 2 threads and 1 prediction, got %+v", changes)
+		}
+
+		focus, _ := db.GetCurrentFocus()
+		loops, _ := db.GetOpenLoops()
+		threads, _ := db.GetActiveThreads()
+		if focus == nil || len(loops) != 5 || len(threads) != 2 {
+			t.Fatalf("Expected nothing to change on a dry run, got focus %v, %d loops, %d threads", focus, len(loops), len(threads))
+		}
+
+		// Running it for real does exactly what the dry run listed
+		code, resp = reset(t, router, `{"protect_high_priority": true}`)
+		if code != http.StatusOK || resp.DryRun {
+			t.Fatalf("Expected the reset to run, got %d: %+v", code, resp)
+		}
+		loops, _ = db.GetOpenLoops()
+		if got := ids(loops); len(got) != 2 || got[0] != "action-high" || got[1] != "backburner-high" {
+			t.Errorf("Expected only the high-priority loops left open, got %v", got)
+		}
+	})
+
+	t.Run("scoped to loops in a queue and background threads", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+		seedResetState(t, db)
+		router := setupModeRouter(db)
+
+		code, resp := reset(t, router, `{"domains": ["loops", "threads"], "loop_queues": ["backburner"], "thread_modes": ["background"]}`)
+		if code != http.StatusOK || len(resp.Changes.Focus) != 0 || len(resp.Changes.Predictions) != 0 {
+			t.Fatalf("Expected focus and predictions out of scope, got %d: %+v", code, resp)
+		}
+		if got := ids(resp.Changes.Loops); len(got) != 2 || got[0] != "backburner-high" || got[1] != "backburner-low" {
+			t.Errorf("Expected both backburner loops abandoned, got %v", got)
+		}
+
+		focus, _ := db.GetCurrentFocus()
+		loops, _ := db.GetOpenLoops()
+		threads, _ := db.GetActiveThreads()
+		running, _ := db.GetRunningPredictions()
+		if focus == nil || len(loops) != 3 || len(threads) != 1 || threads[0].ID != "fg" || len(running) != 1 {
+			t.Fatalf("Expected only backburner loops and the background thread reset, got focus %v, %d loops, threads %v, %d predictions",
+				focus, len(loops), threads, len(running))
+		}
+	})
+
+	t.Run("no body resets everything and keeps history", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+		seedResetState(t, db)
+		router := setupModeRouter(db)
+
+		code, resp := reset(t, router, "")
+		if code != http.StatusOK || len(resp.Changes.Loops) != 5 || len(resp.Changes.Threads) != 2 {
+			t.Fatalf("Expected everything reset, got %d: %+v", code, resp)
+		}
+
+		focus, _ := db.GetCurrentFocus()
+		loops, _ := db.GetOpenLoops()
+		running, _ := db.GetRunningPredictions()
+		sessions, _ := db.GetFocusSessions(time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour))
+		if focus != nil || len(loops) != 0 || len(running) != 0 {
+			t.Fatalf("Expected no focus, loops or predictions left, got focus %v, %d loops, %d predictions", focus, len(loops), len(running))
+		}
+		if len(sessions) != 1 || sessions[0].Status != "cleared" {
+			t.Errorf("Expected the focus session kept as cleared, got %+v", sessions)
+		}
+
+		code, resp = reset(t, router, "")
+		if code != http.StatusOK || len(resp.Changes.Focus) != 0 || len(resp.Changes.Loops) != 0 {
+			t.Errorf("Expected nothing left to reset, got %d: %+v", code, resp)
+		}
+	})
+
+	t.Run("every active focus session is cleared", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "humanos_test_*.db")
+		if err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		path := tmpFile.Name()
+		tmpFile.Close()
+		defer os.Remove(path)
+
+		db, err := database.New(path)
+		if err != nil {
+			t.Fatalf("Failed to create test database: %v", err)
+		}
+		seedResetState(t, db)
+		now := time.Now().UTC()
+		if err := db.SetFocus(&models.FocusState{
+			ID: "newer", TaskName: "Review budget", Duration: "25m", SuccessCriteria: "Notes sent",
+			StartedAt: now, EndsAt: now.Add(25 * time.Minute), CreatedAt: now.Add(time.Second), UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("Failed to set focus: %v", err)
+		}
+		db.Close()
+
+		// Databases written before new sessions superseded old ones can
+		// hold several active sessions
+		conn, err := sql.Open("sqlite3", path)
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		if _, err := conn.Exec(`UPDATE focus_state SET status = 'active' WHERE id = 'focus'`); err != nil {
+			t.Fatalf("Failed to reactivate focus: %v", err)
+		}
+		conn.Close()
+
+		db, err = database.New(path)
+		if err != nil {
+			t.Fatalf("Failed to reopen database: %v", err)
+		}
+		defer db.Close()
+		router := setupModeRouter(db)
+
+		code, resp := reset(t, router, `{"dry_run": true, "domains": ["focus"]}`)
+		if code != http.StatusOK || len(resp.Changes.Focus) != 2 ||
+			resp.Changes.Focus[0].ID != "newer" || resp.Changes.Focus[1].ID != "focus" {
+			t.Fatalf("Expected both active sessions listed, got %d: %+v", code, resp.Changes)
+		}
+
+		reset(t, router, `{"domains": ["focus"]}`)
+		if focus, _ := db.GetCurrentFocus(); focus != nil {
+			t.Errorf("Expected no focus left after the reset, got %+v", focus)
+		}
+	})
+
+	t.Run("unknown scope is rejected", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+		router := setupModeRouter(db)
+
+		for _, body := range []string{`{"domains": ["emotions"]}`, `{"loop_queues": ["someday"]}`, `{"thread_modes": ["idle"]}`} {
+			if code, _ := reset(t, router, body); code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", body, code)
+			}
+		}
+	})
+}
+
+// TestResetSnapshots tests that resets can be undone from their snapshots,
+// that a hard reset needs a confirmation token, and snapshot retention
+func TestResetSnapshots(t *testing.T) {
+	post := func(t *testing.T, router *gin.Engine, path, body string) (int, []byte) {
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code, w.Body.Bytes()
+	}
+
+	t.Run("soft reset is undone by restoring its snapshot", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+		seedResetState(t, db)
+		router := setupModeRouter(db)
+
+		code, body := post(t, router, "/api/v1/mode/reset-soft", "")
+		var reset models.ResetResponse
+		json.Unmarshal(body, &reset)
+		if code != http.StatusOK || reset.SnapshotID == "" {
+			t.Fatalf("Expected a snapshot ID, got %d: %s", code, body)
+		}
+
+		code, body = post(t, router, "/api/v1/mode/restore/"+reset.SnapshotID, "")
+		var restore models.SnapshotResponse
+		json.Unmarshal(body, &restore)
+		if code != http.StatusOK || restore.UndoSnapshotID == "" {
+			t.Fatalf("Expected the restore to return an undo snapshot, got %d: %s", code, body)
+		}
+
+		focus, _ := db.GetCurrentFocus()
+		loops, _ := db.GetOpenLoops()
+		threads, _ := db.GetActiveThreads()
+		running, _ := db.GetRunningPredictions()
+		if focus == nil || len(reset.Changes.Focus) != 1 || !focus.StartedAt.Equal(reset.Changes.Focus[0].StartedAt) || len(loops) != 5 || len(threads) != 2 || len(running) != 1 {
+			t.Fatalf("Expected everything back as before the reset, got focus %v, %d loops, %d threads, %d predictions",
+				focus, len(loops), len(threads), len(running))
+		}
+
+		// The restore can itself be undone
+		post(t, router, "/api/v1/mode/restore/"+restore.UndoSnapshotID, "")
+		if loops, _ := db.GetOpenLoops(); len(loops) != 0 {
+			t.Errorf("Expected undoing the restore to reset the loops again, got %d open", len(loops))
+		}
+	})
+
+	t.Run("dry run takes no snapshot", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+		router := setupModeRouter(db)
+
+		_, body := post(t, router, "/api/v1/mode/reset-soft", `{"dry_run": true}`)
+		var reset models.ResetResponse
+		json.Unmarshal(body, &reset)
+		if snapshots, _ := db.GetSnapshots(10); reset.SnapshotID != "" || len(snapshots) != 0 {
+			t.Errorf("Expected no snapshot for a dry run, got %q and %d", reset.SnapshotID, len(snapshots))
+		}
+	})
+
+	t.Run("hard reset needs a confirmation token and can be undone", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+		seedResetState(t, db)
+		router := setupModeRouter(db)
+
+		code, body := post(t, router, "/api/v1/mode/reset-hard", "")
+		var pending models.ResetResponse
+		json.Unmarshal(body, &pending)
+		if code != http.StatusPreconditionRequired || pending.ConfirmToken == "" || pending.ConfirmBy == nil {
+			t.Fatalf("Expected 428 with a confirmation token, got %d: %s", code, body)
+		}
+		if loops, _ := db.GetOpenLoops(); len(loops) != 5 {
+			t.Fatalf("Expected nothing wiped without confirmation, got %d loops", len(loops))
+		}
+
+		if code, _ := post(t, router, "/api/v1/mode/reset-hard", `{"confirm_token": "guess"}`); code != http.StatusBadRequest {
+			t.Fatalf("Expected 400 for a wrong token, got %d", code)
+		}
+
+		code, body = post(t, router, "/api/v1/mode/reset-hard", `{"confirm_token": "`+pending.ConfirmToken+`"}`)
+		var reset models.ResetResponse
+		json.Unmarshal(body, &reset)
+		if code != http.StatusOK || reset.SnapshotID == "" {
+			t.Fatalf("Expected the hard reset to run, got %d: %s", code, body)
+		}
+		if loops, _ := db.GetOpenLoops(); len(loops) != 0 {
+			t.Fatalf("Expected everything wiped, got %d loops", len(loops))
+		}
+
+		// Tokens are single-use
+		if code, _ := post(t, router, "/api/v1/mode/reset-hard", `{"confirm_token": "`+pending.ConfirmToken+`"}`); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for a reused token, got %d", code)
+		}
+
+		if code, body := post(t, router, "/api/v1/mode/restore/"+reset.SnapshotID, ""); code != http.StatusOK {
+			t.Fatalf("Expected the restore to succeed, got %d: %s", code, body)
+		}
+		focus, _ := db.GetCurrentFocus()
+		loops, _ := db.GetOpenLoops()
+		if focus == nil || len(loops) != 5 {
+			t.Errorf("Expected the wiped state back, got focus %v, %d loops", focus, len(loops))
+		}
+	})
+
+	t.Run("restore leaves the offload queue alone", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+		router := setupModeRouter(db)
+
+		now := time.Now().UTC()
+		for _, id := range []string{"claimed", "pending"} {
+			if err := db.CreateAIOffload(&models.AIOffload{
+				ID: id, TaskType: "plan", Scope: "Plan the launch", Status: "pending", CreatedAt: now, UpdatedAt: now,
+			}); err != nil {
+				t.Fatalf("Failed to create offload: %v", err)
+			}
+		}
+		_, body := post(t, router, "/api/v1/mode/reset-soft", "")
+		var reset models.ResetResponse
+		json.Unmarshal(body, &reset)
+		if reset.SnapshotID == "" {
+			t.Fatalf("Expected a snapshot ID, got %s", body)
+		}
+
+		// A worker claims an offload and another is queued after the snapshot
+		db.ClaimAIOffload("claimed", "stub", now)
+		db.CreateAIOffload(&models.AIOffload{
+			ID: "queued", TaskType: "plan", Scope: "Plan the review", Status: "pending", CreatedAt: now, UpdatedAt: now,
+		})
+
+		if code, body := post(t, router, "/api/v1/mode/restore/"+reset.SnapshotID, ""); code != http.StatusOK {
+			t.Fatalf("Expected the restore to succeed, got %d: %s", code, body)
+		}
+		for id, status := range map[string]string{"claimed": "processing", "pending": "pending", "queued": "pending"} {
+			if offload, _ := db.GetAIOffload(id); offload == nil || offload.Status != status {
+				t.Errorf("Expected offload %s still %s, got %+v", id, status, offload)
+			}
+		}
+	})
+
+	t.Run("snapshots are listed and pruned", func(t *testing.T) {
+		db, cleanup := testDB(t)
+		defer cleanup()
+		seedResetState(t, db)
+		router := setupModeRouter(db)
+
+		var first string
+		for i := 0; i < 5; i++ {
+			_, body := post(t, router, "/api/v1/mode/reset-soft", "")
+			var reset models.ResetResponse
+			json.Unmarshal(body, &reset)
+			if i == 0 {
+				first = reset.SnapshotID
+			}
+		}
+
+		req, _ := http.NewRequest("GET", "/api/v1/mode/snapshots", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp models.SnapshotResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || len(resp.Snapshots) != 3 {
+			t.Fatalf("Expected the newest 3 snapshots kept, got %d: %s", w.Code, w.Body.String())
+		}
+		if resp.Snapshots[0].Reason != "soft_reset" || resp.Snapshots[0].Tables["loops"] != 5 {
+			t.Errorf("Expected a soft reset snapshot copying 5 loops, got %+v", resp.Snapshots[0])
+		}
+
+		if code, _ := post(t, router, "/api/v1/mode/restore/"+first, ""); code != http.StatusNotFound {
+			t.Errorf("Expected 404 for a pruned snapshot, got %d", code)
+		}
+	})
+}
@@ -13,22 +13,28 @@ import (
 
 	"humanos-api/internal/database"
 	"humanos-api/internal/models"
+	"humanos-api/internal/services"
 )
 
 // PredictHandler handles prediction-related endpoints
 type PredictHandler struct {
-	db *database.DB
+	db    *database.DB
+	guard *services.RuminationGuard
 }
 
 // NewPredictHandler creates a new predict handler
-func NewPredictHandler(db *database.DB) *PredictHandler {
-	return &PredictHandler{db: db}
+func NewPredictHandler(db *database.DB, guard *services.RuminationGuard) *PredictHandler {
+	return &PredictHandler{db: db, guard: guard}
 }
 
 // RunPrediction handles POST /api/v1/predict/run
 // Running a prediction initiates a scenario modeling process. Depth controls
 // how much cognitive energy to invest: "low" for quick sanity checks,
 // "medium" for normal planning, "deep" for major decisions requiring thorough analysis.
+// Forecasts can be attached up front so the prediction can be scored later.
+// The time horizon is parsed into a resolution date for due reminders.
+// Each depth has a cap on concurrent predictions and a thinking-time budget,
+// after which the prediction is stopped automatically.
 func (h *PredictHandler) RunPrediction(c *gin.Context) {
 	var req models.PredictRunRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -37,23 +43,47 @@ func (h *PredictHandler) RunPrediction(c *gin.Context) {
 	}
 
 	now := time.Now().UTC()
+	resolveBy := req.ResolveBy
+	if resolveBy != nil {
+		utc := resolveBy.UTC()
+		resolveBy = &utc
+	} else {
+		if parsed, err := services.ParseTimeHorizon(req.TimeHorizon, now); err == nil {
+			resolveBy = &parsed
+		}
+	}
+
 	prediction := &models.Prediction{
-		ID:          uuid.New().String(),
-		Scenario:    req.Scenario,
-		TimeHorizon: req.TimeHorizon,
-		Depth:       req.Depth,
-		Status:      "running",
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:           uuid.New().String(),
+		Scenario:     req.Scenario,
+		Topic:        req.Topic,
+		TimeHorizon:  req.TimeHorizon,
+		ResolveBy:    resolveBy,
+		BudgetEndsAt: h.guard.BudgetEndsAt(req.Depth, req.BudgetMinutes, now),
+		Depth:        req.Depth,
+		Status:       "running",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	for _, fr := range req.Forecasts {
+		prediction.Forecasts = append(prediction.Forecasts, *newForecast(prediction.ID, fr, now))
 	}
 
-	if err := h.db.CreatePrediction(prediction); err != nil {
+	ok, reason, err := h.guard.StartPrediction(prediction)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			"Failed to start prediction",
 			err.Error(),
 		))
 		return
 	}
+	if !ok {
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			"Too many "+req.Depth+" predictions running",
+			reason,
+		))
+		return
+	}
 
 	var depthAdvice string
 	switch req.Depth {
@@ -64,6 +94,9 @@ func (h *PredictHandler) RunPrediction(c *gin.Context) {
 	default:
 		depthAdvice = "Quick scan - don't over-invest in this."
 	}
+	if resolveBy == nil {
+		depthAdvice += " Time horizon not understood - set resolve_by to get a reminder when it is due."
+	}
 
 	c.JSON(http.StatusCreated, models.PredictResponse{
 		Message:      "Prediction running: " + req.Scenario + " [" + req.TimeHorizon + "]. " + depthAdvice,
@@ -77,6 +110,7 @@ func (h *PredictHandler) RunPrediction(c *gin.Context) {
 // Stopping predictions is crucial for managing rumination and analysis paralysis.
 // Sometimes the best decision is to stop thinking about something and act
 // (or accept uncertainty). This endpoint stops predictions matching a topic.
+// Stops are recorded so topics that keep getting restarted can be flagged.
 func (h *PredictHandler) StopPrediction(c *gin.Context) {
 	var req models.PredictStopRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -101,8 +135,202 @@ func (h *PredictHandler) StopPrediction(c *gin.Context) {
 		return
 	}
 
+	now := time.Now().UTC()
+	if err := h.guard.RecordStop(req.Topic, affected, now); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to record stop",
+			err.Error(),
+		))
+		return
+	}
+
 	c.JSON(http.StatusOK, models.PredictResponse{
 		Message:   "Prediction(s) STOPPED for topic: " + req.Topic + ". Mental simulation halted.",
-		Timestamp: time.Now().UTC(),
+		Timestamp: now,
+	})
+}
+
+// GetPrediction handles GET /api/v1/predict/:id
+// Returns a prediction with its forecasts and, once resolved, what actually happened.
+func (h *PredictHandler) GetPrediction(c *gin.Context) {
+	prediction, ok := loadPrediction(c, h.db)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PredictResponse{
+		Message:      "Prediction " + prediction.Status + ": " + prediction.Scenario,
+		PredictionID: prediction.ID,
+		Prediction:   prediction,
+		Timestamp:    time.Now().UTC(),
+	})
+}
+
+// AddForecast handles POST /api/v1/predict/:id/forecasts
+// A forecast turns a vague scenario into a checkable claim with a probability.
+// Writing the number down before the outcome is known is what makes
+// calibration possible - memory quietly rewrites what we "always expected".
+func (h *PredictHandler) AddForecast(c *gin.Context) {
+	var req models.ForecastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	prediction, ok := loadPrediction(c, h.db)
+	if !ok {
+		return
+	}
+	if prediction.Status == "resolved" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Prediction already resolved",
+			"Forecasts must be made before the outcome is known",
+		))
+		return
+	}
+
+	now := time.Now().UTC()
+	forecast := newForecast(prediction.ID, req, now)
+	if err := h.db.CreateForecast(forecast); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to record forecast",
+			err.Error(),
+		))
+		return
+	}
+	prediction.Forecasts = append(prediction.Forecasts, *forecast)
+
+	c.JSON(http.StatusCreated, models.PredictResponse{
+		Message:      "Forecast recorded. It will be scored when the prediction resolves.",
+		PredictionID: prediction.ID,
+		Prediction:   prediction,
+		Timestamp:    now,
 	})
 }
+
+// ResolvePrediction handles POST /api/v1/predict/:id/resolve
+// Once the time horizon has passed, record what actually happened and which
+// forecasts came true. Stopped predictions can be resolved too - the outcome
+// arrives whether or not we kept simulating it.
+func (h *PredictHandler) ResolvePrediction(c *gin.Context) {
+	var req models.PredictResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	prediction, ok := loadPrediction(c, h.db)
+	if !ok {
+		return
+	}
+	if prediction.Status == "resolved" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Prediction already resolved",
+			"This prediction's outcome has already been recorded",
+		))
+		return
+	}
+
+	known := make(map[string]bool, len(prediction.Forecasts))
+	for _, f := range prediction.Forecasts {
+		known[f.ID] = true
+	}
+	occurred := make(map[string]bool, len(req.Forecasts))
+	for _, outcome := range req.Forecasts {
+		if !known[outcome.ForecastID] {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Unknown forecast",
+				"Forecast "+outcome.ForecastID+" does not belong to this prediction",
+			))
+			return
+		}
+		occurred[outcome.ForecastID] = *outcome.Occurred
+	}
+
+	if err := h.db.ResolvePrediction(prediction.ID, req.Outcome, occurred, time.Now().UTC()); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to resolve prediction",
+			err.Error(),
+		))
+		return
+	}
+
+	resolved, err := h.db.GetPrediction(prediction.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to load resolved prediction",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PredictResponse{
+		Message:      "Prediction RESOLVED. Outcome recorded for calibration.",
+		PredictionID: resolved.ID,
+		Prediction:   resolved,
+		Timestamp:    time.Now().UTC(),
+	})
+}
+
+// DuePredictions handles GET /api/v1/predictions/due
+// Lists predictions whose time horizon has passed but whose outcome has not
+// been recorded. Closing the loop on a prediction is what makes it useful:
+// without the outcome there is nothing to learn from.
+func (h *PredictHandler) DuePredictions(c *gin.Context) {
+	now := time.Now().UTC()
+	predictions, err := h.db.GetDuePredictions(now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list due predictions",
+			err.Error(),
+		))
+		return
+	}
+	if predictions == nil {
+		predictions = []models.Prediction{}
+	}
+
+	message := "No predictions awaiting resolution."
+	if len(predictions) > 0 {
+		message = "Predictions past their horizon. Record what actually happened."
+	}
+
+	c.JSON(http.StatusOK, models.PredictionListResponse{
+		Message:     message,
+		Count:       len(predictions),
+		Predictions: predictions,
+		Timestamp:   now,
+	})
+}
+
+// loadPrediction loads the prediction named by the :id path parameter,
+// writing the error response itself when it cannot
+func loadPrediction(c *gin.Context, db *database.DB) (*models.Prediction, bool) {
+	prediction, err := db.GetPrediction(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to find prediction",
+			err.Error(),
+		))
+		return nil, false
+	}
+	if prediction == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Prediction not found",
+			"No prediction exists with the provided ID",
+		))
+		return nil, false
+	}
+	return prediction, true
+}
+
+// newForecast builds a forecast record from a request
+func newForecast(predictionID string, req models.ForecastRequest, now time.Time) *models.Forecast {
+	return &models.Forecast{
+		ID:           uuid.New().String(),
+		PredictionID: predictionID,
+		Statement:    req.Statement,
+		Probability:  *req.Probability,
+		CreatedAt:    now,
+	}
+}
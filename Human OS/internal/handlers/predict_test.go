@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// setupPredictRouter creates a test router with prediction and report routes
+func setupPredictRouter(db *database.DB, policy services.RuminationPolicy) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	guard := services.NewRuminationGuard(db, policy)
+	predictHandler := NewPredictHandler(db, guard)
+	emotions := services.NewEmotionService(db, 4*time.Hour)
+	reportHandler := NewReportHandler(
+		services.NewReportService(db, emotions),
+		guard,
+		services.NewTriggerService(db, emotions),
+	)
+	router.POST("/api/v1/predict/run", predictHandler.RunPrediction)
+	router.DELETE("/api/v1/predict/stop", predictHandler.StopPrediction)
+	router.GET("/api/v1/predict/:id", predictHandler.GetPrediction)
+	router.POST("/api/v1/predict/:id/forecasts", predictHandler.AddForecast)
+	router.POST("/api/v1/predict/:id/resolve", predictHandler.ResolvePrediction)
+	router.GET("/api/v1/reports/calibration", reportHandler.Calibration)
+	router.GET("/api/v1/predictions/due", predictHandler.DuePredictions)
+	router.GET("/api/v1/reports/rumination", reportHandler.Rumination)
+
+	return router
+}
+
+// TestPredictionCalibration tests forecasting, resolving and scoring a prediction
+func TestPredictionCalibration(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupPredictRouter(db, services.RuminationPolicy{})
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/v1/predict/run", `{
+		"scenario": "Vendor delivers the migration",
+		"topic": "vendor",
+		"time_horizon": "2 weeks",
+		"depth": "deep",
+		"forecasts": [{"statement": "Delivered on time", "probability": 0.8}]
+	}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to run prediction: %s", w.Body.String())
+	}
+	var run models.PredictResponse
+	json.Unmarshal(w.Body.Bytes(), &run)
+	id := run.PredictionID
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"valid forecast", `{"statement": "Under budget", "probability": 0.3}`, http.StatusCreated},
+		{"probability above 1", `{"statement": "Impossible", "probability": 1.5}`, http.StatusBadRequest},
+		{"missing probability", `{"statement": "Vague"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send("POST", "/api/v1/predict/"+id+"/forecasts", tt.body)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w = send("GET", "/api/v1/predict/"+id, "")
+	var got models.PredictResponse
+	json.Unmarshal(w.Body.Bytes(), &got)
+	if len(got.Prediction.Forecasts) != 2 {
+		t.Fatalf("Expected 2 forecasts, got %d", len(got.Prediction.Forecasts))
+	}
+	onTime, underBudget := got.Prediction.Forecasts[0].ID, got.Prediction.Forecasts[1].ID
+
+	t.Run("unknown forecast", func(t *testing.T) {
+		w := send("POST", "/api/v1/predict/"+id+"/resolve",
+			`{"outcome": "x", "forecasts": [{"forecast_id": "nope", "occurred": true}]}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	w = send("POST", "/api/v1/predict/"+id+"/resolve", `{
+		"outcome": "Shipped on time, 20% over budget",
+		"forecasts": [
+			{"forecast_id": "`+onTime+`", "occurred": true},
+			{"forecast_id": "`+underBudget+`", "occurred": false}
+		]
+	}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to resolve prediction: %s", w.Body.String())
+	}
+	var resolved models.PredictResponse
+	json.Unmarshal(w.Body.Bytes(), &resolved)
+	if resolved.Prediction.Status != "resolved" || resolved.Prediction.ResolvedAt == nil {
+		t.Errorf("Expected resolved prediction, got %+v", resolved.Prediction)
+	}
+
+	t.Run("resolve twice", func(t *testing.T) {
+		w := send("POST", "/api/v1/predict/"+id+"/resolve", `{"outcome": "again"}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	w = send("GET", "/api/v1/reports/calibration", "")
+	var report models.CalibrationReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+
+	// (0.8-1)^2 = 0.04 and (0.3-0)^2 = 0.09, so the mean is 0.065
+	if report.Overall.Forecasts != 2 || math.Abs(report.Overall.BrierScore-0.065) > 1e-9 {
+		t.Errorf("Expected 2 forecasts with Brier 0.065, got %+v", report.Overall)
+	}
+	if report.ByDepth["deep"].Forecasts != 2 || report.ByTopic["vendor"].Forecasts != 2 {
+		t.Errorf("Expected forecasts grouped by depth and topic, got %+v / %+v", report.ByDepth, report.ByTopic)
+	}
+	if len(report.Curve) != 2 {
+		t.Errorf("Expected 2 populated calibration bins, got %d", len(report.Curve))
+	}
+}
+
+// TestDuePredictions tests horizon parsing, the due list and auto-stopping
+// of unresolved low-depth predictions
+func TestDuePredictions(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupPredictRouter(db, services.RuminationPolicy{})
+
+	run := func(body string) *models.Prediction {
+		req, _ := http.NewRequest("POST", "/api/v1/predict/run", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Failed to run prediction: %s", w.Body.String())
+		}
+		var resp models.PredictResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.Prediction
+	}
+
+	horizons := []struct {
+		horizon  string
+		expected time.Duration
+	}{
+		{"2 weeks", 14 * 24 * time.Hour},
+		{"24h", 24 * time.Hour},
+		{"in three days", 3 * 24 * time.Hour},
+		{"3-5 days", 5 * 24 * time.Hour},
+	}
+	for _, tt := range horizons {
+		t.Run(tt.horizon, func(t *testing.T) {
+			pred := run(`{"scenario": "s", "time_horizon": "` + tt.horizon + `", "depth": "medium"}`)
+			if pred.ResolveBy == nil {
+				t.Fatalf("Expected %q to parse", tt.horizon)
+			}
+			if got := pred.ResolveBy.Sub(pred.CreatedAt); got != tt.expected {
+				t.Errorf("Expected horizon %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if pred := run(`{"scenario": "s", "time_horizon": "someday", "depth": "medium"}`); pred.ResolveBy != nil {
+		t.Errorf("Expected unparseable horizon to leave resolve_by unset, got %v", pred.ResolveBy)
+	}
+
+	past := time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339)
+	low := run(`{"scenario": "Quick check", "time_horizon": "1 day", "depth": "low", "resolve_by": "` + past + `"}`)
+	deep := run(`{"scenario": "Big bet", "time_horizon": "1 day", "depth": "deep", "resolve_by": "` + past + `"}`)
+
+	req, _ := http.NewRequest("GET", "/api/v1/predictions/due", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var due models.PredictionListResponse
+	json.Unmarshal(w.Body.Bytes(), &due)
+	if due.Count != 2 {
+		t.Fatalf("Expected 2 due predictions, got %d", due.Count)
+	}
+
+	scheduler := services.NewPredictionScheduler(db, time.Minute, 24*time.Hour)
+	if _, stopped := scheduler.RunOnce(time.Now().UTC()); stopped != 1 {
+		t.Errorf("Expected 1 low-depth prediction auto-stopped, got %d", stopped)
+	}
+	for id, expected := range map[string]string{low.ID: "stopped", deep.ID: "running"} {
+		pred, _ := db.GetPrediction(id)
+		if pred.Status != expected {
+			t.Errorf("Expected prediction %s to be %s, got %s", id, expected, pred.Status)
+		}
+	}
+}
+
+// TestRuminationGuardConcurrentStarts tests that simultaneous requests
+// cannot start more predictions than a depth's cap
+func TestRuminationGuardConcurrentStarts(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupPredictRouter(db, services.RuminationPolicy{MaxRunning: map[string]int{"medium": 2}})
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("POST", "/api/v1/predict/run",
+				bytes.NewBufferString(`{"scenario": "Relocation", "time_horizon": "1 month", "depth": "medium"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			codes <- w.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	started := 0
+	for code := range codes {
+		switch code {
+		case http.StatusCreated:
+			started++
+		case http.StatusConflict:
+		default:
+			t.Errorf("Expected status 201 or 409, got %d", code)
+		}
+	}
+	if started != 2 {
+		t.Errorf("Expected exactly 2 predictions started, got %d", started)
+	}
+}
+
+// TestRuminationGuard tests depth caps, time budgets and restart flagging
+func TestRuminationGuard(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupPredictRouter(db, services.RuminationPolicy{
+		MaxRunning:       map[string]int{"deep": 1},
+		Budgets:          map[string]time.Duration{"low": 30 * time.Minute},
+		RestartThreshold: 2,
+		Window:           7 * 24 * time.Hour,
+	})
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("depth cap", func(t *testing.T) {
+		if w := send("POST", "/api/v1/predict/run", `{"scenario": "Reorg", "time_horizon": "1 month", "depth": "deep"}`); w.Code != http.StatusCreated {
+			t.Fatalf("Expected first deep prediction to start, got %d", w.Code)
+		}
+		if w := send("POST", "/api/v1/predict/run", `{"scenario": "Merger", "time_horizon": "1 month", "depth": "deep"}`); w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 for second deep prediction, got %d", w.Code)
+		}
+	})
+
+	t.Run("time budget", func(t *testing.T) {
+		w := send("POST", "/api/v1/predict/run", `{"scenario": "Lunch", "time_horizon": "today", "depth": "low"}`)
+		var resp models.PredictResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		pred := resp.Prediction
+		if pred.BudgetEndsAt == nil || pred.BudgetEndsAt.Sub(pred.CreatedAt) != 30*time.Minute {
+			t.Fatalf("Expected 30 minute budget, got %v", pred.BudgetEndsAt)
+		}
+
+		scheduler := services.NewPredictionScheduler(db, time.Minute, 24*time.Hour)
+		scheduler.RunOnce(pred.CreatedAt.Add(31 * time.Minute))
+		if stored, _ := db.GetPrediction(pred.ID); stored.Status != "stopped" {
+			t.Errorf("Expected prediction over budget to be stopped, got %s", stored.Status)
+		}
+	})
+
+	// Stop a topic, then restart it twice
+	send("POST", "/api/v1/predict/run", `{"scenario": "What will the boss think of the email", "time_horizon": "1 day", "depth": "medium"}`)
+	if w := send("DELETE", "/api/v1/predict/stop", `{"topic": "boss"}`); w.Code != http.StatusOK {
+		t.Fatalf("Failed to stop prediction: %s", w.Body.String())
+	}
+	for i := 0; i < 2; i++ {
+		send("POST", "/api/v1/predict/run", `{"scenario": "Maybe the boss hated it", "time_horizon": "1 day", "depth": "medium"}`)
+	}
+
+	w := send("GET", "/api/v1/reports/rumination", "")
+	var report models.RuminationReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if len(report.Topics) != 1 || report.Topics[0].Restarts != 2 || !report.Topics[0].Flagged {
+		t.Errorf("Expected boss topic flagged with 2 restarts, got %+v", report.Topics)
+	}
+}
@@ -0,0 +1,58 @@
+// Package handlers contains HTTP request handlers for the Human OS Cognitive API.
+// Recommendation handlers turn the current cognitive and emotional state into
+// one concrete next action. Knowing you are overloaded is not the same as
+// knowing what to do about it; this endpoint answers the second question.
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// RecommendationHandler handles recommendation endpoints
+type RecommendationHandler struct {
+	recommendations *services.RecommendationService
+}
+
+// NewRecommendationHandler creates a new recommendation handler
+func NewRecommendationHandler(recommendations *services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{recommendations: recommendations}
+}
+
+// GetRecommendations handles GET /api/v1/recommendations?tz=Europe/Berlin
+// Weighs emotional load, energy, open loops, running predictions, time of day
+// and which decompression methods have actually reduced load, and returns the
+// most urgent next action with the endpoint that carries it out. The tz
+// parameter overrides the configured time zone for judging time of day.
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	loc := h.recommendations.Location()
+	if tz := c.Query("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid time zone", err.Error()))
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	recs, ctx, err := h.recommendations.Recommend(now, loc, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to build recommendations",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecommendationResponse{
+		Recommendation: &recs[0],
+		Alternatives:   recs[1:],
+		Context:        ctx,
+		Timestamp:      now,
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// TestRecommendations tests that recommendations follow emotional load,
+// prefer the decompression method that has worked, and drop decompression
+// while a session is running
+func TestRecommendations(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupEmotionRouter(db)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	recommend := func() models.RecommendationResponse {
+		w := send("GET", "/api/v1/recommendations", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp models.RecommendationResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+	actions := func(resp models.RecommendationResponse) map[models.RecommendationAction]models.Recommendation {
+		all := map[models.RecommendationAction]models.Recommendation{}
+		for _, rec := range append([]models.Recommendation{*resp.Recommendation}, resp.Alternatives...) {
+			all[rec.Action] = rec
+		}
+		return all
+	}
+
+	// Two days ago: a walk that helped and music that did not
+	past := time.Now().UTC().Add(-48 * time.Hour)
+	measured := func(method, preLabel string, preIntensity int, postLabel string, postIntensity int) {
+		pre := &models.EmotionalState{ID: method + "-pre", Label: preLabel, Intensity: &preIntensity, CreatedAt: past}
+		post := &models.EmotionalState{ID: method + "-post", Label: postLabel, Intensity: &postIntensity, CreatedAt: past.Add(20 * time.Minute)}
+		db.CreateDecompressSession(&models.DecompressSession{
+			ID: method, Method: method, Duration: "20m", Status: "active",
+			StartedAt: past, EndsAt: past.Add(20 * time.Minute), PreCheckIn: pre, CreatedAt: past,
+		})
+		db.EndDecompressSession(method, "completed", past.Add(20*time.Minute))
+		db.SetDecompressPostCheckIn(method, post)
+	}
+	measured("walk", "angry", 9, "calm", 2)
+	measured("music", "anxious", 6, "anxious", 6)
+
+	t.Run("invalid time zone", func(t *testing.T) {
+		if w := send("GET", "/api/v1/recommendations?tz=Mars/Olympus", ""); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("low load", func(t *testing.T) {
+		resp := recommend()
+		if resp.Recommendation == nil || resp.Context.EmotionalLoad != models.LoadLevelLow {
+			t.Fatalf("Expected a recommendation at low load, got %+v", resp)
+		}
+		if _, ok := actions(resp)[models.ActionDecompress]; ok {
+			t.Errorf("Expected no decompression at low load, got %+v", resp)
+		}
+	})
+
+	t.Run("tag recommends best method", func(t *testing.T) {
+		send("POST", "/api/v1/emotion/tag", `{"label": "stressed", "source_guess": "deadline", "intensity": 10}`)
+		w := send("POST", "/api/v1/emotion/tag", `{"label": "angry", "source_guess": "review", "intensity": 10}`)
+		var resp models.EmotionResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Recommendation == nil || !strings.Contains(resp.Message, "Next: "+resp.Recommendation.Title) {
+			t.Fatalf("Expected tag response to carry a recommendation, got %+v", resp)
+		}
+
+		// Tagging angry boosts decompression above everything else at high load
+		rec := resp.Recommendation
+		if rec.Action != models.ActionDecompress || rec.Params["method"] != "walk" || rec.Params["duration"] == "" {
+			t.Errorf("Expected decompression by walk, got %+v", rec)
+		}
+	})
+
+	t.Run("method insight", func(t *testing.T) {
+		w := send("POST", "/api/v1/emotion/decompress", `{"method": "music", "duration": "10m"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp models.EmotionResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if !strings.Contains(resp.Message, "have not reduced") || !strings.Contains(resp.Message, "walk has worked better") {
+			t.Errorf("Expected music history and walk suggestion, got %q", resp.Message)
+		}
+	})
+
+	t.Run("no decompression while decompressing", func(t *testing.T) {
+		db.CreatePredictionWithinLimit(&models.Prediction{
+			ID: "pred", Scenario: "What if the launch slips?", Topic: "launch",
+			TimeHorizon: "1 week", Depth: "medium", Status: "running",
+			CreatedAt: time.Now().UTC().Add(-3 * time.Hour), UpdatedAt: time.Now().UTC(),
+		}, 0)
+
+		resp := recommend()
+		if !resp.Context.Decompressing || resp.Context.EmotionalLoad != models.LoadLevelHigh {
+			t.Fatalf("Expected high load while decompressing, got %+v", resp.Context)
+		}
+		all := actions(resp)
+		if _, ok := all[models.ActionDecompress]; ok {
+			t.Errorf("Expected no decompression during a session, got %+v", resp)
+		}
+		if stop, ok := all[models.ActionStopPrediction]; !ok || stop.Params["topic"] != "launch" {
+			t.Errorf("Expected to stop the launch prediction, got %+v", resp)
+		}
+	})
+}
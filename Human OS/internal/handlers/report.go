@@ -0,0 +1,156 @@
+// Package handlers contains HTTP request handlers for the Human OS Cognitive API.
+// Report handlers step back from day-to-day operation and assemble periodic
+// reviews from the data the other endpoints record. A retro turns a week of
+// loops, focus sessions and emotions into something you can learn from.
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// ReportHandler handles report-related endpoints
+type ReportHandler struct {
+	reports  *services.ReportService
+	guard    *services.RuminationGuard
+	triggers *services.TriggerService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reports *services.ReportService, guard *services.RuminationGuard, triggers *services.TriggerService) *ReportHandler {
+	return &ReportHandler{reports: reports, guard: guard, triggers: triggers}
+}
+
+// Retro handles GET /api/v1/reports/retro?period=week|month&format=json|markdown
+// The retrospective covers loops opened vs closed, focus time, emotional tags,
+// archived lessons, abandoned predictions and AI offloads for the period.
+// JSON is the default; format=markdown renders the Daily Ops Check layout.
+func (h *ReportHandler) Retro(c *gin.Context) {
+	period := models.ReportPeriod(c.DefaultQuery("period", string(models.ReportPeriodWeek)))
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "markdown" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Invalid format",
+			"Format must be 'json' or 'markdown'",
+		))
+		return
+	}
+
+	if _, _, err := services.ReportWindow(period, time.Now().UTC()); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid period", err.Error()))
+		return
+	}
+
+	report, err := h.reports.Retro(period, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to build retrospective",
+			err.Error(),
+		))
+		return
+	}
+
+	if format == "markdown" {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(services.RenderRetroMarkdown(report)))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Alignment handles GET /api/v1/reports/alignment?period=week|month
+// The "why am I doing this" check: focus minutes, loops and threads per
+// Telos goal, and the open work that serves no goal at all. A month of
+// focus time that lands nowhere near the goals shows up here.
+func (h *ReportHandler) Alignment(c *gin.Context) {
+	period := models.ReportPeriod(c.DefaultQuery("period", string(models.ReportPeriodWeek)))
+	if _, _, err := services.ReportWindow(period, time.Now().UTC()); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid period", err.Error()))
+		return
+	}
+
+	report, err := h.reports.Alignment(period, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to build alignment report",
+			err.Error(),
+		))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Calibration handles GET /api/v1/reports/calibration
+// Calibration compares the probabilities attached to resolved forecasts with
+// what actually happened, per prediction depth and topic. If "deep" predictions
+// score no better than "low" ones, the extra simulation time is not buying accuracy.
+func (h *ReportHandler) Calibration(c *gin.Context) {
+	report, err := h.reports.Calibration(time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to build calibration report",
+			err.Error(),
+		))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Rumination handles GET /api/v1/reports/rumination
+// Lists topics that were stopped and then started again. Coming back to a
+// topic once is normal; restarting it again and again after deciding to stop
+// is the loop the stop was meant to break, so those topics are flagged.
+func (h *ReportHandler) Rumination(c *gin.Context) {
+	report, err := h.guard.Report(time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to build rumination report",
+			err.Error(),
+		))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Decompression handles GET /api/v1/reports/decompression
+// Compares decompression methods by the drop in tagged load between the
+// check-ins before and after each session. A walk that feels virtuous but
+// leaves you just as wound up shows up here.
+func (h *ReportHandler) Decompression(c *gin.Context) {
+	report, err := h.reports.Decompression(time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to build decompression report",
+			err.Error(),
+		))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Triggers handles GET /api/v1/reports/triggers
+// Shows which people, projects and contexts emotions are blamed on, and
+// which loops and threads mentioning them were open when high-load emotions
+// were tagged. Takes the same from, to and tz parameters as emotion trends.
+func (h *ReportHandler) Triggers(c *gin.Context) {
+	from, to, _, err := parseEmotionRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid time range", err.Error()))
+		return
+	}
+
+	report, err := h.triggers.Report(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to build trigger report",
+			err.Error(),
+		))
+		return
+	}
+	report.Timestamp = time.Now().UTC()
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// setupReportRouter creates a test router with the retro report
+func setupReportRouter(db *database.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	emotions := services.NewEmotionService(db, 4*time.Hour)
+	handler := NewReportHandler(
+		services.NewReportService(db, emotions),
+		services.NewRuminationGuard(db, services.RuminationPolicy{}),
+		services.NewTriggerService(db, emotions),
+	)
+	router.GET("/api/v1/reports/retro", handler.Retro)
+
+	return router
+}
+
+// seedRetroState records a completed focus session, a loop closed as done,
+// a loop still open and an archived lesson
+func seedRetroState(t *testing.T, db *database.DB) {
+	now := time.Now().UTC()
+	if err := db.SetFocus(&models.FocusState{
+		ID: "focus", TaskName: "Write the board update", Duration: "50m", SuccessCriteria: "Sent to the board",
+		StartedAt: now.Add(-40 * time.Minute), EndsAt: now.Add(10 * time.Minute), CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to set focus: %v", err)
+	}
+	if _, err := db.CompleteFocus("focus", now.Add(-10*time.Minute)); err != nil {
+		t.Fatalf("Failed to complete focus: %v", err)
+	}
+	for _, id := range []string{"done", "open"} {
+		if err := db.CreateLoop(&models.Loop{
+			ID: id, Description: "Loop " + id, Priority: models.PriorityMedium, Queue: models.QueueAction,
+			Owner: "me", Status: "open", CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("Failed to create loop: %v", err)
+		}
+	}
+	if err := db.CloseLoop("done", models.ClosureDone, ""); err != nil {
+		t.Fatalf("Failed to close loop: %v", err)
+	}
+	if err := db.CreateArchive(&models.Archive{
+		ID: "archive", Object: "Board update", Summary: "Sent", Lesson: "Start the numbers a day early", CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+}
+
+// TestRetroReport tests the retrospective in both formats, over a period
+// with nothing recorded, and with invalid parameters
+func TestRetroReport(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	seedRetroState(t, db)
+	router := setupReportRouter(db)
+
+	empty, emptyCleanup := testDB(t)
+	defer emptyCleanup()
+	emptyRouter := setupReportRouter(empty)
+
+	tests := []struct {
+		name           string
+		router         *gin.Engine
+		query          string
+		expectedStatus int
+		check          func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "json by default",
+			router:         router,
+			query:          "",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var report models.RetroReport
+				json.Unmarshal(w.Body.Bytes(), &report)
+				if report.Period != models.ReportPeriodWeek {
+					t.Errorf("Expected period 'week', got '%s'", report.Period)
+				}
+				if report.Loops.Opened != 2 || report.Loops.Closed != 1 || report.Loops.StillOpen != 1 ||
+					report.Loops.ClosedByType[models.ClosureDone] != 1 {
+					t.Errorf("Expected 2 loops opened, 1 closed as done and 1 still open, got %+v", report.Loops)
+				}
+				if report.Focus.Sessions != 1 || report.Focus.Completed != 1 || report.Focus.TotalMinutes != 30 {
+					t.Errorf("Expected 1 completed session of 30 minutes, got %+v", report.Focus)
+				}
+				if len(report.Lessons) != 1 || report.Lessons[0].Lesson != "Start the numbers a day early" {
+					t.Errorf("Expected the archived lesson, got %+v", report.Lessons)
+				}
+			},
+		},
+		{
+			name:           "markdown",
+			router:         router,
+			query:          "?period=month&format=markdown",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/markdown") {
+					t.Errorf("Expected markdown content type, got '%s'", w.Header().Get("Content-Type"))
+				}
+				body := w.Body.String()
+				for _, want := range []string{
+					"## Monthly Retro",
+					"- Focus Time: 30m across 1 sessions (1 completed)",
+					"- Closed: 1 (done 1, paused 0, abandoned 0)",
+					"- Write the board update: Done when Sent to the board",
+					"- **Board update**: Start the numbers a day early",
+				} {
+					if !strings.Contains(body, want) {
+						t.Errorf("Expected markdown to contain %q, got:\n%s", want, body)
+					}
+				}
+			},
+		},
+		{
+			name:           "empty period",
+			router:         emptyRouter,
+			query:          "?format=markdown",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				body := w.Body.String()
+				for _, want := range []string{
+					"- Focus Time: 0m across 0 sessions (0 completed)",
+					"- No focus sessions completed",
+					"- None tagged",
+					"- No lessons archived",
+				} {
+					if !strings.Contains(body, want) {
+						t.Errorf("Expected markdown to contain %q, got:\n%s", want, body)
+					}
+				}
+			},
+		},
+		{
+			name:           "invalid period",
+			router:         router,
+			query:          "?period=year",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid format",
+			router:         router,
+			query:          "?format=pdf",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/reports/retro"+tt.query, nil)
+			w := httptest.NewRecorder()
+			tt.router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.check != nil {
+				tt.check(t, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package handlers contains HTTP request handlers for the Human OS Cognitive API.
+// Scenario handlers turn a flat prediction into a tree of branches - "if this
+// happens, then that follows, and here is what I'll do" - so planning can be
+// reviewed, pruned and compared by expected value instead of replayed in your head.
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// ScenarioHandler handles scenario tree endpoints
+type ScenarioHandler struct {
+	db *database.DB
+}
+
+// NewScenarioHandler creates a new scenario handler
+func NewScenarioHandler(db *database.DB) *ScenarioHandler {
+	return &ScenarioHandler{db: db}
+}
+
+// GetTree handles GET /api/v1/predict/:id/tree?format=json|dot
+// Returns the scenario tree with path probabilities and expected value.
+// format=dot exports Graphviz DOT for rendering (e.g. `dot -Tsvg`).
+func (h *ScenarioHandler) GetTree(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "dot" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Invalid format",
+			"Format must be 'json' or 'dot'",
+		))
+		return
+	}
+
+	tree, ok := h.loadTree(c)
+	if !ok {
+		return
+	}
+
+	if format == "dot" {
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(services.RenderScenarioDOT(tree)))
+		return
+	}
+	c.JSON(http.StatusOK, models.ScenarioTreeResponse{
+		Message:   "Scenario tree for: " + tree.Scenario,
+		Tree:      tree,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// AddBranch handles POST /api/v1/predict/:id/branches
+// Adds a branch under the scenario itself or under an existing branch.
+// Probability is relative to the parent; siblings should sum to 1.
+// Giving leaves a value lets the tree compute an expected value.
+func (h *ScenarioHandler) AddBranch(c *gin.Context) {
+	var req models.ScenarioBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	prediction, ok := loadPrediction(c, h.db)
+	if !ok {
+		return
+	}
+
+	if req.ParentID != "" {
+		branches, err := h.db.GetScenarioBranches(prediction.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				"Failed to load scenario tree",
+				err.Error(),
+			))
+			return
+		}
+		found := false
+		for _, b := range branches {
+			if b.ID == req.ParentID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"Unknown parent branch",
+				"Branch "+req.ParentID+" does not belong to this prediction",
+			))
+			return
+		}
+	}
+
+	branch := &models.ScenarioBranch{
+		ID:           uuid.New().String(),
+		PredictionID: prediction.ID,
+		ParentID:     req.ParentID,
+		Condition:    req.Condition,
+		Outcome:      req.Outcome,
+		Probability:  *req.Probability,
+		Response:     req.Response,
+		Value:        req.Value,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := h.db.CreateScenarioBranch(branch); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to add branch",
+			err.Error(),
+		))
+		return
+	}
+
+	tree, ok := h.loadTree(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusCreated, models.ScenarioTreeResponse{
+		Message:   "Branch added: " + req.Outcome,
+		BranchID:  branch.ID,
+		Tree:      tree,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// PruneBranch handles DELETE /api/v1/predict/:id/branches/:branch_id
+// Pruning removes a branch and everything below it. Cutting scenarios that
+// no longer matter is as important as adding new ones - every live branch
+// is something the mind keeps simulating.
+func (h *ScenarioHandler) PruneBranch(c *gin.Context) {
+	prediction, ok := loadPrediction(c, h.db)
+	if !ok {
+		return
+	}
+
+	removed, err := h.db.PruneScenarioBranch(prediction.ID, c.Param("branch_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to prune branch",
+			err.Error(),
+		))
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Branch not found",
+			"No branch with that ID exists in this prediction's scenario tree",
+		))
+		return
+	}
+
+	tree, ok := h.loadTree(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, models.ScenarioTreeResponse{
+		Message:   "Branch pruned. Fewer scenarios to simulate.",
+		Tree:      tree,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// loadTree builds the scenario tree for the :id prediction, writing the error
+// response itself when it cannot
+func (h *ScenarioHandler) loadTree(c *gin.Context) (*models.ScenarioTree, bool) {
+	prediction, ok := loadPrediction(c, h.db)
+	if !ok {
+		return nil, false
+	}
+
+	branches, err := h.db.GetScenarioBranches(prediction.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to load scenario tree",
+			err.Error(),
+		))
+		return nil, false
+	}
+	return services.BuildScenarioTree(prediction, branches), true
+}
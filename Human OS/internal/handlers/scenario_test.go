@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// TestScenarioTree tests building, valuing, exporting and pruning a scenario tree
+func TestScenarioTree(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	predictHandler := NewPredictHandler(db, services.NewRuminationGuard(db, services.RuminationPolicy{}))
+	scenarioHandler := NewScenarioHandler(db)
+	router.POST("/api/v1/predict/run", predictHandler.RunPrediction)
+	router.GET("/api/v1/predict/:id/tree", scenarioHandler.GetTree)
+	router.POST("/api/v1/predict/:id/branches", scenarioHandler.AddBranch)
+	router.DELETE("/api/v1/predict/:id/branches/:branch_id", scenarioHandler.PruneBranch)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/v1/predict/run", `{"scenario": "Launch in March", "time_horizon": "3 months", "depth": "deep"}`)
+	var run models.PredictResponse
+	json.Unmarshal(w.Body.Bytes(), &run)
+	base := "/api/v1/predict/" + run.PredictionID
+
+	addBranch := func(body string) models.ScenarioTreeResponse {
+		w := send("POST", base+"/branches", body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Failed to add branch: %s", w.Body.String())
+		}
+		var resp models.ScenarioTreeResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	onTime := addBranch(`{"condition": "Team stays staffed", "outcome": "Ships on time", "probability": 0.6, "value": 100}`)
+	late := addBranch(`{"condition": "Key engineer leaves", "outcome": "Slips a month", "probability": 0.4}`)
+	if late.Tree.ExpectedValue != nil {
+		t.Errorf("Expected no expected value while a leaf is unvalued")
+	}
+	addBranch(`{"parent_id": "` + late.BranchID + `", "condition": "Hire contractor", "outcome": "Recovers", "probability": 0.5, "value": 50, "response": "Cut scope"}`)
+	tree := addBranch(`{"parent_id": "` + late.BranchID + `", "condition": "No hire", "outcome": "Misses quarter", "probability": 0.5, "value": -50}`).Tree
+
+	// 0.6*100 + 0.4*0.5*50 + 0.4*0.5*-50 = 60
+	if tree.ExpectedValue == nil || math.Abs(*tree.ExpectedValue-60) > 1e-9 {
+		t.Errorf("Expected EV 60, got %v", tree.ExpectedValue)
+	}
+	if len(tree.Leaves) != 3 || len(tree.Warnings) != 0 {
+		t.Errorf("Expected 3 leaves and no warnings, got %d leaves, warnings %v", len(tree.Leaves), tree.Warnings)
+	}
+
+	t.Run("unknown parent", func(t *testing.T) {
+		w := send("POST", base+"/branches", `{"parent_id": "nope", "condition": "c", "outcome": "o", "probability": 0.5}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("dot export", func(t *testing.T) {
+		w := send("GET", base+"/tree?format=dot", "")
+		body := w.Body.String()
+		if w.Code != http.StatusOK || !strings.HasPrefix(body, "digraph scenario {") ||
+			!strings.Contains(body, `"`+onTime.BranchID+`"`) {
+			t.Errorf("Unexpected DOT export: %s", body)
+		}
+	})
+
+	w = send("DELETE", base+"/branches/"+late.BranchID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to prune branch: %s", w.Body.String())
+	}
+	var pruned models.ScenarioTreeResponse
+	json.Unmarshal(w.Body.Bytes(), &pruned)
+	if len(pruned.Tree.Branches) != 1 || len(pruned.Tree.Leaves) != 1 {
+		t.Errorf("Expected subtree removed, got %+v", pruned.Tree)
+	}
+	if len(pruned.Tree.Warnings) != 1 {
+		t.Errorf("Expected a warning that probabilities no longer sum to 1, got %v", pruned.Tree.Warnings)
+	}
+
+	t.Run("prune missing branch", func(t *testing.T) {
+		w := send("DELETE", base+"/branches/"+late.BranchID, "")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+// Package handlers contains HTTP request handlers for the Human OS Cognitive API.
+// Telos handlers import the personal context file - problems, mission, goals
+// and the rest of the chain - and trace any item back to why it matters.
+// Focus sessions, loops, threads and tasks link to its goals by ID.
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+	"humanos-api/internal/telos"
+)
+
+// TelosHandler handles Telos personal context endpoints
+type TelosHandler struct {
+	db      *database.DB
+	context *services.TelosService
+}
+
+// NewTelosHandler creates a new Telos handler
+func NewTelosHandler(db *database.DB, context *services.TelosService) *TelosHandler {
+	return &TelosHandler{db: db, context: context}
+}
+
+// Import handles POST /api/v1/telos/import
+// The body is the Telos markdown file itself. It replaces the stored context,
+// so editing the file and importing it again keeps the two in step; work
+// linked to a goal that was removed keeps its goal ID.
+func (h *TelosHandler) Import(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	doc, err := telos.Parse(bytes.NewReader(body))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+	// An empty import would wipe the context, which is never what was meant
+	if len(doc.Items) == 0 {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"No Telos items found",
+			"Expected \"## SECTION\" headings listing items like \"- G1: Ship the beta\"",
+		))
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := h.context.Import(doc, now); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to import Telos file",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TelosResponse{
+		Message:   "Telos context imported. Work can now link to its goals.",
+		Sections:  services.SectionCounts(doc),
+		Timestamp: now,
+	})
+}
+
+// ListItems handles GET /api/v1/telos
+// Lists the imported items in file order; ?section=goals narrows the list
+// to one section, such as the goals work can be linked to.
+func (h *TelosHandler) ListItems(c *gin.Context) {
+	items, err := h.db.GetTelosItems(c.Query("section"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list Telos items",
+			err.Error(),
+		))
+		return
+	}
+	if items == nil {
+		items = []models.TelosItem{}
+	}
+
+	c.JSON(http.StatusOK, models.TelosResponse{
+		Message:   "Telos items in file order.",
+		Items:     items,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// GetItem handles GET /api/v1/telos/:id
+// Returns an item with its trace: the strategies, challenges, goals, mission
+// and problems above it, nearest first, so a goal can be followed back to the
+// mission and problems it serves.
+func (h *TelosHandler) GetItem(c *gin.Context) {
+	item, trace, err := h.context.Trace(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to get Telos item",
+			err.Error(),
+		))
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Telos item not found",
+			"No imported item has ID "+c.Param("id"),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TelosResponse{
+		Message:   "Telos item and the chain above it.",
+		Item:      item,
+		Trace:     trace,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// resolveGoal checks the goal a piece of work is linked to, returning its
+// canonical ID. No goal is fine; an ID that is not an imported goal is
+// rejected with 400, since a typo would silently break the trace.
+func resolveGoal(c *gin.Context, db *database.DB, goalID string) (string, bool) {
+	if goalID == "" {
+		return "", true
+	}
+	item, err := db.GetTelosItem(goalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to look up goal",
+			err.Error(),
+		))
+		return "", false
+	}
+	if item == nil || item.Section != "GOALS" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Unknown goal",
+			"goal_id must be a goal in the imported Telos file, got "+strings.ToUpper(goalID),
+		))
+		return "", false
+	}
+	return item.ID, true
+}
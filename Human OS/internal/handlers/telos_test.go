@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// setupTelosRouter creates a test router with the Telos endpoints, the
+// endpoints that link work to goals and the alignment report
+func setupTelosRouter(db *database.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	lessons := services.NewLessonService(db)
+	archiver := services.NewArchiver(db, false)
+	emotions := services.NewEmotionService(db, 4*time.Hour)
+	guard := services.NewRuminationGuard(db, services.RuminationPolicy{})
+	telosHandler := NewTelosHandler(db, services.NewTelosService(db))
+	focusHandler := NewFocusHandler(db, services.NewCognitiveStateService(db, guard, emotions, nil), archiver, lessons)
+	loopHandler := NewLoopHandler(db, archiver, lessons)
+	threadHandler := NewThreadHandler(db, lessons)
+	ingestHandler := NewIngestHandler(db, services.NewTaskClassifier(db))
+	reportHandler := NewReportHandler(services.NewReportService(db, emotions), guard, services.NewTriggerService(db, emotions))
+
+	router.POST("/api/v1/telos/import", telosHandler.Import)
+	router.GET("/api/v1/telos", telosHandler.ListItems)
+	router.GET("/api/v1/telos/:id", telosHandler.GetItem)
+	router.POST("/api/v1/focus/set", focusHandler.SetFocus)
+	router.POST("/api/v1/loop/authorize", loopHandler.AuthorizeLoop)
+	router.POST("/api/v1/loop/close", loopHandler.CloseLoop)
+	router.POST("/api/v1/thread/spawn", threadHandler.SpawnThread)
+	router.POST("/api/v1/thread/background", threadHandler.BackgroundThread)
+	router.POST("/api/v1/ingest/task", ingestHandler.IngestTask)
+	router.GET("/api/v1/reports/alignment", reportHandler.Alignment)
+
+	return router
+}
+
+// TestTelosGoalLinkage tests importing the example Telos file, tracing items
+// back to the problems they serve and linking work to goals
+func TestTelosGoalLinkage(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupTelosRouter(db)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	ids := func(items []models.TelosItem) string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	example, err := os.ReadFile("../../example_personal_telos.md")
+	if err != nil {
+		t.Fatalf("Failed to read example Telos file: %v", err)
+	}
+
+	t.Run("import the example file", func(t *testing.T) {
+		w := send("POST", "/api/v1/telos/import", string(example))
+		var resp models.TelosResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || resp.Sections["GOALS"] != 4 || resp.Sections["PROBLEMS"] != 1 || resp.Sections["HISTORY"] != 0 {
+			t.Fatalf("Expected 4 goals and 1 problem, no history, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = send("GET", "/api/v1/telos?section=goals", "")
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if got := ids(resp.Items); got != "G1,G2,G3,G4" {
+			t.Errorf("Expected goals in file order, got %s", got)
+		}
+	})
+
+	t.Run("nothing to import is rejected", func(t *testing.T) {
+		if w := send("POST", "/api/v1/telos/import", "# Just prose\n\nNo sections here."); w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := send("GET", "/api/v1/telos/G1", ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected the earlier import to survive, got %d", w.Code)
+		}
+	})
+
+	t.Run("duplicate IDs are rejected", func(t *testing.T) {
+		w := send("POST", "/api/v1/telos/import", "## GOALS\n- G1: Ship the beta\n\n## PROJECTS\n- g1: Beta launch\n")
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "line 5: G1 is already used on line 2") {
+			t.Fatalf("Expected 400 naming both lines, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("items trace back to the problems", func(t *testing.T) {
+		tests := []struct {
+			id    string
+			trace string
+		}{
+			{"g1", "M1,P1"},
+			{"C1", "G1,G2,G3,G4,M1,P1"},
+			{"P1", ""},
+			{"I1", ""},
+		}
+		for _, tt := range tests {
+			w := send("GET", "/api/v1/telos/"+tt.id, "")
+			var resp models.TelosResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if w.Code != http.StatusOK || resp.Item == nil || resp.Item.ID != strings.ToUpper(tt.id) {
+				t.Fatalf("Expected item %s, got %d: %s", tt.id, w.Code, w.Body.String())
+			}
+			if got := ids(resp.Trace); got != tt.trace {
+				t.Errorf("%s: expected trace %q, got %q", tt.id, tt.trace, got)
+			}
+		}
+		if w := send("GET", "/api/v1/telos/G9", ""); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for an unknown item, got %d", w.Code)
+		}
+	})
+
+	t.Run("named references narrow the trace", func(t *testing.T) {
+		file := "## MISSION\n- M1: Ship\n- M2: Rest\n## GOALS\n- G1: Launch (M1)\n## CHALLENGES\n- C1: No time for G1\n"
+		if w := send("POST", "/api/v1/telos/import", file); w.Code != http.StatusOK {
+			t.Fatalf("Failed to import: %s", w.Body.String())
+		}
+		w := send("GET", "/api/v1/telos/C1", "")
+		var resp models.TelosResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if got := ids(resp.Trace); got != "G1,M1" {
+			t.Errorf("Expected C1 -> G1 -> M1, got %q", got)
+		}
+
+		if w := send("POST", "/api/v1/telos/import", string(example)); w.Code != http.StatusOK {
+			t.Fatalf("Failed to re-import the example: %s", w.Body.String())
+		}
+	})
+
+	t.Run("work links to goals", func(t *testing.T) {
+		tests := []struct {
+			path string
+			body string
+		}{
+			{"/api/v1/focus/set", `{"task_name": "Record podcast pitch", "duration": "25m", "success_criteria": "Sent", "goal_id": "g1"}`},
+			{"/api/v1/loop/authorize", `{"description": "Email the host", "priority": "high", "queue": "action", "owner": "me", "goal_id": "G1"}`},
+			{"/api/v1/thread/spawn", `{"thread_name": "SOSS launch", "mode": "foreground", "time_scope": "month", "goal_id": "G2"}`},
+			{"/api/v1/thread/background", `{"thread_name": "Pitch angle", "goal": "Find a hook", "goal_id": "G1"}`},
+			{"/api/v1/ingest/task", `{"description": "Write the download page", "goal_id": "G2"}`},
+		}
+		for _, tt := range tests {
+			w := send("POST", tt.path, tt.body)
+			if w.Code != http.StatusOK && w.Code != http.StatusCreated {
+				t.Fatalf("%s: expected success, got %d: %s", tt.path, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `"goal_id":"G`) {
+				t.Errorf("%s: expected the goal in the response, got %s", tt.path, w.Body.String())
+			}
+		}
+
+		focus, _ := db.GetCurrentFocus()
+		loops, _ := db.GetOpenLoops()
+		threads, _ := db.GetActiveThreads()
+		tasks, _ := db.GetPendingTasks()
+		if focus == nil || focus.GoalID != "G1" || len(loops) != 1 || loops[0].GoalID != "G1" ||
+			len(threads) != 2 || threads[0].GoalID == "" || len(tasks) != 1 || tasks[0].GoalID != "G2" {
+			t.Fatalf("Expected goal IDs to be stored, got focus %+v, loops %+v, threads %+v, tasks %+v", focus, loops, threads, tasks)
+		}
+	})
+
+	t.Run("unknown goals are rejected", func(t *testing.T) {
+		for _, goal := range []string{"G9", "M1"} {
+			w := send("POST", "/api/v1/loop/authorize",
+				`{"description": "Orphan", "priority": "low", "queue": "action", "owner": "me", "goal_id": "`+goal+`"}`)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d: %s", goal, w.Code, w.Body.String())
+			}
+		}
+	})
+}
+
+// TestGoalAlignment tests the per-goal totals of the alignment report and the
+// list of work linked to no goal
+func TestGoalAlignment(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	router := setupTelosRouter(db)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	created := func(path, body string) string {
+		w := send("POST", path, body)
+		var resp struct {
+			ID       string `json:"id"`
+			LoopID   string `json:"loop_id"`
+			ThreadID string `json:"thread_id"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("%s: expected 201, got %d: %s", path, w.Code, w.Body.String())
+		}
+		return resp.ID + resp.LoopID + resp.ThreadID
+	}
+
+	if w := send("POST", "/api/v1/telos/import", "## GOALS\n- G1: Ship the beta\n- G2: Run a marathon\n- G3: Learn Spanish\n"); w.Code != http.StatusOK {
+		t.Fatalf("Failed to import: %s", w.Body.String())
+	}
+
+	// Two finished sessions on G1, one abandoned there when the next started,
+	// one unlinked and one from before the week, started in order
+	now := time.Now().UTC()
+	for _, session := range []struct {
+		id, goal string
+		ago      time.Duration
+		minutes  int // 0 leaves the session active
+	}{
+		{"f4", "G2", 10 * 24 * time.Hour, 60},
+		{"f1", "G1", 48 * time.Hour, 50},
+		{"f0", "G1", 26 * time.Hour, 0},
+		{"f2", "G1", 24 * time.Hour, 25},
+		{"f3", "", 3 * time.Hour, 30},
+	} {
+		started := now.Add(-session.ago)
+		if err := db.SetFocus(&models.FocusState{
+			ID: session.id, TaskName: "Session " + session.id, Duration: "8h", SuccessCriteria: "Done",
+			StartedAt: started, EndsAt: started.Add(8 * time.Hour), GoalID: session.goal, CreatedAt: started, UpdatedAt: started,
+		}); err != nil {
+			t.Fatalf("Failed to set focus: %v", err)
+		}
+		if session.minutes > 0 {
+			if _, err := db.CompleteFocus(session.id, started.Add(time.Duration(session.minutes)*time.Minute)); err != nil {
+				t.Fatalf("Failed to complete focus: %v", err)
+			}
+		}
+	}
+
+	authorize := func(description, goalID string) string {
+		body, _ := json.Marshal(models.LoopAuthorizeRequest{
+			Description: description, Priority: models.PriorityMedium, Queue: models.QueueAction, Owner: "me", GoalID: goalID,
+		})
+		return created("/api/v1/loop/authorize", string(body))
+	}
+	closed := authorize("Cut the beta build", "G1")
+	authorize("Write release notes", "G1")
+	authorize("Book shoe fitting", "G2")
+	authorize("Renew car insurance", "")
+	if w := send("POST", "/api/v1/loop/close", `{"loop_id": "`+closed+`", "closure_type": "done"}`); w.Code != http.StatusOK {
+		t.Fatalf("Failed to close loop: %s", w.Body.String())
+	}
+	created("/api/v1/thread/spawn", `{"thread_name": "Beta feedback", "mode": "background", "time_scope": "week", "goal_id": "G1"}`)
+	created("/api/v1/thread/spawn", `{"thread_name": "Kitchen remodel", "mode": "foreground", "time_scope": "month"}`)
+	created("/api/v1/ingest/task", `{"description": "Buy running socks", "goal_id": "G2"}`)
+	created("/api/v1/ingest/task", `{"description": "Return library books"}`)
+
+	// Drop G2 from the file; the work linked to it is still reported
+	if w := send("POST", "/api/v1/telos/import", "## GOALS\n- G1: Ship the beta\n- G3: Learn Spanish\n"); w.Code != http.StatusOK {
+		t.Fatalf("Failed to re-import: %s", w.Body.String())
+	}
+
+	w := send("GET", "/api/v1/reports/alignment", "")
+	var report models.AlignmentReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if w.Code != http.StatusOK || len(report.Goals) != 3 {
+		t.Fatalf("Expected G1, G3 and removed G2, got %d: %s", w.Code, w.Body.String())
+	}
+
+	want := []models.GoalAlignment{
+		{GoalID: "G1", Text: "Ship the beta", FocusSessions: 3, FocusMinutes: 195, LoopsOpen: 1, LoopsClosed: 1, ThreadsActive: 1},
+		{GoalID: "G3", Text: "Learn Spanish"},
+		{GoalID: "G2", Removed: true, LoopsOpen: 1, TasksPending: 1},
+	}
+	for i, goal := range want {
+		if report.Goals[i] != goal {
+			t.Errorf("Goal %d: expected %+v, got %+v", i, goal, report.Goals[i])
+		}
+	}
+	// The abandoned session counts the 2 hours until the next one started
+	if report.AlignedFocusMinutes != 195 || report.UnlinkedFocusMinutes != 30 {
+		t.Errorf("Expected 195 aligned and 30 unlinked focus minutes, got %d and %d", report.AlignedFocusMinutes, report.UnlinkedFocusMinutes)
+	}
+
+	kinds := map[string]string{}
+	for _, work := range report.Unlinked {
+		kinds[work.Kind] = work.Description
+	}
+	if len(report.Unlinked) != 4 || kinds["focus"] != "Session f3" || kinds["loop"] != "Renew car insurance" ||
+		kinds["thread"] != "Kitchen remodel" || kinds["task"] != "Return library books" {
+		t.Errorf("Expected one unlinked focus, loop, thread and task, got %+v", report.Unlinked)
+	}
+
+	if w := send("GET", "/api/v1/reports/alignment?period=month", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a month, got %d", w.Code)
+	}
+	if w := send("GET", "/api/v1/reports/alignment?period=year", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown period, got %d", w.Code)
+	}
+}
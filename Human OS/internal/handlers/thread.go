@@ -13,28 +13,38 @@ import (
 
 	"humanos-api/internal/database"
 	"humanos-api/internal/models"
+	"humanos-api/internal/services"
 )
 
 // ThreadHandler handles thread-related endpoints
 type ThreadHandler struct {
-	db *database.DB
+	db      *database.DB
+	lessons *services.LessonService
 }
 
 // NewThreadHandler creates a new thread handler
-func NewThreadHandler(db *database.DB) *ThreadHandler {
-	return &ThreadHandler{db: db}
+func NewThreadHandler(db *database.DB, lessons *services.LessonService) *ThreadHandler {
+	return &ThreadHandler{
+		db:      db,
+		lessons: lessons,
+	}
 }
 
 // SpawnThread handles POST /api/v1/thread/spawn
 // Spawning a thread creates a new cognitive process. Foreground threads
 // require active attention; background threads run in diffuse mode.
 // Time scope helps categorize the thread's expected duration and priority.
+// Lessons from related archived work are returned alongside the new thread.
 func (h *ThreadHandler) SpawnThread(c *gin.Context) {
 	var req models.ThreadSpawnRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
 		return
 	}
+	goalID, ok := resolveGoal(c, h.db, req.GoalID)
+	if !ok {
+		return
+	}
 
 	now := time.Now().UTC()
 	thread := &models.Thread{
@@ -43,6 +53,7 @@ func (h *ThreadHandler) SpawnThread(c *gin.Context) {
 		Mode:      req.Mode,
 		TimeScope: req.TimeScope,
 		Status:    "active",
+		GoalID:    goalID,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -60,10 +71,14 @@ func (h *ThreadHandler) SpawnThread(c *gin.Context) {
 		modeDescription = "BACKGROUND - diffuse processing activated"
 	}
 
+	// Resurfacing is best-effort; it must never block spawning the thread
+	lessons, _ := h.lessons.Resurface(models.LessonContextThread, thread.ID, thread.Name)
+
 	c.JSON(http.StatusCreated, models.ThreadResponse{
 		Message:   "Thread spawned: " + modeDescription,
 		ThreadID:  thread.ID,
 		Thread:    thread,
+		Lessons:   lessons,
 		Timestamp: now,
 	})
 }
@@ -78,6 +93,10 @@ func (h *ThreadHandler) BackgroundThread(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
 		return
 	}
+	goalID, ok := resolveGoal(c, h.db, req.GoalID)
+	if !ok {
+		return
+	}
 
 	now := time.Now().UTC()
 	thread := &models.Thread{
@@ -87,6 +106,7 @@ func (h *ThreadHandler) BackgroundThread(c *gin.Context) {
 		TimeScope: "ongoing",
 		Goal:      req.Goal,
 		Status:    "active",
+		GoalID:    goalID,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -0,0 +1,209 @@
+// Package handlers contains HTTP request handlers for the Human OS Cognitive API.
+// Trigger handlers manage the named people, projects and contexts that
+// emotions are blamed on. Triggers are detected from source guesses as
+// emotions are tagged; these endpoints let you correct and consolidate them.
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/services"
+)
+
+// TriggerHandler handles trigger-related endpoints
+type TriggerHandler struct {
+	db       *database.DB
+	triggers *services.TriggerService
+}
+
+// NewTriggerHandler creates a new trigger handler
+func NewTriggerHandler(db *database.DB, triggers *services.TriggerService) *TriggerHandler {
+	return &TriggerHandler{db: db, triggers: triggers}
+}
+
+// ListTriggers handles GET /api/v1/triggers
+// Triggers are listed most tagged first, so the things that set you off most
+// often are at the top.
+func (h *TriggerHandler) ListTriggers(c *gin.Context) {
+	triggers, err := h.db.GetTriggers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to list triggers",
+			err.Error(),
+		))
+		return
+	}
+	if triggers == nil {
+		triggers = []models.Trigger{}
+	}
+
+	c.JSON(http.StatusOK, models.TriggerResponse{
+		Message:   "Triggers by number of tagged emotions.",
+		Triggers:  triggers,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// CreateTrigger handles POST /api/v1/triggers
+// Registers a trigger ahead of time, typically a person with nicknames, so
+// source guesses that mention any of them are recognized from the start.
+func (h *TriggerHandler) CreateTrigger(c *gin.Context) {
+	var req models.TriggerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	now := time.Now().UTC()
+	trigger := &models.Trigger{
+		ID:        uuid.New().String(),
+		Kind:      req.Kind,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if !applyTriggerRequest(c, trigger, req) {
+		return
+	}
+
+	merged, err := h.triggers.Save(trigger, true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.TriggerResponse{
+		Message:   "Trigger created: " + trigger.Name + ".",
+		Trigger:   trigger,
+		Merged:    merged,
+		Timestamp: now,
+	})
+}
+
+// UpdateTrigger handles PUT /api/v1/triggers/:id
+// Renames or reclassifies a trigger and replaces its aliases. Detection only
+// guesses whether a trigger is a person, project or context; this is where
+// the guess gets corrected. Adding another trigger's name as an alias merges
+// that trigger into this one.
+func (h *TriggerHandler) UpdateTrigger(c *gin.Context) {
+	var req models.TriggerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	trigger, err := h.db.GetTrigger(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to find trigger",
+			err.Error(),
+		))
+		return
+	}
+	if trigger == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Trigger not found",
+			"No trigger exists with the provided ID",
+		))
+		return
+	}
+
+	trigger.Kind = req.Kind
+	trigger.UpdatedAt = time.Now().UTC()
+	if !applyTriggerRequest(c, trigger, req) {
+		return
+	}
+
+	merged, err := h.triggers.Save(trigger, false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	message := "Trigger updated: " + trigger.Name + "."
+	if len(merged) > 0 {
+		message += " Merged: " + strings.Join(merged, ", ") + "."
+	}
+	c.JSON(http.StatusOK, models.TriggerResponse{
+		Message:   message,
+		Trigger:   trigger,
+		Merged:    merged,
+		Timestamp: trigger.UpdatedAt,
+	})
+}
+
+// DeleteTrigger handles DELETE /api/v1/triggers/:id
+// Emotions blamed only on the deleted trigger stay untagged until a backfill
+// runs over their range.
+func (h *TriggerHandler) DeleteTrigger(c *gin.Context) {
+	affected, err := h.db.DeleteTrigger(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to delete trigger",
+			err.Error(),
+		))
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"Trigger not found",
+			"No trigger exists with the provided ID",
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TriggerResponse{
+		Message:   "Trigger deleted.",
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// Backfill handles POST /api/v1/triggers/backfill
+// Detects triggers for emotions tagged within the range that have none yet,
+// such as tags recorded before triggers existed or whose trigger was deleted.
+// Takes the same from, to and tz parameters as emotion trends.
+func (h *TriggerHandler) Backfill(c *gin.Context) {
+	from, to, _, err := parseEmotionRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid time range", err.Error()))
+		return
+	}
+
+	linked, err := h.triggers.Backfill(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"Failed to backfill triggers",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TriggerResponse{
+		Message:   fmt.Sprintf("Detected triggers for %d emotion(s).", linked),
+		Linked:    linked,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// applyTriggerRequest normalizes the requested name and aliases onto a
+// trigger, writing a 400 response when the name is empty
+func applyTriggerRequest(c *gin.Context, trigger *models.Trigger, req models.TriggerRequest) bool {
+	trigger.Name = services.NormalizeTriggerName(req.Name)
+	if trigger.Name == "" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"Invalid trigger name",
+			"The name must contain at least one letter or digit",
+		))
+		return false
+	}
+
+	trigger.Aliases = services.NormalizeTriggerAliases(trigger.Name, req.Aliases)
+	return true
+}
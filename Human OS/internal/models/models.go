@@ -63,24 +63,29 @@ const (
 // Focus is the core attention mechanism - when set, it defines what the mind
 // should be working on to the exclusion of other tasks.
 type FocusState struct {
-	ID              string    `json:"id" db:"id"`
-	TaskName        string    `json:"task_name" db:"task_name"`
-	Duration        string    `json:"duration" db:"duration"`
-	SuccessCriteria string    `json:"success_criteria" db:"success_criteria"`
-	IsLocked        bool      `json:"is_locked" db:"is_locked"`
-	Timebox         string    `json:"timebox,omitempty" db:"timebox"`
-	Fallback        string    `json:"fallback,omitempty" db:"fallback"`
-	StartedAt       time.Time `json:"started_at" db:"started_at"`
-	EndsAt          time.Time `json:"ends_at,omitempty" db:"ends_at"`
-	CreatedAt       time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
-}
-
-// FocusSetRequest represents a request to set focus on a specific task
+	ID              string     `json:"id" db:"id"`
+	TaskName        string     `json:"task_name" db:"task_name"`
+	Duration        string     `json:"duration" db:"duration"`
+	SuccessCriteria string     `json:"success_criteria" db:"success_criteria"`
+	IsLocked        bool       `json:"is_locked" db:"is_locked"`
+	Timebox         string     `json:"timebox,omitempty" db:"timebox"`
+	Fallback        string     `json:"fallback,omitempty" db:"fallback"`
+	Status          string     `json:"status" db:"status"` // "active", "completed", "superseded", "cleared"
+	StartedAt       time.Time  `json:"started_at" db:"started_at"`
+	EndsAt          time.Time  `json:"ends_at,omitempty" db:"ends_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	GoalID          string     `json:"goal_id,omitempty" db:"goal_id"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// FocusSetRequest represents a request to set focus on a specific task.
+// GoalID optionally names the Telos goal the work serves.
 type FocusSetRequest struct {
 	TaskName        string `json:"task_name" binding:"required"`
 	Duration        string `json:"duration" binding:"required"`
 	SuccessCriteria string `json:"success_criteria" binding:"required"`
+	GoalID          string `json:"goal_id,omitempty"`
 }
 
 // FocusLockRequest represents a request to lock focus (preventing context switching)
@@ -90,11 +95,21 @@ type FocusLockRequest struct {
 	Fallback string `json:"fallback" binding:"required"`
 }
 
+// FocusCompleteRequest represents a request to mark the current focus session complete.
+// Archive overrides the AUTO_ARCHIVE setting for this session.
+type FocusCompleteRequest struct {
+	Outcome string `json:"outcome,omitempty"`
+	Archive *bool  `json:"archive,omitempty"`
+}
+
 // FocusResponse is the response for focus operations
 type FocusResponse struct {
-	Message   string      `json:"message"`
-	Focus     *FocusState `json:"focus,omitempty"`
-	Timestamp time.Time   `json:"timestamp"`
+	Message      string             `json:"message"`
+	Focus        *FocusState        `json:"focus,omitempty"`
+	ArchiveID    string             `json:"archive_id,omitempty"`
+	LessonPrompt string             `json:"lesson_prompt,omitempty"`
+	Lessons      []ResurfacedLesson `json:"lessons,omitempty"`
+	Timestamp    time.Time          `json:"timestamp"`
 }
 
 // ============================================================================
@@ -114,23 +129,28 @@ type Loop struct {
 	ClosureType ClosureType `json:"closure_type,omitempty" db:"closure_type"`
 	NextStep    string      `json:"next_step,omitempty" db:"next_step"`
 	ClosedAt    *time.Time  `json:"closed_at,omitempty" db:"closed_at"`
+	GoalID      string      `json:"goal_id,omitempty" db:"goal_id"`
 	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
 }
 
-// LoopAuthorizeRequest represents a request to create and authorize a new loop
+// LoopAuthorizeRequest represents a request to create and authorize a new loop.
+// GoalID optionally names the Telos goal the loop serves.
 type LoopAuthorizeRequest struct {
 	Description string    `json:"description" binding:"required"`
 	Priority    Priority  `json:"priority" binding:"required,oneof=high medium low"`
 	Queue       QueueType `json:"queue" binding:"required,oneof=action reference backburner"`
 	Owner       string    `json:"owner" binding:"required"`
+	GoalID      string    `json:"goal_id,omitempty"`
 }
 
-// LoopCloseRequest represents a request to close an existing loop
+// LoopCloseRequest represents a request to close an existing loop.
+// Archive overrides the AUTO_ARCHIVE setting for loops closed as done.
 type LoopCloseRequest struct {
 	LoopID      string      `json:"loop_id" binding:"required"`
 	ClosureType ClosureType `json:"closure_type" binding:"required,oneof=done paused abandoned"`
 	NextStep    string      `json:"next_step,omitempty"`
+	Archive     *bool       `json:"archive,omitempty"`
 }
 
 // LoopKillRequest represents a request to kill/terminate a loop immediately
@@ -141,10 +161,13 @@ type LoopKillRequest struct {
 
 // LoopResponse is the response for loop operations
 type LoopResponse struct {
-	Message   string    `json:"message"`
-	LoopID    string    `json:"loop_id,omitempty"`
-	Loop      *Loop     `json:"loop,omitempty"`
-	Timestamp time.Time `json:"timestamp"`
+	Message      string             `json:"message"`
+	LoopID       string             `json:"loop_id,omitempty"`
+	Loop         *Loop              `json:"loop,omitempty"`
+	ArchiveID    string             `json:"archive_id,omitempty"`
+	LessonPrompt string             `json:"lesson_prompt,omitempty"`
+	Lessons      []ResurfacedLesson `json:"lessons,omitempty"`
+	Timestamp    time.Time          `json:"timestamp"`
 }
 
 // ============================================================================
@@ -160,21 +183,27 @@ type Thread struct {
 	TimeScope string     `json:"time_scope" db:"time_scope"`
 	Goal      string     `json:"goal,omitempty" db:"goal"`
 	Status    string     `json:"status" db:"status"` // "active", "terminated"
+	GoalID    string     `json:"goal_id,omitempty" db:"goal_id"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
 }
 
-// ThreadSpawnRequest represents a request to spawn a new cognitive thread
+// ThreadSpawnRequest represents a request to spawn a new cognitive thread.
+// GoalID optionally names the Telos goal the thread serves.
 type ThreadSpawnRequest struct {
 	ThreadName string     `json:"thread_name" binding:"required"`
 	Mode       ThreadMode `json:"mode" binding:"required,oneof=foreground background"`
 	TimeScope  string     `json:"time_scope" binding:"required"`
+	GoalID     string     `json:"goal_id,omitempty"`
 }
 
-// ThreadBackgroundRequest represents a request to create a background processing thread
+// ThreadBackgroundRequest represents a request to create a background
+// processing thread. Goal is what to mull over; GoalID optionally names the
+// Telos goal it serves.
 type ThreadBackgroundRequest struct {
 	ThreadName string `json:"thread_name" binding:"required"`
 	Goal       string `json:"goal" binding:"required"`
+	GoalID     string `json:"goal_id,omitempty"`
 }
 
 // ThreadTerminateRequest represents a request to terminate threads matching a rule
@@ -184,10 +213,11 @@ type ThreadTerminateRequest struct {
 
 // ThreadResponse is the response for thread operations
 type ThreadResponse struct {
-	Message   string    `json:"message"`
-	ThreadID  string    `json:"thread_id,omitempty"`
-	Thread    *Thread   `json:"thread,omitempty"`
-	Timestamp time.Time `json:"timestamp"`
+	Message   string             `json:"message"`
+	ThreadID  string             `json:"thread_id,omitempty"`
+	Thread    *Thread            `json:"thread,omitempty"`
+	Lessons   []ResurfacedLesson `json:"lessons,omitempty"`
+	Timestamp time.Time          `json:"timestamp"`
 }
 
 // ============================================================================
@@ -201,17 +231,82 @@ type Task struct {
 	Category    string    `json:"category" db:"category"`
 	Urgency     Priority  `json:"urgency" db:"urgency"`
 	Importance  Priority  `json:"importance" db:"importance"`
+	Queue       QueueType `json:"queue,omitempty" db:"queue"`
+	RuleID      string    `json:"rule_id,omitempty" db:"rule_id"`
 	Status      string    `json:"status" db:"status"` // "pending", "processed"
+	GoalID      string    `json:"goal_id,omitempty" db:"goal_id"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// IngestTaskRequest represents a request to ingest a new task
+// IngestTaskRequest represents a request to ingest a new task.
+// Category, urgency and importance are optional - anything left blank is
+// filled in from the first matching classification rule. GoalID optionally
+// names the Telos goal the task serves.
 type IngestTaskRequest struct {
 	Description string   `json:"description" binding:"required"`
-	Category    string   `json:"category" binding:"required"`
-	Urgency     Priority `json:"urgency" binding:"required,oneof=high medium low"`
-	Importance  Priority `json:"importance" binding:"required,oneof=high medium low"`
+	Category    string   `json:"category,omitempty"`
+	Urgency     Priority `json:"urgency,omitempty" binding:"omitempty,oneof=high medium low"`
+	Importance  Priority `json:"importance,omitempty" binding:"omitempty,oneof=high medium low"`
+	GoalID      string   `json:"goal_id,omitempty"`
+}
+
+// RuleMatchType represents how a classification rule pattern is matched
+type RuleMatchType string
+
+const (
+	RuleMatchKeyword RuleMatchType = "keyword"
+	RuleMatchRegex   RuleMatchType = "regex"
+)
+
+// ClassificationRule maps a keyword or regular expression found in a task
+// description to a category, default urgency/importance and target queue.
+// Rules are evaluated in ascending position order; the first match wins.
+type ClassificationRule struct {
+	ID         string        `json:"id" db:"id"`
+	Name       string        `json:"name" db:"name"`
+	MatchType  RuleMatchType `json:"match_type" db:"match_type"`
+	Pattern    string        `json:"pattern" db:"pattern"`
+	Category   string        `json:"category" db:"category"`
+	Urgency    Priority      `json:"urgency" db:"urgency"`
+	Importance Priority      `json:"importance" db:"importance"`
+	Queue      QueueType     `json:"queue" db:"queue"`
+	Position   int           `json:"position" db:"position"`
+	Enabled    bool          `json:"enabled" db:"enabled"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// ClassificationRuleRequest represents a request to create or replace a classification rule
+type ClassificationRuleRequest struct {
+	Name       string        `json:"name" binding:"required"`
+	MatchType  RuleMatchType `json:"match_type" binding:"required,oneof=keyword regex"`
+	Pattern    string        `json:"pattern" binding:"required"`
+	Category   string        `json:"category" binding:"required"`
+	Urgency    Priority      `json:"urgency" binding:"required,oneof=high medium low"`
+	Importance Priority      `json:"importance" binding:"required,oneof=high medium low"`
+	Queue      QueueType     `json:"queue" binding:"required,oneof=action reference backburner"`
+	Position   int           `json:"position"`
+	Enabled    *bool         `json:"enabled,omitempty"`
+}
+
+// ClassificationRuleResponse is the response for classification rule operations
+type ClassificationRuleResponse struct {
+	Message   string               `json:"message"`
+	Rule      *ClassificationRule  `json:"rule,omitempty"`
+	Rules     []ClassificationRule `json:"rules,omitempty"`
+	Timestamp time.Time            `json:"timestamp"`
+}
+
+// TaskClassification reports how the server classified an ingested task
+type TaskClassification struct {
+	RuleID     string    `json:"rule_id,omitempty"`
+	RuleName   string    `json:"rule_name,omitempty"`
+	Category   string    `json:"category"`
+	Urgency    Priority  `json:"urgency"`
+	Importance Priority  `json:"importance"`
+	Queue      QueueType `json:"queue"`
+	Filled     []string  `json:"filled_fields,omitempty"`
 }
 
 // Idea represents a captured idea for later processing
@@ -234,22 +329,35 @@ type IngestIdeaRequest struct {
 
 // IngestResponse is the response for ingestion operations
 type IngestResponse struct {
-	Message   string    `json:"message"`
-	ID        string    `json:"id"`
-	Timestamp time.Time `json:"timestamp"`
+	Message        string              `json:"message"`
+	ID             string              `json:"id"`
+	Classification *TaskClassification `json:"classification,omitempty"`
+	GoalID         string              `json:"goal_id,omitempty"`
+	Timestamp      time.Time           `json:"timestamp"`
 }
 
 // ============================================================================
 // ARCHIVE MODELS
 // ============================================================================
 
-// Archive represents a committed/archived object with learnings
+// ArchiveSource identifies what produced an automatic archive entry
+type ArchiveSource string
+
+const (
+	ArchiveSourceLoop  ArchiveSource = "loop"
+	ArchiveSourceFocus ArchiveSource = "focus"
+)
+
+// Archive represents a committed/archived object with learnings.
+// Entries created automatically from finished work carry a source reference.
 type Archive struct {
-	ID        string    `json:"id" db:"id"`
-	Object    string    `json:"object" db:"object"`
-	Summary   string    `json:"summary" db:"summary"`
-	Lesson    string    `json:"lesson,omitempty" db:"lesson"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID         string        `json:"id" db:"id"`
+	Object     string        `json:"object" db:"object"`
+	Summary    string        `json:"summary" db:"summary"`
+	Lesson     string        `json:"lesson,omitempty" db:"lesson"`
+	SourceType ArchiveSource `json:"source_type,omitempty" db:"source_type"`
+	SourceID   string        `json:"source_id,omitempty" db:"source_id"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
 }
 
 // ArchiveCommitRequest represents a request to archive something
@@ -259,6 +367,19 @@ type ArchiveCommitRequest struct {
 	Lesson  string `json:"lesson,omitempty"`
 }
 
+// ArchiveLessonRequest represents a follow-up request to record a lesson on an archive
+type ArchiveLessonRequest struct {
+	Lesson string `json:"lesson" binding:"required"`
+}
+
+// ArchiveListResponse is the response for archive listings
+type ArchiveListResponse struct {
+	Message   string    `json:"message"`
+	Count     int       `json:"count"`
+	Archives  []Archive `json:"archives"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 // ArchiveResponse is the response for archive operations
 type ArchiveResponse struct {
 	Message   string    `json:"message"`
@@ -266,27 +387,167 @@ type ArchiveResponse struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// ArchiveSearchResult is a single ranked archive search hit. Snippet is a
+// short excerpt of the best-matching field with matched terms in [brackets].
+type ArchiveSearchResult struct {
+	Archive Archive `json:"archive"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+// ArchiveSearchResponse is the response for archive search
+type ArchiveSearchResponse struct {
+	Query     string                `json:"query"`
+	Count     int                   `json:"count"`
+	Results   []ArchiveSearchResult `json:"results"`
+	Timestamp time.Time             `json:"timestamp"`
+}
+
+// ============================================================================
+// LESSON MODELS
+// ============================================================================
+
+// LessonContext identifies the kind of work a lesson was resurfaced for
+type LessonContext string
+
+const (
+	LessonContextFocus  LessonContext = "focus"
+	LessonContextThread LessonContext = "thread"
+	LessonContextLoop   LessonContext = "loop"
+)
+
+// ResurfacedLesson is an archived lesson shown when starting related work.
+// ResurfacingID is used to rate whether the lesson was useful, which feeds
+// back into how it ranks next time.
+type ResurfacedLesson struct {
+	ResurfacingID  string  `json:"resurfacing_id"`
+	ArchiveID      string  `json:"archive_id"`
+	Object         string  `json:"object"`
+	Lesson         string  `json:"lesson"`
+	Snippet        string  `json:"snippet"`
+	Score          float64 `json:"score"`
+	UsefulCount    int     `json:"useful_count"`
+	NotUsefulCount int     `json:"not_useful_count"`
+}
+
+// LessonFeedback aggregates usefulness ratings for one archived lesson
+type LessonFeedback struct {
+	ArchiveID      string `json:"archive_id"`
+	UsefulCount    int    `json:"useful_count"`
+	NotUsefulCount int    `json:"not_useful_count"`
+}
+
+// LessonRateRequest represents a request to rate a resurfaced lesson
+type LessonRateRequest struct {
+	Useful *bool `json:"useful" binding:"required"`
+}
+
+// LessonResponse is the response for lesson operations
+type LessonResponse struct {
+	Message   string          `json:"message"`
+	Feedback  *LessonFeedback `json:"feedback,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
 // ============================================================================
 // PREDICTION MODELS
 // ============================================================================
 
 // Prediction represents an active prediction/scenario modeling process
 type Prediction struct {
-	ID          string    `json:"id" db:"id"`
-	Scenario    string    `json:"scenario" db:"scenario"`
-	TimeHorizon string    `json:"time_horizon" db:"time_horizon"`
-	Depth       string    `json:"depth" db:"depth"` // "low", "medium", "deep"
-	Status      string    `json:"status" db:"status"` // "running", "stopped"
-	Results     string    `json:"results,omitempty" db:"results"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID           string     `json:"id" db:"id"`
+	Scenario     string     `json:"scenario" db:"scenario"`
+	Topic        string     `json:"topic,omitempty" db:"topic"`
+	TimeHorizon  string     `json:"time_horizon" db:"time_horizon"`
+	ResolveBy    *time.Time `json:"resolve_by,omitempty" db:"resolve_by"`
+	Depth        string     `json:"depth" db:"depth"`   // "low", "medium", "deep"
+	Status       string     `json:"status" db:"status"` // "running", "stopped", "resolved"
+	Results      string     `json:"results,omitempty" db:"results"`
+	Forecasts    []Forecast `json:"forecasts,omitempty"`
+	BudgetEndsAt *time.Time `json:"budget_ends_at,omitempty" db:"budget_ends_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 }
 
-// PredictRunRequest represents a request to run a prediction
+// PredictRunRequest represents a request to run a prediction.
+// Topic groups predictions for calibration reporting; forecasts are optional
+// explicit probability estimates that can be scored once the prediction resolves.
+// ResolveBy overrides the resolution date parsed from TimeHorizon, and
+// BudgetMinutes overrides the default thinking-time budget for the depth.
 type PredictRunRequest struct {
-	Scenario    string `json:"scenario" binding:"required"`
-	TimeHorizon string `json:"time_horizon" binding:"required"`
-	Depth       string `json:"depth" binding:"required,oneof=low medium deep"`
+	Scenario      string            `json:"scenario" binding:"required"`
+	Topic         string            `json:"topic,omitempty"`
+	TimeHorizon   string            `json:"time_horizon" binding:"required"`
+	ResolveBy     *time.Time        `json:"resolve_by,omitempty"`
+	Depth         string            `json:"depth" binding:"required,oneof=low medium deep"`
+	BudgetMinutes int               `json:"budget_minutes,omitempty" binding:"omitempty,min=1"`
+	Forecasts     []ForecastRequest `json:"forecasts,omitempty" binding:"omitempty,dive"`
+}
+
+// Forecast is an explicit, scorable claim attached to a prediction:
+// "this statement will be true, with this probability"
+type Forecast struct {
+	ID           string     `json:"id" db:"id"`
+	PredictionID string     `json:"prediction_id" db:"prediction_id"`
+	Statement    string     `json:"statement" db:"statement"`
+	Probability  float64    `json:"probability" db:"probability"`
+	Occurred     *bool      `json:"occurred,omitempty" db:"occurred"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ForecastRequest represents a request to add a forecast to a prediction
+type ForecastRequest struct {
+	Statement   string   `json:"statement" binding:"required"`
+	Probability *float64 `json:"probability" binding:"required,min=0,max=1"`
+}
+
+// ForecastOutcome records whether a single forecast came true
+type ForecastOutcome struct {
+	ForecastID string `json:"forecast_id" binding:"required"`
+	Occurred   *bool  `json:"occurred" binding:"required"`
+}
+
+// PredictResolveRequest represents a request to record the actual outcome of a prediction
+type PredictResolveRequest struct {
+	Outcome   string            `json:"outcome" binding:"required"`
+	Forecasts []ForecastOutcome `json:"forecasts,omitempty" binding:"omitempty,dive"`
+}
+
+// CalibrationScore summarizes forecast accuracy. Brier score is the mean
+// squared error between probability and outcome: 0 is perfect, 0.25 is what
+// always answering 50% earns, and 1 is confidently wrong every time.
+type CalibrationScore struct {
+	Forecasts  int     `json:"forecasts"`
+	BrierScore float64 `json:"brier_score"`
+}
+
+// CalibrationBin is one point on the calibration curve: of the forecasts made
+// with probability in [lower, upper), how often did they actually happen?
+type CalibrationBin struct {
+	Lower             float64 `json:"lower"`
+	Upper             float64 `json:"upper"`
+	Count             int     `json:"count"`
+	MeanProbability   float64 `json:"mean_probability"`
+	ObservedFrequency float64 `json:"observed_frequency"`
+}
+
+// CalibrationReport shows whether mental simulations are worth the effort
+type CalibrationReport struct {
+	Overall   CalibrationScore            `json:"overall"`
+	ByDepth   map[string]CalibrationScore `json:"by_depth"`
+	ByTopic   map[string]CalibrationScore `json:"by_topic"`
+	Curve     []CalibrationBin            `json:"curve"`
+	Timestamp time.Time                   `json:"timestamp"`
+}
+
+// ResolvedForecast is a resolved forecast with the prediction context needed for scoring
+type ResolvedForecast struct {
+	Probability float64
+	Occurred    bool
+	Depth       string
+	Topic       string
 }
 
 // PredictStopRequest represents a request to stop prediction on a topic
@@ -302,6 +563,86 @@ type PredictResponse struct {
 	Timestamp    time.Time   `json:"timestamp"`
 }
 
+// ScenarioBranch is one node in a prediction's scenario tree: if Condition
+// holds, Outcome follows with Probability (relative to its parent), and
+// Response is the plan for that case. Value scores leaf outcomes for
+// expected-value comparison, in whatever unit the decision is measured.
+type ScenarioBranch struct {
+	ID              string           `json:"id" db:"id"`
+	PredictionID    string           `json:"prediction_id" db:"prediction_id"`
+	ParentID        string           `json:"parent_id,omitempty" db:"parent_id"`
+	Condition       string           `json:"condition" db:"condition"`
+	Outcome         string           `json:"outcome" db:"outcome"`
+	Probability     float64          `json:"probability" db:"probability"`
+	PathProbability float64          `json:"path_probability"`
+	Response        string           `json:"response,omitempty" db:"response"`
+	Value           *float64         `json:"value,omitempty" db:"value"`
+	Children        []ScenarioBranch `json:"children,omitempty"`
+	CreatedAt       time.Time        `json:"created_at" db:"created_at"`
+}
+
+// ScenarioBranchRequest represents a request to add a branch to a scenario tree
+type ScenarioBranchRequest struct {
+	ParentID    string   `json:"parent_id,omitempty"`
+	Condition   string   `json:"condition" binding:"required"`
+	Outcome     string   `json:"outcome" binding:"required"`
+	Probability *float64 `json:"probability" binding:"required,min=0,max=1"`
+	Response    string   `json:"response,omitempty"`
+	Value       *float64 `json:"value,omitempty"`
+}
+
+// ScenarioLeaf is a complete path through a scenario tree
+type ScenarioLeaf struct {
+	BranchID    string   `json:"branch_id"`
+	Path        []string `json:"path"`
+	Probability float64  `json:"probability"`
+	Value       *float64 `json:"value,omitempty"`
+}
+
+// ScenarioTree is a prediction's full scenario tree with its expected value.
+// ExpectedValue is only set when every leaf has a value.
+type ScenarioTree struct {
+	PredictionID  string           `json:"prediction_id"`
+	Scenario      string           `json:"scenario"`
+	Branches      []ScenarioBranch `json:"branches"`
+	Leaves        []ScenarioLeaf   `json:"leaves"`
+	ExpectedValue *float64         `json:"expected_value,omitempty"`
+	Warnings      []string         `json:"warnings,omitempty"`
+}
+
+// ScenarioTreeResponse is the response for scenario tree operations
+type ScenarioTreeResponse struct {
+	Message   string        `json:"message"`
+	BranchID  string        `json:"branch_id,omitempty"`
+	Tree      *ScenarioTree `json:"tree"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// RuminationTopic tracks how often a topic came back after being stopped
+type RuminationTopic struct {
+	Topic           string     `json:"topic"`
+	Stops           int        `json:"stops"`
+	Restarts        int        `json:"restarts"`
+	LastRestartedAt *time.Time `json:"last_restarted_at,omitempty"`
+	Flagged         bool       `json:"flagged"`
+}
+
+// RuminationReport lists stopped topics that keep getting restarted
+type RuminationReport struct {
+	WindowDays int               `json:"window_days"`
+	Threshold  int               `json:"threshold"`
+	Topics     []RuminationTopic `json:"topics"`
+	Timestamp  time.Time         `json:"timestamp"`
+}
+
+// PredictionListResponse is the response for listing predictions
+type PredictionListResponse struct {
+	Message     string       `json:"message"`
+	Count       int          `json:"count"`
+	Predictions []Prediction `json:"predictions"`
+	Timestamp   time.Time    `json:"timestamp"`
+}
+
 // ============================================================================
 // EMOTION MODELS
 // ============================================================================
@@ -311,6 +652,7 @@ type EmotionalState struct {
 	ID          string    `json:"id" db:"id"`
 	Label       string    `json:"label" db:"label"`
 	SourceGuess string    `json:"source_guess" db:"source_guess"`
+	Intensity   *int      `json:"intensity,omitempty" db:"intensity"` // 1-10
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -318,56 +660,493 @@ type EmotionalState struct {
 type EmotionTagRequest struct {
 	Label       string `json:"label" binding:"required"`
 	SourceGuess string `json:"source_guess" binding:"required"`
+	Intensity   *int   `json:"intensity,omitempty" binding:"omitempty,min=1,max=10"`
+}
+
+// EmotionDefinition is one entry in the emotion taxonomy. Valence runs from
+// -1 (unpleasant) to 1 (pleasant), arousal from 0 (calm) to 1 (activated),
+// and LoadWeight is how much the emotion adds to emotional load at full
+// intensity (0-1). Synonyms are tagged as this label.
+type EmotionDefinition struct {
+	Label      string    `json:"label" db:"label"`
+	Valence    float64   `json:"valence" db:"valence"`
+	Arousal    float64   `json:"arousal" db:"arousal"`
+	LoadWeight float64   `json:"load_weight" db:"load_weight"`
+	Synonyms   []string  `json:"synonyms" db:"synonyms"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// EmotionDefinitionRequest represents a request to add or update a taxonomy entry
+type EmotionDefinitionRequest struct {
+	Valence    *float64 `json:"valence" binding:"required,min=-1,max=1"`
+	Arousal    *float64 `json:"arousal" binding:"required,min=0,max=1"`
+	LoadWeight *float64 `json:"load_weight" binding:"required,min=0,max=1"`
+	Synonyms   []string `json:"synonyms,omitempty"`
+}
+
+// EmotionTaxonomyResponse is the response for emotion taxonomy operations
+type EmotionTaxonomyResponse struct {
+	Message   string              `json:"message"`
+	Count     int                 `json:"count"`
+	Emotions  []EmotionDefinition `json:"emotions"`
+	Timestamp time.Time           `json:"timestamp"`
 }
 
-// DecompressSession represents an active decompression session
+// DecompressSession represents a decompression session. Check-ins are
+// emotions tagged just before and after the session.
 type DecompressSession struct {
-	ID        string    `json:"id" db:"id"`
-	Method    string    `json:"method" db:"method"`
-	Duration  string    `json:"duration" db:"duration"`
-	Status    string    `json:"status" db:"status"` // "active", "completed"
-	StartedAt time.Time `json:"started_at" db:"started_at"`
-	EndsAt    time.Time `json:"ends_at" db:"ends_at"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID          string          `json:"id" db:"id"`
+	Method      string          `json:"method" db:"method"`
+	Duration    string          `json:"duration" db:"duration"`
+	Status      string          `json:"status" db:"status"` // "active", "completed", "ended", "cancelled"
+	StartedAt   time.Time       `json:"started_at" db:"started_at"`
+	EndsAt      time.Time       `json:"ends_at" db:"ends_at"`
+	EndedAt     *time.Time      `json:"ended_at,omitempty" db:"ended_at"`
+	PreCheckIn  *EmotionalState `json:"pre_check_in,omitempty"`
+	PostCheckIn *EmotionalState `json:"post_check_in,omitempty"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+// EmotionCheckIn is an emotion tagged before or after decompression
+type EmotionCheckIn struct {
+	Label       string `json:"label" binding:"required"`
+	SourceGuess string `json:"source_guess,omitempty"`
+	Intensity   *int   `json:"intensity,omitempty" binding:"omitempty,min=1,max=10"`
 }
 
 // EmotionDecompressRequest represents a request to start decompression
 type EmotionDecompressRequest struct {
-	Method   string `json:"method" binding:"required"`
-	Duration string `json:"duration" binding:"required"`
+	Method   string          `json:"method" binding:"required"`
+	Duration string          `json:"duration" binding:"required"`
+	CheckIn  *EmotionCheckIn `json:"check_in,omitempty"`
+}
+
+// DecompressFinishRequest represents a request to end, complete or cancel
+// a decompression session, with an optional post-session check-in
+type DecompressFinishRequest struct {
+	CheckIn *EmotionCheckIn `json:"check_in,omitempty"`
+}
+
+// DecompressResponse is the response for decompression lifecycle operations
+type DecompressResponse struct {
+	Message   string             `json:"message"`
+	Session   *DecompressSession `json:"session"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// DecompressMethodStats summarizes how well one decompression method works.
+// Load is a check-in's load weight scaled by intensity; averages are only
+// set once a finished session has both check-ins.
+type DecompressMethodStats struct {
+	Method        string   `json:"method"`
+	Sessions      int      `json:"sessions"`
+	Completed     int      `json:"completed"`
+	Ended         int      `json:"ended"`
+	Cancelled     int      `json:"cancelled"`
+	Measured      int      `json:"measured"`
+	AvgPreLoad    *float64 `json:"avg_pre_load,omitempty"`
+	AvgPostLoad   *float64 `json:"avg_post_load,omitempty"`
+	AvgReduction  *float64 `json:"avg_reduction,omitempty"`
+	ReducedCount  int      `json:"reduced_count"`
+	ReductionRate *float64 `json:"reduction_rate,omitempty"`
+}
+
+// DecompressionReport ranks decompression methods by how much they reduced
+// tagged emotional load
+type DecompressionReport struct {
+	Methods   []DecompressMethodStats `json:"methods"`
+	Timestamp time.Time               `json:"timestamp"`
 }
 
 // EmotionResponse is the response for emotion operations
 type EmotionResponse struct {
+	Message        string          `json:"message"`
+	ID             string          `json:"id,omitempty"`
+	Triggers       []string        `json:"triggers,omitempty"`
+	Recommendation *Recommendation `json:"recommendation,omitempty"`
+	Timestamp      time.Time       `json:"timestamp"`
+}
+
+// TriggerKind classifies what an emotion was blamed on
+type TriggerKind string
+
+const (
+	TriggerKindPerson  TriggerKind = "person"
+	TriggerKindProject TriggerKind = "project"
+	TriggerKindContext TriggerKind = "context"
+)
+
+// Trigger is a named person, project or context that emotions are blamed on.
+// Triggers are detected from source guesses; aliases are other names for
+// the same trigger.
+type Trigger struct {
+	ID        string      `json:"id" db:"id"`
+	Name      string      `json:"name" db:"name"`
+	Kind      TriggerKind `json:"kind" db:"kind"`
+	Aliases   []string    `json:"aliases" db:"aliases"`
+	Tags      int         `json:"tags"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// TriggerRequest represents a request to create or replace a trigger
+type TriggerRequest struct {
+	Name    string      `json:"name" binding:"required"`
+	Kind    TriggerKind `json:"kind" binding:"required,oneof=person project context"`
+	Aliases []string    `json:"aliases,omitempty"`
+}
+
+// TriggerResponse is the response for trigger operations
+type TriggerResponse struct {
 	Message   string    `json:"message"`
-	ID        string    `json:"id,omitempty"`
+	Trigger   *Trigger  `json:"trigger,omitempty"`
+	Triggers  []Trigger `json:"triggers,omitempty"`
+	Merged    []string  `json:"merged,omitempty"`
+	Linked    int       `json:"linked,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// TriggerStats counts the emotions blamed on one trigger
+type TriggerStats struct {
+	TriggerID    string      `json:"trigger_id"`
+	Name         string      `json:"name"`
+	Kind         TriggerKind `json:"kind"`
+	Tags         int         `json:"tags"`
+	HighLoadTags int         `json:"high_load_tags"`
+	LastTaggedAt *time.Time  `json:"last_tagged_at,omitempty"`
+}
+
+// TriggerLink is a loop or thread that was open when high-load emotions
+// were blamed on a trigger it mentions
+type TriggerLink struct {
+	ID               string   `json:"id"`
+	Text             string   `json:"text"`
+	Status           string   `json:"status"`
+	HighLoadEmotions int      `json:"high_load_emotions"`
+	Triggers         []string `json:"triggers"`
+}
+
+// TriggerReport shows which triggers, and which loops and threads behind
+// them, most often precede high-load emotions
+type TriggerReport struct {
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Emotions  int            `json:"emotions"`
+	HighLoad  int            `json:"high_load"`
+	Triggers  []TriggerStats `json:"triggers"`
+	Loops     []TriggerLink  `json:"loops"`
+	Threads   []TriggerLink  `json:"threads"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// EmotionHistoryResponse lists tagged emotional states over a time range
+type EmotionHistoryResponse struct {
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Count     int              `json:"count"`
+	States    []EmotionalState `json:"states"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// EmotionDay summarizes one day of emotional tags alongside the open loops
+// and focus sessions of that day. Load is the undecayed weighted intensity.
+type EmotionDay struct {
+	Date           string  `json:"date"` // YYYY-MM-DD in the requested time zone
+	Tags           int     `json:"tags"`
+	Load           float64 `json:"load"`
+	OpenLoops      int     `json:"open_loops"`
+	FocusSessions  int     `json:"focus_sessions"`
+	FocusCompleted int     `json:"focus_completed"`
+}
+
+// EmotionCorrelations holds Pearson correlations between daily emotional
+// load and other signals. A value is nil when there is too little data.
+type EmotionCorrelations struct {
+	OpenLoops       *float64 `json:"open_loops"`
+	FocusCompletion *float64 `json:"focus_completion"`
+}
+
+// EmotionTrends breaks tagged emotions down by label, source, hour of day
+// and weekday, and relates daily load to open loops and focus completion
+type EmotionTrends struct {
+	From         time.Time           `json:"from"`
+	To           time.Time           `json:"to"`
+	TimeZone     string              `json:"time_zone"`
+	Total        int                 `json:"total"`
+	ByLabel      map[string]int      `json:"by_label"`
+	BySource     map[string]int      `json:"by_source"`
+	ByHour       [24]int             `json:"by_hour"`
+	ByWeekday    map[string]int      `json:"by_weekday"`
+	Daily        []EmotionDay        `json:"daily"`
+	Correlations EmotionCorrelations `json:"correlations"`
+	Timestamp    time.Time           `json:"timestamp"`
+}
+
+// ============================================================================
+// TELOS MODELS
+// ============================================================================
+
+// TelosItem is one entry of the imported Telos personal context: a problem,
+// mission, goal, challenge, strategy or project, keyed by its ID (P1, M1,
+// G1...). Refs are the other items its text names.
+type TelosItem struct {
+	ID         string    `json:"id" db:"id"`
+	Section    string    `json:"section" db:"section"`
+	Text       string    `json:"text" db:"text"`
+	Refs       []string  `json:"refs,omitempty" db:"refs"`
+	Position   int       `json:"position" db:"position"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+}
+
+// TelosResponse is the response for Telos operations. Trace is the chain
+// from an item up to the problems it serves, nearest first.
+type TelosResponse struct {
+	Message   string         `json:"message"`
+	Item      *TelosItem     `json:"item,omitempty"`
+	Items     []TelosItem    `json:"items,omitempty"`
+	Trace     []TelosItem    `json:"trace,omitempty"`
+	Sections  map[string]int `json:"sections,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
 // ============================================================================
 // AI INTEGRATION MODELS
 // ============================================================================
 
-// AIOffload represents an offloaded task to AI
+// AIOffload represents an offloaded task to AI. Offloads are queued as
+// pending and run by the offload queue; Provider, Result and Error are set
+// once the offload has been run. A failed attempt that will be retried
+// keeps its error and goes back to pending until NextAttemptAt.
 type AIOffload struct {
+	ID              string            `json:"id" db:"id"`
+	TaskType        string            `json:"task_type" db:"task_type"`
+	Scope           string            `json:"scope" db:"scope"`
+	Status          string            `json:"status" db:"status"` // "pending", "processing", "awaiting_review", "completed", "failed", "cancelled"
+	Provider        string            `json:"provider,omitempty" db:"provider"`
+	Result          string            `json:"result,omitempty" db:"result"`
+	Error           string            `json:"error,omitempty" db:"error"`
+	Attempts        int               `json:"attempts" db:"attempts"`
+	NextAttemptAt   *time.Time        `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	StartedAt       *time.Time        `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt     *time.Time        `json:"completed_at,omitempty" db:"completed_at"`
+	Quorum          bool              `json:"quorum" db:"quorum"`
+	Strategy        string            `json:"strategy,omitempty" db:"strategy"`         // agreement strategy for quorum offloads
+	TemplateVersion int               `json:"template_version" db:"template_version"`   // prompt template version used (0 = none)
+	Instructions    string            `json:"instructions,omitempty" db:"instructions"` // rendered system instructions
+	Prompt          string            `json:"prompt,omitempty" db:"prompt"`             // rendered prompt sent to the provider
+	Feedback        string            `json:"feedback,omitempty" db:"feedback"`         // reviewer feedback sent with the next attempt
+	SkipReview      bool              `json:"skip_review" db:"skip_review"`             // completes without review; set only for assist requests
+	Votes           []AIOffloadVote   `json:"votes,omitempty"`
+	Reviews         []AIOffloadReview `json:"reviews,omitempty"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// AIOffloadVote is one quorum member's answer to one attempt of a quorum
+// offload. Agrees marks the votes that formed the agreement.
+type AIOffloadVote struct {
 	ID        string    `json:"id" db:"id"`
-	TaskType  string    `json:"task_type" db:"task_type"`
-	Scope     string    `json:"scope" db:"scope"`
-	Status    string    `json:"status" db:"status"` // "pending", "processing", "completed"
+	OffloadID string    `json:"offload_id" db:"offload_id"`
+	Attempt   int       `json:"attempt" db:"attempt"`
+	Member    string    `json:"member" db:"member"`
+	Status    string    `json:"status" db:"status"` // "completed", "failed", "cancelled"
+	Output    string    `json:"output,omitempty" db:"output"`
+	Error     string    `json:"error,omitempty" db:"error"`
+	Agrees    bool      `json:"agrees" db:"agrees"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// AIOffloadRequest represents a request to offload a task to AI
+// AIOffloadReview is the audit record of one human review of an offload's
+// result: who reviewed it, what the AI produced and what was accepted.
+type AIOffloadReview struct {
+	ID             string    `json:"id" db:"id"`
+	OffloadID      string    `json:"offload_id" db:"offload_id"`
+	Action         string    `json:"action" db:"action"` // "approved", "edited", "rejected"
+	Reviewer       string    `json:"reviewer" db:"reviewer"`
+	Feedback       string    `json:"feedback,omitempty" db:"feedback"`
+	AIResult       string    `json:"ai_result" db:"ai_result"`
+	AcceptedResult string    `json:"accepted_result,omitempty" db:"accepted_result"` // empty when rejected
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// AIOffloadReviewRequest represents a review of an offload awaiting review.
+// Rejecting requires feedback; editing requires the corrected result.
+type AIOffloadReviewRequest struct {
+	Reviewer string `json:"reviewer" binding:"required"`
+	Feedback string `json:"feedback,omitempty"`
+	Result   string `json:"result,omitempty"`
+}
+
+// AIOffloadReviewResponse is the response for listing review audit records
+type AIOffloadReviewResponse struct {
+	Message   string            `json:"message"`
+	Reviews   []AIOffloadReview `json:"reviews"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// PromptTemplate is one version of the prompt an offload task type is sent
+// with. Body placeholders such as {{scope}} and {{goals}} are filled in when
+// an offload is queued. Saving a template always creates a new version.
+type PromptTemplate struct {
+	ID           string    `json:"id" db:"id"`
+	TaskType     string    `json:"task_type" db:"task_type"`
+	Version      int       `json:"version" db:"version"`
+	Instructions string    `json:"instructions" db:"instructions"` // system instructions for the provider
+	Body         string    `json:"body" db:"body"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// PromptTemplateRequest represents a request to save a new version of a
+// task type's prompt template
+type PromptTemplateRequest struct {
+	Instructions string `json:"instructions" binding:"required"`
+	Body         string `json:"body" binding:"required"`
+}
+
+// PromptTemplateResponse is the response for prompt template operations
+type PromptTemplateResponse struct {
+	Message   string           `json:"message"`
+	Template  *PromptTemplate  `json:"template,omitempty"`
+	Templates []PromptTemplate `json:"templates,omitempty"`
+	Variables []string         `json:"variables,omitempty"` // placeholders a body may use
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// AIOffloadRequest represents a request to offload a task to AI.
+// Quorum fans the offload out to every configured quorum provider and only
+// accepts a result when their answers agree.
 type AIOffloadRequest struct {
 	TaskType string `json:"task_type" binding:"required"`
 	Scope    string `json:"scope" binding:"required"`
+	Quorum   bool   `json:"quorum,omitempty"`
 }
 
-// AIAssistRequest represents a request for AI execution assistance
+// AIAssistRequest represents a request for AI execution assistance.
+// Template breaks the task down with the local template instead of the AI.
 type AIAssistRequest struct {
 	Task           string `json:"task" binding:"required"`
 	AssistanceType string `json:"assistance_type" binding:"required"`
+	Template       bool   `json:"template"`
+}
+
+// AssistSession is a task the person is executing themselves, broken into
+// steps they check off. It is linked to the focus that was current when it
+// started, and its steps come from the AI or a local template.
+type AssistSession struct {
+	ID             string       `json:"id" db:"id"`
+	Task           string       `json:"task" db:"task"`
+	AssistanceType string       `json:"assistance_type" db:"assistance_type"`
+	FocusID        string       `json:"focus_id,omitempty" db:"focus_id"`
+	OffloadID      string       `json:"offload_id,omitempty" db:"offload_id"` // the breakdown request
+	Source         string       `json:"source" db:"source"`                   // "ai", "template"
+	Status         string       `json:"status" db:"status"`                   // "planning", "active", "completed"
+	Steps          []AssistStep `json:"steps"`
+	StepsDone      int          `json:"steps_done"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at" db:"updated_at"`
+	CompletedAt    *time.Time   `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// AssistStep is one step of an assist session. A stuck step carries what is
+// in the way and the help request sent for it; Help is that request's result
+// once it has finished.
+type AssistStep struct {
+	ID            string     `json:"id" db:"id"`
+	SessionID     string     `json:"session_id" db:"session_id"`
+	Position      int        `json:"position" db:"position"`
+	Description   string     `json:"description" db:"description"`
+	Status        string     `json:"status" db:"status"` // "pending", "stuck", "done"
+	StuckOn       string     `json:"stuck_on,omitempty" db:"stuck_on"`
+	HelpOffloadID string     `json:"help_offload_id,omitempty" db:"help_offload_id"`
+	HelpStatus    string     `json:"help_status,omitempty"`
+	Help          string     `json:"help,omitempty"`
+	DoneAt        *time.Time `json:"done_at,omitempty" db:"done_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AssistStuckRequest asks for help with a step. Note says what is in the way.
+type AssistStuckRequest struct {
+	Note string `json:"note"`
+}
+
+// AssistSessionResponse is the response for assist session operations
+type AssistSessionResponse struct {
+	Message   string          `json:"message"`
+	Session   *AssistSession  `json:"session,omitempty"`
+	Sessions  []AssistSession `json:"sessions,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// AssistProgress is how far the assist session linked to the current focus
+// has got, as shown on the dashboard
+type AssistProgress struct {
+	SessionID  string `json:"session_id"`
+	Task       string `json:"task"`
+	Status     string `json:"status"`
+	StepsDone  int    `json:"steps_done"`
+	StepsTotal int    `json:"steps_total"`
+	NextStep   string `json:"next_step,omitempty"`
+	StuckSteps int    `json:"stuck_steps"`
+}
+
+// AIOffloadResponse is the response for observing and cancelling offloads
+type AIOffloadResponse struct {
+	Message   string      `json:"message"`
+	Offload   *AIOffload  `json:"offload,omitempty"`
+	Offloads  []AIOffload `json:"offloads,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// StateItem is one piece of open work in the context package sent to the AI
+// for a state summary
+type StateItem struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"` // "loop", "task", "idea", "thread"
+	Text     string `json:"text"`
+	Priority string `json:"priority,omitempty"` // loop priority, or task urgency/importance as "high/low"
+	Queue    string `json:"queue,omitempty"`
+	Detail   string `json:"detail,omitempty"` // next step, category, storage or thread mode
+	AgeDays  int    `json:"age_days"`
+}
+
+// StatePackage is the context package for a state summary: everything open,
+// pending, captured or running
+type StatePackage struct {
+	Items []StateItem `json:"items"`
+}
+
+// BriefingItem is one item a state briefing says to deal with, in order
+type BriefingItem struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Text   string `json:"text"`
+	Reason string `json:"reason"`
+}
+
+// ClosureSuggestion proposes closing, dropping or parking an open item
+type ClosureSuggestion struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Text   string `json:"text"`
+	Action string `json:"action"` // "close", "kill", "archive", "drop", "terminate", "background"
+	Reason string `json:"reason"`
+}
+
+// StateBriefing is a prioritized summary of open work with suggested closures
+type StateBriefing struct {
+	Summary    string              `json:"summary"`
+	Priorities []BriefingItem      `json:"priorities"`
+	Closures   []ClosureSuggestion `json:"closures"`
+}
+
+// StateSummaryResponse is the response for an AI state summary
+type StateSummaryResponse struct {
+	Message   string         `json:"message"`
+	Provider  string         `json:"provider"`
+	Counts    map[string]int `json:"counts"` // items sent, by kind
+	Briefing  StateBriefing  `json:"briefing"`
+	Timestamp time.Time      `json:"timestamp"`
 }
 
 // AIResponse is the response for AI operations
@@ -383,28 +1162,242 @@ type AIResponse struct {
 
 // CognitiveStatus represents the complete cognitive dashboard state
 type CognitiveStatus struct {
-	ForegroundThreads  []string  `json:"foreground_threads"`
-	BackgroundThreads  []string  `json:"background_threads"`
-	EmotionalLoad      LoadLevel `json:"emotional_load"`
-	OpenLoopsEstimate  int       `json:"open_loops_estimate"`
-	EnergyLevel        LoadLevel `json:"energy_level"`
-	CurrentFocus       string    `json:"current_focus,omitempty"`
-	FocusLocked        bool      `json:"focus_locked"`
-	ActivePredictions  int       `json:"active_predictions"`
-	PendingTasks       int       `json:"pending_tasks"`
-	CapturedIdeas      int       `json:"captured_ideas"`
-	Timestamp          time.Time `json:"timestamp"`
+	ForegroundThreads []string        `json:"foreground_threads"`
+	BackgroundThreads []string        `json:"background_threads"`
+	EmotionalLoad     LoadLevel       `json:"emotional_load"`
+	OpenLoopsEstimate int             `json:"open_loops_estimate"`
+	EnergyLevel       LoadLevel       `json:"energy_level"`
+	CurrentFocus      string          `json:"current_focus,omitempty"`
+	FocusLocked       bool            `json:"focus_locked"`
+	ActivePredictions int             `json:"active_predictions"`
+	PendingTasks      int             `json:"pending_tasks"`
+	CapturedIdeas     int             `json:"captured_ideas"`
+	RuminatingTopics  []string        `json:"ruminating_topics,omitempty"`
+	Assist            *AssistProgress `json:"assist,omitempty"`
+	Timestamp         time.Time       `json:"timestamp"`
+}
+
+// ============================================================================
+// RECOMMENDATION MODELS
+// ============================================================================
+
+// RecommendationAction is the kind of next step a recommendation suggests
+type RecommendationAction string
+
+const (
+	ActionDecompress     RecommendationAction = "decompress"
+	ActionSoftReset      RecommendationAction = "soft_reset"
+	ActionLoopCleanup    RecommendationAction = "loop_cleanup"
+	ActionStopPrediction RecommendationAction = "stop_prediction"
+	ActionRest           RecommendationAction = "rest"
+	ActionFocus          RecommendationAction = "focus"
+)
+
+// Recommendation is a concrete next action, with the endpoint that carries
+// it out and the parameters to send. Higher scores are more urgent.
+type Recommendation struct {
+	Action   RecommendationAction `json:"action"`
+	Title    string               `json:"title"`
+	Reason   string               `json:"reason"`
+	Endpoint string               `json:"endpoint,omitempty"`
+	Params   map[string]string    `json:"params,omitempty"`
+	Score    int                  `json:"score"`
+}
+
+// RecommendationContext is the state a recommendation was based on
+type RecommendationContext struct {
+	EmotionalLoad     LoadLevel `json:"emotional_load"`
+	LoadScore         float64   `json:"load_score"`
+	EnergyLevel       LoadLevel `json:"energy_level"`
+	OpenLoops         int       `json:"open_loops"`
+	ActivePredictions int       `json:"active_predictions"`
+	RuminatingTopics  []string  `json:"ruminating_topics,omitempty"`
+	Decompressing     bool      `json:"decompressing"`
+	TimeOfDay         string    `json:"time_of_day"` // "morning", "afternoon", "evening", "night"
+	Hour              int       `json:"hour"`
+}
+
+// RecommendationResponse is the response for recommendations
+type RecommendationResponse struct {
+	Recommendation *Recommendation       `json:"recommendation"`
+	Alternatives   []Recommendation      `json:"alternatives"`
+	Context        RecommendationContext `json:"context"`
+	Timestamp      time.Time             `json:"timestamp"`
+}
+
+// ============================================================================
+// REPORT MODELS
+// ============================================================================
+
+// ReportPeriod represents the time span covered by a retrospective
+type ReportPeriod string
+
+const (
+	ReportPeriodWeek  ReportPeriod = "week"
+	ReportPeriodMonth ReportPeriod = "month"
+)
+
+// RetroReport is a periodic retrospective assembled from existing cognitive data
+type RetroReport struct {
+	Period        ReportPeriod      `json:"period"`
+	Start         time.Time         `json:"start"`
+	End           time.Time         `json:"end"`
+	Loops         RetroLoopStats    `json:"loops"`
+	Focus         RetroFocusStats   `json:"focus"`
+	Emotions      map[string]int    `json:"emotions"`
+	EmotionalLoad LoadLevel         `json:"emotional_load"`
+	Lessons       []Archive         `json:"lessons"`
+	Predictions   RetroPredictStats `json:"predictions"`
+	AIOffloads    RetroAIStats      `json:"ai_offloads"`
+	GeneratedAt   time.Time         `json:"generated_at"`
+}
+
+// RetroLoopStats summarizes loops opened and closed during the period
+type RetroLoopStats struct {
+	Opened       int                 `json:"opened"`
+	Closed       int                 `json:"closed"`
+	ClosedByType map[ClosureType]int `json:"closed_by_type"`
+	StillOpen    int                 `json:"still_open"`
+}
+
+// RetroFocusStats summarizes focus sessions started during the period
+type RetroFocusStats struct {
+	Sessions     int          `json:"sessions"`
+	Completed    int          `json:"completed"`
+	TotalMinutes int          `json:"total_minutes"`
+	SessionsList []FocusState `json:"sessions_list"`
+}
+
+// RetroPredictStats summarizes prediction activity during the period
+type RetroPredictStats struct {
+	Started   int          `json:"started"`
+	Abandoned []Prediction `json:"abandoned"`
+}
+
+// RetroAIStats summarizes AI offloads created during the period
+type RetroAIStats struct {
+	Total    int            `json:"total"`
+	ByType   map[string]int `json:"by_type"`
+	ByStatus map[string]int `json:"by_status"`
+}
+
+// AlignmentReport shows how work lines up with the Telos goals: what was
+// spent on each goal during the period and which open work serves none.
+// Focus minutes count sessions started in the period; loops closed count
+// closures in the period; open loops, active threads and pending tasks are
+// as of now.
+type AlignmentReport struct {
+	Period               ReportPeriod    `json:"period"`
+	Start                time.Time       `json:"start"`
+	End                  time.Time       `json:"end"`
+	Goals                []GoalAlignment `json:"goals"`
+	Unlinked             []UnlinkedWork  `json:"unlinked"`
+	AlignedFocusMinutes  int             `json:"aligned_focus_minutes"`
+	UnlinkedFocusMinutes int             `json:"unlinked_focus_minutes"`
+	GeneratedAt          time.Time       `json:"generated_at"`
+}
+
+// GoalAlignment is the work linked to one goal. Removed marks a goal ID that
+// work still names but the imported Telos file no longer has.
+type GoalAlignment struct {
+	GoalID        string `json:"goal_id"`
+	Text          string `json:"text,omitempty"`
+	Removed       bool   `json:"removed,omitempty"`
+	FocusSessions int    `json:"focus_sessions"`
+	FocusMinutes  int    `json:"focus_minutes"`
+	LoopsOpen     int    `json:"loops_open"`
+	LoopsClosed   int    `json:"loops_closed"`
+	ThreadsActive int    `json:"threads_active"`
+	TasksPending  int    `json:"tasks_pending"`
+}
+
+// UnlinkedWork is a piece of work not linked to any goal
+type UnlinkedWork struct {
+	Kind        string    `json:"kind"` // "focus", "loop", "thread", "task"
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // ============================================================================
 // MODE/RESET MODELS
 // ============================================================================
 
-// ResetResponse is the response for reset operations
+// ResetDomain is a part of the active state a soft reset can clear
+type ResetDomain string
+
+const (
+	ResetDomainFocus       ResetDomain = "focus"
+	ResetDomainLoops       ResetDomain = "loops"
+	ResetDomainThreads     ResetDomain = "threads"
+	ResetDomainPredictions ResetDomain = "predictions"
+)
+
+// SoftResetRequest scopes a soft reset. Domains picks what is reset; none
+// means all four, as an unscoped reset always did. LoopQueues and
+// ThreadModes narrow the loops and threads reset to some queues or modes,
+// and ProtectHighPriority keeps high-priority loops open. With DryRun
+// nothing changes; the response lists what would.
+type SoftResetRequest struct {
+	Domains             []ResetDomain `json:"domains,omitempty" binding:"omitempty,dive,oneof=focus loops threads predictions"`
+	LoopQueues          []QueueType   `json:"loop_queues,omitempty" binding:"omitempty,dive,oneof=action reference backburner"`
+	ThreadModes         []ThreadMode  `json:"thread_modes,omitempty" binding:"omitempty,dive,oneof=foreground background"`
+	ProtectHighPriority bool          `json:"protect_high_priority,omitempty"`
+	DryRun              bool          `json:"dry_run,omitempty"`
+}
+
+// SoftResetChanges lists exactly what a soft reset changes: the active focus
+// sessions it clears, the loops it abandons, the threads it terminates and
+// the predictions it stops. ProtectedLoops were in scope but are kept open.
+type SoftResetChanges struct {
+	Focus          []FocusState `json:"focus"`
+	Loops          []Loop       `json:"loops"`
+	Threads        []Thread     `json:"threads"`
+	Predictions    []Prediction `json:"predictions"`
+	ProtectedLoops []Loop       `json:"protected_loops"`
+}
+
+// HardResetRequest confirms a hard reset with the token handed out by a
+// request without one
+type HardResetRequest struct {
+	ConfirmToken string `json:"confirm_token"`
+}
+
+// ResetResponse is the response for reset operations. Changes is set for
+// soft resets, listing what was (or, for a dry run, would be) changed.
+// SnapshotID is the snapshot taken first, which restoring undoes the reset.
+// An unconfirmed hard reset returns ConfirmToken, valid until ConfirmBy.
 type ResetResponse struct {
-	Message   string    `json:"message"`
-	ResetType string    `json:"reset_type"`
-	Timestamp time.Time `json:"timestamp"`
+	Message      string            `json:"message"`
+	ResetType    string            `json:"reset_type"`
+	DryRun       bool              `json:"dry_run,omitempty"`
+	Changes      *SoftResetChanges `json:"changes,omitempty"`
+	SnapshotID   string            `json:"snapshot_id,omitempty"`
+	ConfirmToken string            `json:"confirm_token,omitempty"`
+	ConfirmBy    *time.Time        `json:"confirm_by,omitempty"`
+	Timestamp    time.Time         `json:"timestamp"`
+}
+
+// StateSnapshot is a copy of every table taken before a reset or a restore.
+// Restoring it puts all data back as it was. Tables maps each table copied
+// to its number of rows.
+type StateSnapshot struct {
+	ID        string         `json:"id" db:"id"`
+	Reason    string         `json:"reason" db:"reason"` // "soft_reset", "hard_reset", "restore"
+	Tables    map[string]int `json:"tables"`
+	RowCount  int            `json:"row_count" db:"row_count"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
+
+// SnapshotResponse is the response for snapshot operations. UndoSnapshotID
+// is the snapshot of the state a restore replaced, so the restore can itself
+// be undone.
+type SnapshotResponse struct {
+	Message        string          `json:"message"`
+	Snapshot       *StateSnapshot  `json:"snapshot,omitempty"`
+	Snapshots      []StateSnapshot `json:"snapshots,omitempty"`
+	UndoSnapshotID string          `json:"undo_snapshot_id,omitempty"`
+	Timestamp      time.Time       `json:"timestamp"`
 }
 
 // ============================================================================
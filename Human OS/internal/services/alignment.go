@@ -0,0 +1,132 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// Alignment builds the goal alignment report for the period ending at now:
+// for each imported Telos goal, the focus time, loops and threads linked to
+// it, followed by the work that is not linked to any goal. Work naming a goal
+// that was later removed from the Telos file is reported under that ID
+// rather than dropped.
+func (s *ReportService) Alignment(period models.ReportPeriod, now time.Time) (*models.AlignmentReport, error) {
+	start, end, err := ReportWindow(period, now)
+	if err != nil {
+		return nil, err
+	}
+
+	goals, err := s.db.GetTelosItems("GOALS")
+	if err != nil {
+		return nil, err
+	}
+	report := &models.AlignmentReport{
+		Period:      period,
+		Start:       start,
+		End:         end,
+		Goals:       []models.GoalAlignment{},
+		Unlinked:    []models.UnlinkedWork{},
+		GeneratedAt: now,
+	}
+	byGoal := make(map[string]*models.GoalAlignment, len(goals))
+	for _, goal := range goals {
+		byGoal[goal.ID] = &models.GoalAlignment{GoalID: goal.ID, Text: goal.Text}
+	}
+	// alignment returns the entry for a goal, adding one for a removed goal
+	alignment := func(goalID string) *models.GoalAlignment {
+		if byGoal[goalID] == nil {
+			byGoal[goalID] = &models.GoalAlignment{GoalID: goalID, Removed: true}
+		}
+		return byGoal[goalID]
+	}
+	unlinked := func(kind, id, description string, createdAt time.Time) {
+		report.Unlinked = append(report.Unlinked, models.UnlinkedWork{
+			Kind: kind, ID: id, Description: description, CreatedAt: createdAt,
+		})
+	}
+
+	// Focus time
+	sessions, err := s.db.GetFocusSessions(start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		minutes := int(FocusTimeSpent(&session, now).Minutes())
+		if session.GoalID == "" {
+			report.UnlinkedFocusMinutes += minutes
+			unlinked("focus", session.ID, session.TaskName, session.CreatedAt)
+			continue
+		}
+		report.AlignedFocusMinutes += minutes
+		goal := alignment(session.GoalID)
+		goal.FocusSessions++
+		goal.FocusMinutes += minutes
+	}
+
+	// Loops still open, and those closed during the period
+	loops, err := s.db.GetOpenLoops()
+	if err != nil {
+		return nil, err
+	}
+	for _, loop := range loops {
+		if loop.GoalID == "" {
+			unlinked("loop", loop.ID, loop.Description, loop.CreatedAt)
+			continue
+		}
+		alignment(loop.GoalID).LoopsOpen++
+	}
+	loops, err = s.db.GetLoopsOpenDuring(start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, loop := range loops {
+		if loop.GoalID != "" && loop.ClosedAt != nil && loop.ClosedAt.Before(end) {
+			alignment(loop.GoalID).LoopsClosed++
+		}
+	}
+
+	// Active threads
+	threads, err := s.db.GetActiveThreads()
+	if err != nil {
+		return nil, err
+	}
+	for _, thread := range threads {
+		if thread.GoalID == "" {
+			unlinked("thread", thread.ID, thread.Name, thread.CreatedAt)
+			continue
+		}
+		alignment(thread.GoalID).ThreadsActive++
+	}
+
+	// Pending tasks
+	tasks, err := s.db.GetPendingTasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if task.GoalID == "" {
+			unlinked("task", task.ID, task.Description, task.CreatedAt)
+			continue
+		}
+		alignment(task.GoalID).TasksPending++
+	}
+
+	// Imported goals in file order, then removed ones by ID
+	for _, goal := range goals {
+		report.Goals = append(report.Goals, *byGoal[goal.ID])
+	}
+	var removed []string
+	for id, goal := range byGoal {
+		if goal.Removed {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		report.Goals = append(report.Goals, *byGoal[id])
+	}
+
+	return report, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// Archiver turns finished work into archive entries so the archive becomes a
+// complete record without a separate manual commit step. Lessons can't be
+// inferred, so each automatic entry comes with a prompt to add one later.
+type Archiver struct {
+	db          *database.DB
+	autoArchive bool
+}
+
+// NewArchiver creates a new archiver. autoArchive sets the default used when
+// a request does not say whether to archive.
+func NewArchiver(db *database.DB, autoArchive bool) *Archiver {
+	return &Archiver{
+		db:          db,
+		autoArchive: autoArchive,
+	}
+}
+
+// ShouldArchive resolves a per-request override against the configured default
+func (a *Archiver) ShouldArchive(override *bool) bool {
+	if override != nil {
+		return *override
+	}
+	return a.autoArchive
+}
+
+// ArchiveLoop records a loop closed as done
+func (a *Archiver) ArchiveLoop(loop *models.Loop, nextStep string) (*models.Archive, error) {
+	summary := loop.Description
+	if nextStep != "" {
+		summary += " Next step: " + nextStep
+	}
+	return a.create(models.ArchiveSourceLoop, loop.ID, summary)
+}
+
+// ArchiveFocus records a completed focus session
+func (a *Archiver) ArchiveFocus(focus *models.FocusState, outcome string) (*models.Archive, error) {
+	summary := focus.TaskName + " (" + focus.Duration + "). Success criteria: " + focus.SuccessCriteria + "."
+	if outcome != "" {
+		summary += " Outcome: " + outcome
+	}
+	return a.create(models.ArchiveSourceFocus, focus.ID, summary)
+}
+
+// LessonPrompt returns the follow-up prompt shown after an automatic archive
+func LessonPrompt(archiveID string) string {
+	return "What did you learn? Record it with POST /api/v1/archive/" + archiveID + "/lesson"
+}
+
+// create stores an archive entry referencing its source object
+func (a *Archiver) create(source models.ArchiveSource, sourceID, summary string) (*models.Archive, error) {
+	archive := &models.Archive{
+		ID:         uuid.New().String(),
+		Object:     string(source) + ":" + sourceID,
+		Summary:    summary,
+		SourceType: source,
+		SourceID:   sourceID,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := a.db.CreateArchive(archive); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
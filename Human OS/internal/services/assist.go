@@ -0,0 +1,244 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"humanos-api/internal/ai"
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// AssistService runs assist sessions: tasks the person executes themselves
+// with AI support. A session's steps come from a breakdown request on the
+// offload queue, as soon as it finishes, or from a local template when asked
+// for or when the AI gives nothing usable; stuck steps get their own help
+// requests.
+type AssistService struct {
+	db       *database.DB
+	offloads *OffloadService
+}
+
+// NewAssistService creates a new assist service queueing its AI requests on
+// offloads, and planning sessions as their breakdowns finish there
+func NewAssistService(db *database.DB, offloads *OffloadService) *AssistService {
+	s := &AssistService{db: db, offloads: offloads}
+	offloads.onFinish(s.breakdownFinished)
+	return s
+}
+
+// TemplateSteps is the local breakdown of a task: the same five steps for
+// any task, which works offline and is enough to get moving
+func TemplateSteps(task string) []string {
+	return []string{
+		"Get clear on what done looks like for: " + task,
+		"Gather what you need to start",
+		"Do the first small piece",
+		"Work through the rest",
+		"Check the result against what done looks like",
+	}
+}
+
+// Start creates a session linked to the current focus. With template the
+// steps are ready at once; otherwise the session is planning until the
+// breakdown request on the queue finishes.
+func (s *AssistService) Start(task, assistanceType string, template bool, now time.Time) (*models.AssistSession, error) {
+	session := &models.AssistSession{
+		ID:             uuid.New().String(),
+		Task:           task,
+		AssistanceType: assistanceType,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	focus, err := s.db.GetCurrentFocus()
+	if err != nil {
+		return nil, err
+	}
+	if focus != nil {
+		session.FocusID = focus.ID
+	}
+
+	if template {
+		session.Source, session.Status = "template", "active"
+		session.Steps = newAssistSteps(session.ID, TemplateSteps(task), now)
+		if err := s.db.CreateAssistSession(session); err != nil {
+			return nil, err
+		}
+		return session, nil
+	}
+
+	offload := &models.AIOffload{
+		ID:           uuid.New().String(),
+		TaskType:     "assist:" + assistanceType,
+		Scope:        task,
+		Instructions: ai.BreakdownInstructions(assistanceType),
+		SkipReview:   true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	session.OffloadID, session.Source, session.Status = offload.ID, "ai", "planning"
+	session.Steps = []models.AssistStep{}
+	if err := s.db.CreateAssistSession(session); err != nil {
+		return nil, err
+	}
+	if err := s.offloads.Enqueue(offload); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Get returns a session, or nil if it does not exist
+func (s *AssistService) Get(id string) (*models.AssistSession, error) {
+	return s.db.GetAssistSession(id)
+}
+
+// List returns sessions newest first, optionally filtered by status
+func (s *AssistService) List(status string, limit int) ([]models.AssistSession, error) {
+	return s.db.GetAssistSessions(status, limit)
+}
+
+// ForFocus returns the unfinished session linked to a focus, or nil
+func (s *AssistService) ForFocus(focusID string) (*models.AssistSession, error) {
+	return s.db.GetFocusAssistSession(focusID)
+}
+
+// Plan returns a session, or nil if it does not exist, first giving it its
+// steps if it is planning and its breakdown has finished. Sessions are
+// planned when their breakdown finishes on the queue; this catches up on any
+// missed, for example across a restart, and runs when asked for and before a
+// step is changed. Reads never plan.
+func (s *AssistService) Plan(id string, now time.Time) (*models.AssistSession, error) {
+	session, err := s.db.GetAssistSession(id)
+	if err != nil || session == nil {
+		return session, err
+	}
+	return s.plan(session, now)
+}
+
+// breakdownFinished plans the session waiting on an offload that just
+// finished on the queue, if there is one
+func (s *AssistService) breakdownFinished(offloadID string) {
+	session, err := s.db.GetPlanningAssistSession(offloadID)
+	if err == nil && session != nil {
+		_, err = s.plan(session, time.Now().UTC())
+	}
+	if err != nil {
+		log.Printf("Assist sessions: failed to plan the session for offload %s: %v", offloadID, err)
+	}
+}
+
+// plan gives a planning session the steps parsed from its finished
+// breakdown. A breakdown that failed, was cancelled or listed no steps falls
+// back to the local template, so a session is never stuck planning.
+func (s *AssistService) plan(session *models.AssistSession, now time.Time) (*models.AssistSession, error) {
+	if session.Status != "planning" {
+		return session, nil
+	}
+	offload, err := s.db.GetAIOffload(session.OffloadID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, steps := "template", TemplateSteps(session.Task)
+	switch {
+	case offload == nil:
+	case offload.Status == "completed":
+		if parsed := ai.ParseSteps(offload.Result); len(parsed) > 0 {
+			source, steps = "ai", parsed
+		}
+	case offload.Status == "failed" || offload.Status == "cancelled":
+	default:
+		return session, nil
+	}
+
+	if _, err := s.db.SetAssistSteps(session.ID, source, newAssistSteps(session.ID, steps, now), now); err != nil {
+		return nil, err
+	}
+	planned, err := s.db.GetAssistSession(session.ID)
+	if err != nil || planned == nil {
+		return session, err
+	}
+	return planned, nil
+}
+
+// CompleteStep checks off a step. It reports false if the step was already
+// done or the session is not active.
+func (s *AssistService) CompleteStep(sessionID string, position int, now time.Time) (bool, error) {
+	affected, err := s.db.CompleteAssistStep(sessionID, position, now)
+	return affected > 0, err
+}
+
+// Stuck marks a step as stuck and queues a request for help with it, with
+// the task, the step and what is in the way as the scope. The step and the
+// request are saved together, so a step never points at a request that was
+// not queued. A help request already sent for the step is cancelled. It
+// reports false if the step was already done or the session is not active.
+func (s *AssistService) Stuck(session *models.AssistSession, step models.AssistStep, note string, now time.Time) (bool, error) {
+	scope := fmt.Sprintf("Stuck on step %d of %d: %s\nTask: %s", step.Position, len(session.Steps), step.Description, session.Task)
+	if note != "" {
+		scope += "\nWhat is in the way: " + note
+	}
+	offload := &models.AIOffload{
+		ID:           uuid.New().String(),
+		TaskType:     ai.TaskUnstick,
+		Scope:        scope,
+		Instructions: ai.UnstickInstructions,
+		Status:       "pending",
+		SkipReview:   true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	affected, replaced, err := s.db.MarkAssistStepStuck(session.ID, step.Position, note, offload, now)
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	s.offloads.notify()
+	if replaced != "" {
+		// A request that already finished is left as it is
+		if _, err := s.offloads.Cancel(replaced); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// AssistProgress summarizes a session for the dashboard: steps done, the first
+// step not yet done and how many are stuck
+func AssistProgress(session *models.AssistSession) *models.AssistProgress {
+	progress := &models.AssistProgress{
+		SessionID:  session.ID,
+		Task:       session.Task,
+		Status:     session.Status,
+		StepsDone:  session.StepsDone,
+		StepsTotal: len(session.Steps),
+	}
+	for _, step := range session.Steps {
+		if step.Status == "stuck" {
+			progress.StuckSteps++
+		}
+		if step.Status != "done" && progress.NextStep == "" {
+			progress.NextStep = step.Description
+		}
+	}
+	return progress
+}
+
+// newAssistSteps numbers a breakdown's steps from 1
+func newAssistSteps(sessionID string, descriptions []string, now time.Time) []models.AssistStep {
+	steps := make([]models.AssistStep, 0, len(descriptions))
+	for i, description := range descriptions {
+		steps = append(steps, models.AssistStep{
+			ID:          uuid.New().String(),
+			SessionID:   sessionID,
+			Position:    i + 1,
+			Description: description,
+			Status:      "pending",
+			UpdatedAt:   now,
+		})
+	}
+	return steps
+}
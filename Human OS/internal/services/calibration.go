@@ -0,0 +1,100 @@
+package services
+
+import (
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// CalibrationBins is the number of equal-width probability buckets on the calibration curve
+const CalibrationBins = 10
+
+// UntaggedTopic groups forecasts from predictions started without a topic
+const UntaggedTopic = "untagged"
+
+// Calibration scores every resolved forecast so you can see whether your
+// mental simulations are actually predictive, and at which depth they pay off
+func (s *ReportService) Calibration(now time.Time) (*models.CalibrationReport, error) {
+	forecasts, err := s.db.GetResolvedForecasts()
+	if err != nil {
+		return nil, err
+	}
+	report := ScoreCalibration(forecasts)
+	report.Timestamp = now
+	return report, nil
+}
+
+// ScoreCalibration computes Brier scores overall, per depth and per topic,
+// plus a calibration curve. A well-calibrated forecaster's curve sits on the
+// diagonal: things called at 70% happen about 70% of the time.
+func ScoreCalibration(forecasts []models.ResolvedForecast) *models.CalibrationReport {
+	report := &models.CalibrationReport{
+		ByDepth: make(map[string]models.CalibrationScore),
+		ByTopic: make(map[string]models.CalibrationScore),
+		Curve:   make([]models.CalibrationBin, 0, CalibrationBins),
+	}
+
+	var total float64
+	depthTotals := make(map[string]float64)
+	topicTotals := make(map[string]float64)
+	bins := make([]models.CalibrationBin, CalibrationBins)
+	for i := range bins {
+		bins[i].Lower = float64(i) / CalibrationBins
+		bins[i].Upper = float64(i+1) / CalibrationBins
+	}
+
+	for _, f := range forecasts {
+		outcome := 0.0
+		if f.Occurred {
+			outcome = 1
+		}
+		sq := (f.Probability - outcome) * (f.Probability - outcome)
+
+		topic := f.Topic
+		if topic == "" {
+			topic = UntaggedTopic
+		}
+		total += sq
+		depthTotals[f.Depth] += sq
+		topicTotals[topic] += sq
+		report.Overall.Forecasts++
+		depth := report.ByDepth[f.Depth]
+		depth.Forecasts++
+		report.ByDepth[f.Depth] = depth
+		byTopic := report.ByTopic[topic]
+		byTopic.Forecasts++
+		report.ByTopic[topic] = byTopic
+
+		// Probability 1.0 belongs in the last bucket rather than one past it
+		idx := int(f.Probability * CalibrationBins)
+		if idx >= CalibrationBins {
+			idx = CalibrationBins - 1
+		}
+		bins[idx].Count++
+		bins[idx].MeanProbability += f.Probability
+		bins[idx].ObservedFrequency += outcome
+	}
+
+	if report.Overall.Forecasts > 0 {
+		report.Overall.BrierScore = total / float64(report.Overall.Forecasts)
+	}
+	for depth, score := range report.ByDepth {
+		score.BrierScore = depthTotals[depth] / float64(score.Forecasts)
+		report.ByDepth[depth] = score
+	}
+	for topic, score := range report.ByTopic {
+		score.BrierScore = topicTotals[topic] / float64(score.Forecasts)
+		report.ByTopic[topic] = score
+	}
+
+	// Empty buckets carry no information, so only populated ones are plotted
+	for _, bin := range bins {
+		if bin.Count == 0 {
+			continue
+		}
+		bin.MeanProbability /= float64(bin.Count)
+		bin.ObservedFrequency /= float64(bin.Count)
+		report.Curve = append(report.Curve, bin)
+	}
+	return report
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// Fallback classification used when no rule matches and the caller left fields blank
+const (
+	DefaultTaskCategory   = "uncategorized"
+	DefaultTaskUrgency    = models.PriorityMedium
+	DefaultTaskImportance = models.PriorityMedium
+	DefaultTaskQueue      = models.QueueAction
+)
+
+// TaskClassifier fills in missing task fields from the user-editable
+// classification rules table. Explicit values supplied by the caller always
+// win; rules only fill the gaps.
+type TaskClassifier struct {
+	db *database.DB
+}
+
+// NewTaskClassifier creates a new task classifier
+func NewTaskClassifier(db *database.DB) *TaskClassifier {
+	return &TaskClassifier{db: db}
+}
+
+// Classify evaluates enabled rules in order against the request description
+// and returns the resulting classification, including which rule fired and
+// which fields were filled in by the server.
+func (c *TaskClassifier) Classify(req *models.IngestTaskRequest) (*models.TaskClassification, error) {
+	rules, err := c.db.GetClassificationRules(true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.TaskClassification{
+		Category:   req.Category,
+		Urgency:    req.Urgency,
+		Importance: req.Importance,
+	}
+
+	// Defaults apply unless a rule matches below
+	category := DefaultTaskCategory
+	urgency := DefaultTaskUrgency
+	importance := DefaultTaskImportance
+	queue := DefaultTaskQueue
+
+	for _, rule := range rules {
+		matched, err := MatchRule(&rule, req.Description)
+		if err != nil {
+			// A broken stored pattern should not block ingestion
+			continue
+		}
+		if matched {
+			result.RuleID = rule.ID
+			result.RuleName = rule.Name
+			category, urgency, importance, queue = rule.Category, rule.Urgency, rule.Importance, rule.Queue
+			break
+		}
+	}
+
+	if result.Category == "" {
+		result.Category = category
+		result.Filled = append(result.Filled, "category")
+	}
+	if result.Urgency == "" {
+		result.Urgency = urgency
+		result.Filled = append(result.Filled, "urgency")
+	}
+	if result.Importance == "" {
+		result.Importance = importance
+		result.Filled = append(result.Filled, "importance")
+	}
+	result.Queue = queue
+
+	return result, nil
+}
+
+// MatchRule reports whether a rule's pattern matches the given text.
+// Keyword rules match case-insensitively on any comma-separated keyword;
+// regex rules use Go regular expression syntax.
+func MatchRule(rule *models.ClassificationRule, text string) (bool, error) {
+	switch rule.MatchType {
+	case models.RuleMatchKeyword:
+		lower := strings.ToLower(text)
+		for _, keyword := range strings.Split(rule.Pattern, ",") {
+			keyword = strings.ToLower(strings.TrimSpace(keyword))
+			if keyword != "" && strings.Contains(lower, keyword) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case models.RuleMatchRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(text), nil
+	default:
+		return false, fmt.Errorf("unknown match type: %s", rule.MatchType)
+	}
+}
+
+// ValidateRulePattern checks that a rule's pattern can be evaluated
+func ValidateRulePattern(matchType models.RuleMatchType, pattern string) error {
+	if matchType == models.RuleMatchRegex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+	return nil
+}
@@ -12,12 +12,15 @@ import (
 
 // CognitiveStateService provides high-level cognitive state operations
 type CognitiveStateService struct {
-	db *database.DB
+	db       *database.DB
+	guard    *RuminationGuard
+	emotions *EmotionService
+	assist   *AssistService
 }
 
 // NewCognitiveStateService creates a new cognitive state service
-func NewCognitiveStateService(db *database.DB) *CognitiveStateService {
-	return &CognitiveStateService{db: db}
+func NewCognitiveStateService(db *database.DB, guard *RuminationGuard, emotions *EmotionService, assist *AssistService) *CognitiveStateService {
+	return &CognitiveStateService{db: db, guard: guard, emotions: emotions, assist: assist}
 }
 
 // GetDashboardStatus aggregates all cognitive state into a single dashboard view.
@@ -36,6 +39,15 @@ func (s *CognitiveStateService) GetDashboardStatus() (*models.CognitiveStatus, e
 	if focus != nil {
 		status.CurrentFocus = focus.TaskName
 		status.FocusLocked = focus.IsLocked
+
+		// Show how far the assist session on this focus has got
+		session, err := s.assist.ForFocus(focus.ID)
+		if err != nil {
+			return nil, err
+		}
+		if session != nil {
+			status.Assist = AssistProgress(session)
+		}
 	}
 
 	// Get foreground threads
@@ -72,6 +84,13 @@ func (s *CognitiveStateService) GetDashboardStatus() (*models.CognitiveStatus, e
 	}
 	status.ActivePredictions = activePreds
 
+	// Flag topics that keep being restarted after a stop
+	ruminating, err := s.guard.FlaggedTopics(status.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	status.RuminatingTopics = ruminating
+
 	// Count pending tasks
 	pendingTasks, err := s.db.CountPendingTasks()
 	if err != nil {
@@ -87,7 +106,7 @@ func (s *CognitiveStateService) GetDashboardStatus() (*models.CognitiveStatus, e
 	status.CapturedIdeas = capturedIdeas
 
 	// Calculate emotional load based on recent emotional states
-	status.EmotionalLoad = s.calculateEmotionalLoad()
+	status.EmotionalLoad = s.calculateEmotionalLoad(status.Timestamp)
 
 	// Calculate energy level (heuristic based on open loops and active threads)
 	status.EnergyLevel = s.calculateEnergyLevel(openLoops, len(fgThreads), len(bgThreads))
@@ -95,47 +114,14 @@ func (s *CognitiveStateService) GetDashboardStatus() (*models.CognitiveStatus, e
 	return status, nil
 }
 
-// calculateEmotionalLoad determines the emotional load level based on recent emotional states
-func (s *CognitiveStateService) calculateEmotionalLoad() models.LoadLevel {
-	states, err := s.db.GetRecentEmotionalStates(5)
-	if err != nil || len(states) == 0 {
+// calculateEmotionalLoad determines the emotional load level from recent
+// emotional tags, weighted by taxonomy load weight and intensity and decayed by age
+func (s *CognitiveStateService) calculateEmotionalLoad(now time.Time) models.LoadLevel {
+	_, level, err := s.emotions.EmotionalLoad(now)
+	if err != nil {
 		return models.LoadLevelLow
 	}
-
-	// Count high-load emotions
-	highLoadEmotions := map[string]bool{
-		"angry":       true,
-		"anxious":     true,
-		"overwhelmed": true,
-		"stressed":    true,
-		"frustrated":  true,
-	}
-
-	mediumLoadEmotions := map[string]bool{
-		"tired":     true,
-		"resentful": true,
-		"worried":   true,
-		"uncertain": true,
-	}
-
-	highCount := 0
-	mediumCount := 0
-	for _, state := range states {
-		if highLoadEmotions[state.Label] {
-			highCount++
-		} else if mediumLoadEmotions[state.Label] {
-			mediumCount++
-		}
-	}
-
-	// Determine load level
-	if highCount >= 2 || (highCount >= 1 && mediumCount >= 2) {
-		return models.LoadLevelHigh
-	}
-	if highCount >= 1 || mediumCount >= 2 {
-		return models.LoadLevelMedium
-	}
-	return models.LoadLevelLow
+	return level
 }
 
 // calculateEnergyLevel determines energy level based on cognitive load indicators
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"humanos-api/internal/models"
+)
+
+// Decompression ranks decompression methods by how much they reduced tagged
+// load between the pre- and post-session check-ins. Sessions past their
+// planned end are completed first so they are counted.
+func (s *ReportService) Decompression(now time.Time) (*models.DecompressionReport, error) {
+	if _, err := s.db.CompleteExpiredDecompressSessions(now); err != nil {
+		return nil, err
+	}
+	sessions, err := s.db.GetDecompressSessions()
+	if err != nil {
+		return nil, err
+	}
+	weights, err := s.emotions.LoadWeights()
+	if err != nil {
+		return nil, err
+	}
+	return &models.DecompressionReport{
+		Methods:   ScoreDecompression(sessions, weights),
+		Timestamp: now,
+	}, nil
+}
+
+// ScoreDecompression groups finished sessions by method and averages the
+// change in check-in load. Completed and ended sessions with both check-ins
+// are measured; cancelled sessions are only counted. Methods are ordered by
+// average reduction, with unmeasured methods last.
+func ScoreDecompression(sessions []models.DecompressSession, weights map[string]float64) []models.DecompressMethodStats {
+	type totals struct {
+		pre, post float64
+	}
+	stats := make(map[string]*models.DecompressMethodStats)
+	sums := make(map[string]*totals)
+
+	for _, session := range sessions {
+		if session.Status == "active" {
+			continue
+		}
+		method := strings.ToLower(strings.TrimSpace(session.Method))
+		st, ok := stats[method]
+		if !ok {
+			st = &models.DecompressMethodStats{Method: method}
+			stats[method] = st
+			sums[method] = &totals{}
+		}
+		st.Sessions++
+		switch session.Status {
+		case "completed":
+			st.Completed++
+		case "ended":
+			st.Ended++
+		case "cancelled":
+			st.Cancelled++
+			continue
+		}
+
+		if session.PreCheckIn == nil || session.PostCheckIn == nil {
+			continue
+		}
+		pre := CheckInLoad(*session.PreCheckIn, weights)
+		post := CheckInLoad(*session.PostCheckIn, weights)
+		st.Measured++
+		sums[method].pre += pre
+		sums[method].post += post
+		if post < pre {
+			st.ReducedCount++
+		}
+	}
+
+	methods := make([]models.DecompressMethodStats, 0, len(stats))
+	for method, st := range stats {
+		if st.Measured > 0 {
+			n := float64(st.Measured)
+			pre := sums[method].pre / n
+			post := sums[method].post / n
+			reduction := pre - post
+			rate := float64(st.ReducedCount) / n
+			st.AvgPreLoad, st.AvgPostLoad = &pre, &post
+			st.AvgReduction, st.ReductionRate = &reduction, &rate
+		}
+		methods = append(methods, *st)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		a, b := methods[i].AvgReduction, methods[j].AvgReduction
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		if a != nil && *a != *b {
+			return *a > *b
+		}
+		return methods[i].Method < methods[j].Method
+	})
+	return methods
+}
+
+// CheckInLoad is the load a single check-in carries: its label's load
+// weight scaled by intensity, without decay
+func CheckInLoad(state models.EmotionalState, weights map[string]float64) float64 {
+	return EmotionLoadScore([]models.EmotionalState{state}, weights, 0, state.CreatedAt)
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// DefaultEmotionIntensity is assumed for emotions tagged without an intensity
+const DefaultEmotionIntensity = 5
+
+// Emotional load thresholds on the decayed, weighted intensity score. One
+// fresh high-load emotion at default intensity scores 0.5 (medium); two, or
+// one at full intensity, reach high.
+const (
+	mediumLoadScore = 0.5
+	highLoadScore   = 1.0
+)
+
+// emotionLookbackHalfLives bounds how far back states are read; after five
+// half-lives an emotion contributes about 3% of its original weight.
+const emotionLookbackHalfLives = 5
+
+// EmotionService resolves labels against the emotion taxonomy and computes
+// emotional load from recent tags
+type EmotionService struct {
+	db       *database.DB
+	halfLife time.Duration
+}
+
+// NewEmotionService creates a new emotion service. halfLife is how long it
+// takes a tagged emotion's contribution to emotional load to halve.
+func NewEmotionService(db *database.DB, halfLife time.Duration) *EmotionService {
+	return &EmotionService{db: db, halfLife: halfLife}
+}
+
+// Resolve maps a tagged label to its taxonomy entry, matching either the
+// label or one of its synonyms. Unknown labels return a nil definition and
+// the label normalized to lower case.
+func (s *EmotionService) Resolve(label string) (string, *models.EmotionDefinition, error) {
+	normalized := strings.ToLower(strings.TrimSpace(label))
+	taxonomy, err := s.db.GetEmotionTaxonomy()
+	if err != nil {
+		return "", nil, err
+	}
+	for i, def := range taxonomy {
+		if def.Label == normalized {
+			return def.Label, &taxonomy[i], nil
+		}
+		for _, synonym := range def.Synonyms {
+			if strings.ToLower(synonym) == normalized {
+				return def.Label, &taxonomy[i], nil
+			}
+		}
+	}
+	return normalized, nil, nil
+}
+
+// ValidateDefinition checks that a taxonomy entry's label and synonyms do not
+// collide with another entry, so every tag resolves to exactly one emotion
+func (s *EmotionService) ValidateDefinition(def *models.EmotionDefinition) error {
+	taxonomy, err := s.db.GetEmotionTaxonomy()
+	if err != nil {
+		return err
+	}
+	names := append([]string{def.Label}, def.Synonyms...)
+	for _, other := range taxonomy {
+		if other.Label == def.Label {
+			continue
+		}
+		taken := append([]string{other.Label}, other.Synonyms...)
+		for _, name := range names {
+			for _, t := range taken {
+				if strings.EqualFold(name, t) {
+					return fmt.Errorf("%q is already used by emotion %q", name, other.Label)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// EmotionalLoad scores recent emotional tags as of now and classifies the result
+func (s *EmotionService) EmotionalLoad(now time.Time) (float64, models.LoadLevel, error) {
+	states, err := s.db.GetEmotionalStatesSince(now.Add(-emotionLookbackHalfLives * s.halfLife))
+	if err != nil {
+		return 0, models.LoadLevelLow, err
+	}
+	weights, err := s.LoadWeights()
+	if err != nil {
+		return 0, models.LoadLevelLow, err
+	}
+	score := EmotionLoadScore(states, weights, s.halfLife, now)
+	return score, LoadLevelForScore(score), nil
+}
+
+// LoadWeights returns the taxonomy's load weight for each label
+func (s *EmotionService) LoadWeights() (map[string]float64, error) {
+	taxonomy, err := s.db.GetEmotionTaxonomy()
+	if err != nil {
+		return nil, err
+	}
+	weights := make(map[string]float64, len(taxonomy))
+	for _, def := range taxonomy {
+		weights[def.Label] = def.LoadWeight
+	}
+	return weights, nil
+}
+
+// EmotionLoadScore sums each state's load weight scaled by intensity (out of
+// 10) and decayed exponentially by age. Labels missing from weights add nothing.
+func EmotionLoadScore(states []models.EmotionalState, weights map[string]float64, halfLife time.Duration, now time.Time) float64 {
+	score := 0.0
+	for _, state := range states {
+		intensity := DefaultEmotionIntensity
+		if state.Intensity != nil {
+			intensity = *state.Intensity
+		}
+		decay := 1.0
+		if age := now.Sub(state.CreatedAt); age > 0 && halfLife > 0 {
+			decay = math.Pow(0.5, float64(age)/float64(halfLife))
+		}
+		score += weights[state.Label] * float64(intensity) / 10 * decay
+	}
+	return score
+}
+
+// LoadLevelForScore classifies an emotional load score
+func LoadLevelForScore(score float64) models.LoadLevel {
+	switch {
+	case score >= highLoadScore:
+		return models.LoadLevelHigh
+	case score >= mediumLoadScore:
+		return models.LoadLevelMedium
+	default:
+		return models.LoadLevelLow
+	}
+}
+
+// EmotionLoadClass classifies a single emotion's load weight as high, medium or low
+func EmotionLoadClass(weight float64) models.LoadLevel {
+	switch {
+	case weight >= 0.75:
+		return models.LoadLevelHigh
+	case weight >= 0.35:
+		return models.LoadLevelMedium
+	default:
+		return models.LoadLevelLow
+	}
+}
+
+// minCorrelationDays is the fewest days needed before a correlation is reported
+const minCorrelationDays = 3
+
+// Trends breaks down the emotions tagged within [from, to) and relates each
+// day's emotional load to the loops open at the end of that day and the share
+// of that day's focus sessions that were completed. Days, hours and weekdays
+// are taken in loc.
+func (s *EmotionService) Trends(from, to time.Time, loc *time.Location) (*models.EmotionTrends, error) {
+	states, err := s.db.GetEmotionalStates(from, to, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	weights, err := s.LoadWeights()
+	if err != nil {
+		return nil, err
+	}
+	loops, err := s.db.GetLoopsOpenDuring(from, to)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.db.GetFocusSessions(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	trends := &models.EmotionTrends{
+		From:      from,
+		To:        to,
+		TimeZone:  loc.String(),
+		Total:     len(states),
+		ByLabel:   make(map[string]int),
+		BySource:  make(map[string]int),
+		ByWeekday: make(map[string]int),
+		Daily:     []models.EmotionDay{},
+	}
+
+	// Build one bucket per calendar day in loc
+	dayIndex := make(map[string]int)
+	start := from.In(loc)
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		if end.After(to) {
+			end = to
+		}
+		open := 0
+		for _, loop := range loops {
+			if loop.CreatedAt.Before(end) && (loop.ClosedAt == nil || !loop.ClosedAt.Before(end)) {
+				open++
+			}
+		}
+		date := day.Format("2006-01-02")
+		dayIndex[date] = len(trends.Daily)
+		trends.Daily = append(trends.Daily, models.EmotionDay{Date: date, OpenLoops: open})
+	}
+
+	for _, state := range states {
+		local := state.CreatedAt.In(loc)
+		trends.ByLabel[state.Label]++
+		if source := strings.ToLower(strings.TrimSpace(state.SourceGuess)); source != "" {
+			trends.BySource[source]++
+		}
+		trends.ByHour[local.Hour()]++
+		trends.ByWeekday[local.Weekday().String()]++
+
+		if i, ok := dayIndex[local.Format("2006-01-02")]; ok {
+			trends.Daily[i].Tags++
+			trends.Daily[i].Load += EmotionLoadScore([]models.EmotionalState{state}, weights, 0, state.CreatedAt)
+		}
+	}
+
+	for _, focus := range sessions {
+		if i, ok := dayIndex[focus.StartedAt.In(loc).Format("2006-01-02")]; ok {
+			trends.Daily[i].FocusSessions++
+			if focus.Status == "completed" {
+				trends.Daily[i].FocusCompleted++
+			}
+		}
+	}
+
+	// Correlate load with open loops over every day, and with focus
+	// completion over the days that had at least one focus session
+	var loads, openLoops, focusLoads, completion []float64
+	for _, day := range trends.Daily {
+		loads = append(loads, day.Load)
+		openLoops = append(openLoops, float64(day.OpenLoops))
+		if day.FocusSessions > 0 {
+			focusLoads = append(focusLoads, day.Load)
+			completion = append(completion, float64(day.FocusCompleted)/float64(day.FocusSessions))
+		}
+	}
+	trends.Correlations.OpenLoops = pearson(loads, openLoops)
+	trends.Correlations.FocusCompletion = pearson(focusLoads, completion)
+
+	return trends, nil
+}
+
+// pearson returns the Pearson correlation coefficient of xs and ys, or nil
+// when there are too few points or either series is constant
+func pearson(xs, ys []float64) *float64 {
+	n := len(xs)
+	if n < minCorrelationDays || n != len(ys) {
+		return nil
+	}
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+	r := cov / math.Sqrt(varX*varY)
+	return &r
+}
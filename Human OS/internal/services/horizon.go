@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// horizonAmount matches "2 weeks", "in 3 days", "24h", "3-6 months" and "a month".
+// For ranges the upper bound is used, since that is when the outcome is known.
+var horizonAmount = regexp.MustCompile(
+	`^(?:in |within |next )?(?:(\d+|an?|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve)\s*(?:-|to)\s*)?` +
+		`(\d+|an?|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve)\s*` +
+		`(h|hrs?|hours?|d|days?|w|wks?|weeks?|months?|mos?|quarters?|y|yrs?|years?)$`)
+
+// horizonWords maps spelled-out counts to numbers
+var horizonWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+// ParseTimeHorizon turns a free-text time horizon into the date a prediction
+// should be resolved by. It understands relative amounts ("2 weeks", "24h",
+// "3-6 months"), calendar phrases ("tomorrow", "end of month", "next quarter")
+// and explicit dates (2006-01-02). A bare unit such as "months" is read as
+// three of that unit.
+func ParseTimeHorizon(horizon string, from time.Time) (time.Time, error) {
+	text := strings.Join(strings.Fields(strings.ToLower(horizon)), " ")
+	text = strings.TrimSuffix(text, ".")
+
+	if date, err := time.Parse("2006-01-02", text); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse(time.RFC3339, strings.ToUpper(text)); err == nil {
+		return date.UTC(), nil
+	}
+
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	switch text {
+	case "today", "tonight", "end of day", "eod":
+		return day.AddDate(0, 0, 1), nil
+	case "tomorrow":
+		return day.AddDate(0, 0, 2), nil
+	case "this week", "end of week", "eow":
+		// Weeks end on Sunday night
+		return day.AddDate(0, 0, (7-int(from.Weekday()))%7+1), nil
+	case "next week":
+		return from.AddDate(0, 0, 7), nil
+	case "this month", "end of month", "eom":
+		return time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, from.Location()), nil
+	case "next month":
+		return from.AddDate(0, 1, 0), nil
+	case "this quarter", "end of quarter", "eoq":
+		return quarterEnd(from), nil
+	case "next quarter":
+		return quarterEnd(from).AddDate(0, 3, 0), nil
+	case "this year", "end of year", "eoy":
+		return time.Date(from.Year()+1, 1, 1, 0, 0, 0, 0, from.Location()), nil
+	case "next year":
+		return from.AddDate(1, 0, 0), nil
+	case "hours", "days", "weeks", "months", "quarters", "years":
+		text = "3 " + text
+	}
+
+	m := horizonAmount.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("unrecognized time horizon %q", horizon)
+	}
+	n, ok := horizonWords[m[2]]
+	if !ok {
+		var err error
+		if n, err = strconv.Atoi(m[2]); err != nil {
+			return time.Time{}, fmt.Errorf("unrecognized time horizon %q", horizon)
+		}
+	}
+
+	switch unit := m[3]; {
+	case strings.HasPrefix(unit, "h"):
+		return from.Add(time.Duration(n) * time.Hour), nil
+	case strings.HasPrefix(unit, "d"):
+		return from.AddDate(0, 0, n), nil
+	case strings.HasPrefix(unit, "w"):
+		return from.AddDate(0, 0, 7*n), nil
+	case strings.HasPrefix(unit, "mo"):
+		return from.AddDate(0, n, 0), nil
+	case strings.HasPrefix(unit, "q"):
+		return from.AddDate(0, 3*n, 0), nil
+	default:
+		return from.AddDate(n, 0, 0), nil
+	}
+}
+
+// quarterEnd returns the first instant after the calendar quarter containing t
+func quarterEnd(t time.Time) time.Time {
+	firstMonth := time.Month((int(t.Month())-1)/3*3 + 1)
+	return time.Date(t.Year(), firstMonth+3, 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// MaxResurfacedLessons caps how many past lessons are shown when starting work
+const MaxResurfacedLessons = 3
+
+// lessonStopWords are dropped from resurfacing queries. Lesson search matches
+// any term, so common words would otherwise pull in unrelated archives.
+var lessonStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "do": true, "for": true, "from": true, "get": true,
+	"in": true, "into": true, "is": true, "it": true, "my": true, "of": true,
+	"on": true, "or": true, "our": true, "the": true, "this": true, "to": true,
+	"up": true, "with": true, "work": true, "task": true, "new": true,
+}
+
+// LessonService resurfaces archived lessons when related work starts, so
+// hard-won lessons are in front of you at the moment they can change the outcome.
+type LessonService struct {
+	db *database.DB
+}
+
+// NewLessonService creates a new lesson service
+func NewLessonService(db *database.DB) *LessonService {
+	return &LessonService{db: db}
+}
+
+// Resurface finds the lessons most related to the given text and records each
+// one shown so it can be rated. Usefulness ratings from earlier resurfacings
+// boost or demote a lesson relative to its text match score.
+func (s *LessonService) Resurface(contextType models.LessonContext, contextID, text string) ([]models.ResurfacedLesson, error) {
+	query := lessonQuery(text)
+	if query == "" {
+		return nil, nil
+	}
+
+	// Fetch more than needed so feedback can reorder before the cut
+	matches, err := s.db.SearchLessons(query, MaxResurfacedLessons*3)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+
+	archiveIDs := make([]string, len(matches))
+	for i, m := range matches {
+		archiveIDs[i] = m.Archive.ID
+	}
+	feedback, err := s.db.GetLessonFeedback(archiveIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	lessons := make([]models.ResurfacedLesson, len(matches))
+	for i, m := range matches {
+		f := feedback[m.Archive.ID]
+		lessons[i] = models.ResurfacedLesson{
+			ArchiveID:      m.Archive.ID,
+			Object:         m.Archive.Object,
+			Lesson:         m.Archive.Lesson,
+			Snippet:        m.Snippet,
+			Score:          m.Score * feedbackWeight(f),
+			UsefulCount:    f.UsefulCount,
+			NotUsefulCount: f.NotUsefulCount,
+		}
+	}
+	sort.SliceStable(lessons, func(i, j int) bool {
+		return lessons[i].Score > lessons[j].Score
+	})
+	if len(lessons) > MaxResurfacedLessons {
+		lessons = lessons[:MaxResurfacedLessons]
+	}
+
+	for i := range lessons {
+		lessons[i].ResurfacingID = uuid.New().String()
+		if err := s.db.CreateLessonResurfacing(
+			lessons[i].ResurfacingID, lessons[i].ArchiveID, contextType, contextID, lessons[i].Score,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return lessons, nil
+}
+
+// Rate records whether a resurfaced lesson was useful and returns the updated
+// feedback totals for its archive. Nil means the resurfacing was not found.
+func (s *LessonService) Rate(resurfacingID string, useful bool) (*models.LessonFeedback, error) {
+	archiveID, err := s.db.RateLessonResurfacing(resurfacingID, useful)
+	if err != nil || archiveID == "" {
+		return nil, err
+	}
+
+	feedback, err := s.db.GetLessonFeedback([]string{archiveID})
+	if err != nil {
+		return nil, err
+	}
+	f := feedback[archiveID]
+	f.ArchiveID = archiveID
+	return &f, nil
+}
+
+// feedbackWeight scales a match score by the lesson's rating history using a
+// smoothed useful ratio: unrated lessons keep their score (weight 1.0), and
+// the weight moves toward 0 or 2 as ratings accumulate.
+func feedbackWeight(f models.LessonFeedback) float64 {
+	ratio := float64(f.UsefulCount+1) / float64(f.UsefulCount+f.NotUsefulCount+2)
+	return 2 * ratio
+}
+
+// lessonQuery reduces free text to its significant words
+func lessonQuery(text string) string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		word = strings.Trim(word, ".,;:!?\"'()[]{}")
+		if len(word) < 3 || lessonStopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,458 @@
+package services
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"humanos-api/internal/ai"
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// offloadBatchSize is how many due offloads are considered per dispatch
+const offloadBatchSize = 100
+
+// maxRetryBackoff caps the doubling delay between retries
+const maxRetryBackoff = time.Hour
+
+// OffloadPolicy configures how the offload queue runs jobs. Zero
+// concurrency means unlimited.
+type OffloadPolicy struct {
+	Timeout         time.Duration  // how long a single attempt may run (0 = no limit)
+	Concurrency     int            // running offloads per task type
+	TypeConcurrency map[string]int // per task type overrides; "assist" covers every assist type
+	MaxAttempts     int            // attempts before an offload is marked failed
+	RetryBackoff    time.Duration  // delay before the first retry, doubled for each one after
+	PollInterval    time.Duration  // how often to look for due offloads and poll providers
+	Quorum          *ai.Quorum     // providers and strategy for quorum offloads (nil = unavailable)
+	RequireQuorum   bool           // run every offload (but not assist requests) in quorum mode
+}
+
+// runningOffload is an offload a worker is executing
+type runningOffload struct {
+	group     string
+	cancel    context.CancelFunc
+	cancelled bool // cancelled by the user; recorded as cancelled
+	draining  bool // interrupted by shutdown; returned to the queue
+}
+
+// OffloadService is the AI offload job queue. Offloads are persisted as
+// pending rows in ai_offloads; a dispatcher claims due ones and runs them on
+// worker goroutines, within per-task-type concurrency limits, retrying
+// failures with exponential backoff.
+type OffloadService struct {
+	db       *database.DB
+	provider ai.Provider
+	policy   OffloadPolicy
+
+	mu       sync.Mutex
+	running  map[string]*runningOffload
+	counts   map[string]int
+	finished []func(id string) // run when an offload reaches a final status
+
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	dispatch sync.WaitGroup
+	workers  sync.WaitGroup
+}
+
+// NewOffloadService creates a new offload queue. Call Start to begin running
+// offloads.
+func NewOffloadService(db *database.DB, provider ai.Provider, policy OffloadPolicy) *OffloadService {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.PollInterval <= 0 {
+		policy.PollInterval = time.Second
+	}
+	return &OffloadService{
+		db:       db,
+		provider: provider,
+		policy:   policy,
+		running:  make(map[string]*runningOffload),
+		counts:   make(map[string]int),
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+}
+
+// ProviderName returns the name of the configured provider
+func (s *OffloadService) ProviderName() string {
+	return s.provider.Name()
+}
+
+// Provider returns the configured provider, for AI features that run
+// outside the queue
+func (s *OffloadService) Provider() ai.Provider {
+	return s.provider
+}
+
+// Start requeues offloads left processing by a previous run and starts the
+// dispatcher in the background until Stop is called
+func (s *OffloadService) Start() {
+	if released, err := s.db.ReleaseAIOffloads(time.Now().UTC()); err != nil {
+		log.Printf("Offload queue: failed to requeue interrupted offloads: %v", err)
+	} else if released > 0 {
+		log.Printf("Offload queue: requeued %d interrupted offload(s)", released)
+	}
+
+	s.dispatch.Add(1)
+	go func() {
+		defer s.dispatch.Done()
+		ticker := time.NewTicker(s.policy.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.RunOnce(time.Now().UTC())
+			select {
+			case <-ticker.C:
+			case <-s.wake:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops claiming offloads and waits for running ones to finish. Offloads
+// still running when ctx ends are interrupted and returned to the queue, to
+// run again on the next start.
+func (s *OffloadService) Stop(ctx context.Context) {
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.dispatch.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	for _, r := range s.running {
+		r.draining = true
+		r.cancel()
+	}
+	s.mu.Unlock()
+	<-done
+}
+
+// QuorumAvailable reports whether quorum offloads can run
+func (s *OffloadService) QuorumAvailable() bool {
+	return s.policy.Quorum != nil && len(s.policy.Quorum.Members) > 1
+}
+
+// QuorumRequired reports whether every offload must run in quorum mode
+func (s *OffloadService) QuorumRequired() bool {
+	return s.policy.RequireQuorum
+}
+
+// Enqueue persists a new offload as pending and wakes the dispatcher. A
+// quorum offload records the strategy that will judge it.
+func (s *OffloadService) Enqueue(offload *models.AIOffload) error {
+	offload.Status = "pending"
+	if offload.Quorum && s.policy.Quorum != nil {
+		offload.Strategy = s.policy.Quorum.Strategy.Name()
+	}
+	if err := s.db.CreateAIOffload(offload); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// Review records a person's review of an offload awaiting review. A
+// rejected offload goes back in the queue, so the dispatcher is woken. It
+// reports false if the offload was not awaiting review.
+func (s *OffloadService) Review(review *models.AIOffloadReview) (bool, error) {
+	affected, err := s.db.ReviewAIOffload(review)
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	if review.Action == "rejected" {
+		s.notify()
+	}
+	return true, nil
+}
+
+// Cancel cancels a pending or running offload. A pending offload is
+// cancelled immediately; a running one is interrupted and recorded as
+// cancelled by its worker. It reports false if the offload was neither.
+// The lock is held across both checks, as offloads are claimed under it, so
+// one cannot start running in between.
+func (s *OffloadService) Cancel(id string) (bool, error) {
+	s.mu.Lock()
+	if r, ok := s.running[id]; ok {
+		r.cancelled = true
+		r.cancel()
+		s.mu.Unlock()
+		return true, nil
+	}
+	affected, err := s.db.CancelAIOffload(id, time.Now().UTC())
+	s.mu.Unlock()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	s.finish(id)
+	return true, nil
+}
+
+// onFinish registers fn to be called with the ID of each offload that
+// reaches a final status on the queue - awaiting review, completed, failed
+// or cancelled
+func (s *OffloadService) onFinish(fn func(id string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finished = append(s.finished, fn)
+}
+
+// finish calls the functions registered with onFinish for an offload,
+// outside the lock
+func (s *OffloadService) finish(id string) {
+	s.mu.Lock()
+	finished := slices.Clone(s.finished)
+	s.mu.Unlock()
+	for _, fn := range finished {
+		fn(id)
+	}
+}
+
+// RunOnce claims every due offload that fits within the concurrency limits
+// and starts a worker for it. Groups already at their limit are left out of
+// the query, so a backlog in one group cannot hide due offloads of others.
+func (s *OffloadService) RunOnce(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		due, err := s.db.GetDueAIOffloads(now, s.saturatedGroups(), offloadBatchSize)
+		if err != nil {
+			log.Printf("Offload queue: failed to list due offloads: %v", err)
+			return
+		}
+
+		skipped := false
+		for i := range due {
+			offload := &due[i]
+			group := OffloadGroup(offload.TaskType)
+			if limit := s.limit(group); limit > 0 && s.counts[group] >= limit {
+				skipped = true
+				continue
+			}
+
+			provider := s.provider.Name()
+			if offload.Quorum {
+				provider = "quorum"
+			}
+			claimed, err := s.db.ClaimAIOffload(offload.ID, provider, now)
+			if err != nil {
+				log.Printf("Offload queue: failed to claim offload %s: %v", offload.ID, err)
+				continue
+			}
+			if claimed == 0 {
+				continue
+			}
+			offload.Attempts++
+
+			ctx, cancel := context.WithCancel(context.Background())
+			s.running[offload.ID] = &runningOffload{group: group, cancel: cancel}
+			s.counts[group]++
+			s.workers.Add(1)
+			go s.work(ctx, *offload)
+		}
+
+		// A full batch that filled a group may have crowded out other groups;
+		// look again without it
+		if len(due) < offloadBatchSize || !skipped {
+			return
+		}
+	}
+}
+
+// saturatedGroups lists the groups running at their concurrency limit.
+// Caller must hold the lock.
+func (s *OffloadService) saturatedGroups() []string {
+	var groups []string
+	for group, count := range s.counts {
+		if limit := s.limit(group); limit > 0 && count >= limit {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// limit returns the concurrency limit for a task type group
+func (s *OffloadService) limit(group string) int {
+	if limit, ok := s.policy.TypeConcurrency[group]; ok {
+		return limit
+	}
+	return s.policy.Concurrency
+}
+
+// notify wakes the dispatcher without blocking
+func (s *OffloadService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// work runs one attempt of a claimed offload and records the outcome. A
+// successful offload waits for a person to review its result, unless it was
+// queued with SkipReview: assist requests, which support work the person is
+// doing themselves, complete directly.
+func (s *OffloadService) work(ctx context.Context, offload models.AIOffload) {
+	defer s.workers.Done()
+
+	if s.policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.policy.Timeout)
+		defer cancel()
+	}
+	output, failure := s.attempt(ctx, offload)
+
+	s.mu.Lock()
+	r := s.running[offload.ID]
+	delete(s.running, offload.ID)
+	s.counts[r.group]--
+	r.cancel()
+	s.mu.Unlock()
+
+	now := time.Now().UTC()
+	var err error
+	final := true
+	switch {
+	case r.draining:
+		_, err = s.db.ReleaseAIOffloads(now, offload.ID)
+		final = false
+	case r.cancelled:
+		_, err = s.db.FinishAIOffload(offload.ID, "cancelled", "", "", now)
+	case failure == "" && offload.SkipReview:
+		_, err = s.db.FinishAIOffload(offload.ID, "completed", output, "", now)
+	case failure == "":
+		_, err = s.db.FinishAIOffload(offload.ID, "awaiting_review", output, "", now)
+	case offload.Attempts < s.policy.MaxAttempts:
+		_, err = s.db.RetryAIOffload(offload.ID, failure, now.Add(RetryBackoff(s.policy.RetryBackoff, offload.Attempts)), now)
+		final = false
+	default:
+		_, err = s.db.FinishAIOffload(offload.ID, "failed", "", failure, now)
+	}
+	if err != nil {
+		log.Printf("Offload queue: failed to record offload %s: %v", offload.ID, err)
+	} else if final {
+		s.finish(offload.ID)
+	}
+
+	// A slot is free; look for more work
+	s.notify()
+}
+
+// attempt runs an offload once, on the provider or in quorum, returning its
+// output or why the attempt failed
+func (s *OffloadService) attempt(ctx context.Context, offload models.AIOffload) (string, string) {
+	req := OffloadRequest(offload)
+	if !offload.Quorum {
+		result, err := ai.Run(ctx, s.provider, req, s.policy.PollInterval)
+		if err != nil {
+			return "", err.Error()
+		}
+		if result.Status != ai.StatusCompleted {
+			return "", string(result.Status) + ": " + result.Error
+		}
+		return result.Output, ""
+	}
+
+	if !s.QuorumAvailable() {
+		return "", "quorum is not configured"
+	}
+	votes, decision, err := s.policy.Quorum.Run(ctx, req, s.policy.PollInterval)
+	if ctx.Err() == nil {
+		if recordErr := s.db.RecordAIOffloadVotes(QuorumVotes(offload, votes)); recordErr != nil {
+			log.Printf("Offload queue: failed to record votes for offload %s: %v", offload.ID, recordErr)
+		}
+	}
+	if err != nil {
+		return "", err.Error()
+	}
+	if !decision.Reached {
+		return "", "quorum not reached (" + s.policy.Quorum.Strategy.Name() + "): " + decision.Reason
+	}
+	return decision.Output, ""
+}
+
+// QuorumVotes converts a quorum attempt's votes into vote records
+func QuorumVotes(offload models.AIOffload, votes []ai.Vote) []models.AIOffloadVote {
+	now := time.Now().UTC()
+	records := make([]models.AIOffloadVote, 0, len(votes))
+	for _, vote := range votes {
+		records = append(records, models.AIOffloadVote{
+			ID:        uuid.New().String(),
+			OffloadID: offload.ID,
+			Attempt:   offload.Attempts,
+			Member:    vote.Member,
+			Status:    string(vote.Status),
+			Output:    vote.Output,
+			Error:     vote.Error,
+			Agrees:    vote.Agrees,
+			CreatedAt: now,
+		})
+	}
+	return records
+}
+
+// RetryBackoff is the delay before retrying after the given attempt:
+// base, doubled for each attempt after the first, capped at an hour
+func RetryBackoff(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
+
+// OffloadGroup is the task type an offload's concurrency limit is counted
+// under. Assist requests, including help with stuck steps, share one
+// "assist" group.
+func OffloadGroup(taskType string) string {
+	if strings.HasPrefix(taskType, "assist:") || taskType == ai.TaskUnstick {
+		return "assist"
+	}
+	return taskType
+}
+
+// OffloadRequest builds the provider request for an offload from the
+// prompt rendered when it was queued. Assist requests, which are not
+// templated, are logged with an "assist:<type>" task type and carry their
+// own instructions. A rejected offload carries its previous result and the
+// reviewer's feedback into the prompt.
+func OffloadRequest(offload models.AIOffload) ai.Request {
+	system, prompt := offload.Instructions, offload.Prompt
+	if assistType, isAssist := strings.CutPrefix(offload.TaskType, "assist:"); isAssist && system == "" {
+		system = "The user is doing this task themselves. Help only with: " + assistType + ". Be brief."
+	} else if system == "" {
+		system = "Help with the following task."
+	}
+	if prompt == "" {
+		prompt = offload.Scope
+	}
+
+	if offload.Feedback != "" {
+		prompt += "\n\nPrevious response:\n" + offload.Result + "\n\nReviewer feedback:\n" + offload.Feedback
+	}
+	return ai.Request{TaskType: offload.TaskType, System: system, Prompt: prompt}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"humanos-api/internal/ai"
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// maxRelatedLoops caps how many open loops are given to a prompt as context
+const maxRelatedLoops = 5
+
+// promptVariable matches a {{name}} placeholder in a template body
+var promptVariable = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// PromptVariables are the placeholders a template body may use
+var PromptVariables = []string{"scope", "task_type", "focus", "loops", "goals"}
+
+// PromptService fills offload prompt templates with the offload's scope and
+// the context around it: what is in focus, which open loops it touches and
+// which goals it serves. The provider sees the same picture you do.
+type PromptService struct {
+	db *database.DB
+}
+
+// NewPromptService creates a new prompt service. The imported Telos goals
+// are offered to every prompt.
+func NewPromptService(db *database.DB) *PromptService {
+	return &PromptService{db: db}
+}
+
+// ValidateTemplate checks that a template can be rendered: it must place the
+// scope somewhere and use only known variables
+func (s *PromptService) ValidateTemplate(t *models.PromptTemplate) error {
+	if t.TaskType == "" || ai.ReservedTaskType(t.TaskType) {
+		return fmt.Errorf("task type %q cannot have a template", t.TaskType)
+	}
+	found := false
+	for _, match := range promptVariable.FindAllStringSubmatch(t.Body, -1) {
+		if !isPromptVariable(match[1]) {
+			return fmt.Errorf("unknown variable {{%s}}; use one of %s", match[1], strings.Join(PromptVariables, ", "))
+		}
+		found = found || match[1] == "scope"
+	}
+	if !found {
+		return fmt.Errorf("body must include {{scope}}")
+	}
+	return nil
+}
+
+// Render fills in the offload's instructions and prompt from the current
+// template for its task type and records the version used. Task types
+// without a template are sent with general instructions and the scope alone
+// as version 0.
+func (s *PromptService) Render(offload *models.AIOffload) error {
+	template, err := s.db.GetPromptTemplate(offload.TaskType, 0)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		offload.TemplateVersion = 0
+		offload.Instructions = "Help with the following task."
+		offload.Prompt = offload.Scope
+		return nil
+	}
+
+	focus, err := s.db.GetCurrentFocus()
+	if err != nil {
+		return err
+	}
+	loops, err := s.db.GetOpenLoops()
+	if err != nil {
+		return err
+	}
+	goals, err := s.db.GetTelosItems("GOALS")
+	if err != nil {
+		return err
+	}
+
+	vars := map[string]string{
+		"scope":     offload.Scope,
+		"task_type": offload.TaskType,
+		"focus":     "none",
+		"loops":     "none",
+		"goals":     "none",
+	}
+	if focus != nil {
+		vars["focus"] = focus.TaskName
+		if focus.SuccessCriteria != "" {
+			vars["focus"] += " (done when: " + focus.SuccessCriteria + ")"
+		}
+	}
+	if related := RelatedLoops(offload.Scope, loops); len(related) > 0 {
+		lines := make([]string, len(related))
+		for i, loop := range related {
+			lines[i] = "- " + loop.Description + " [" + string(loop.Priority) + "]"
+			if loop.NextStep != "" {
+				lines[i] += " next: " + loop.NextStep
+			}
+		}
+		vars["loops"] = strings.Join(lines, "\n")
+	}
+	if len(goals) > 0 {
+		lines := make([]string, len(goals))
+		for i, goal := range goals {
+			lines[i] = "- " + goal.ID + ": " + goal.Text
+		}
+		vars["goals"] = strings.Join(lines, "\n")
+	}
+
+	offload.TemplateVersion = template.Version
+	offload.Instructions = template.Instructions
+	offload.Prompt = RenderTemplate(template.Body, vars)
+	return nil
+}
+
+// RenderTemplate replaces each {{name}} placeholder with its value. Unknown
+// placeholders are left as they are.
+func RenderTemplate(body string, vars map[string]string) string {
+	return promptVariable.ReplaceAllStringFunc(body, func(placeholder string) string {
+		name := promptVariable.FindStringSubmatch(placeholder)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// RelatedLoops returns the open loops sharing a significant word with the
+// scope, most shared words first, at most maxRelatedLoops
+func RelatedLoops(scope string, loops []models.Loop) []models.Loop {
+	terms := strings.Fields(lessonQuery(scope))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		loop  models.Loop
+		score int
+	}
+	var matches []scored
+	for _, loop := range loops {
+		words := make(map[string]bool)
+		for _, word := range strings.Fields(lessonQuery(loop.Description)) {
+			words[word] = true
+		}
+		score := 0
+		for _, term := range terms {
+			if words[term] {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{loop, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	var related []models.Loop
+	for i := 0; i < len(matches) && i < maxRelatedLoops; i++ {
+		related = append(related, matches[i].loop)
+	}
+	return related
+}
+
+// isPromptVariable reports whether name is a known template variable
+func isPromptVariable(name string) bool {
+	for _, v := range PromptVariables {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,373 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// labelActions nudges recommendations towards what a just-tagged emotion
+// usually calls for: worry feeds on running predictions, tiredness on
+// pushing through, overwhelm on too many open loops
+var labelActions = map[string]models.RecommendationAction{
+	"anxious":     models.ActionStopPrediction,
+	"worried":     models.ActionStopPrediction,
+	"uncertain":   models.ActionStopPrediction,
+	"tired":       models.ActionRest,
+	"overwhelmed": models.ActionLoopCleanup,
+	"stressed":    models.ActionDecompress,
+	"angry":       models.ActionDecompress,
+	"frustrated":  models.ActionDecompress,
+}
+
+// labelBoost is added to the score of the action a tagged emotion calls for
+const labelBoost = 15
+
+// busyLoopCount is the number of open loops above which cleanup is suggested
+const busyLoopCount = 7
+
+// defaultDecompressMethods is used until a method has measured sessions
+var defaultDecompressMethods = map[string]string{
+	"morning":   "walk",
+	"afternoon": "walk",
+	"evening":   "music",
+	"night":     "silence",
+}
+
+// RecommendationInput is everything a recommendation is based on
+type RecommendationInput struct {
+	Context     models.RecommendationContext
+	Label       string                         // just-tagged emotion, if any
+	Methods     []models.DecompressMethodStats // ranked by effectiveness
+	Predictions []models.Prediction            // running, oldest first
+	Loops       []models.Loop                  // open loops
+	Now         time.Time
+}
+
+// RecommendationService suggests a concrete next action from current
+// emotional load, energy, open loops, time of day and what has worked before
+type RecommendationService struct {
+	db        *database.DB
+	cognitive *CognitiveStateService
+	emotions  *EmotionService
+	reports   *ReportService
+	loc       *time.Location
+}
+
+// NewRecommendationService creates a new recommendation service. loc is the
+// time zone used to judge time of day.
+func NewRecommendationService(db *database.DB, cognitive *CognitiveStateService, emotions *EmotionService, reports *ReportService, loc *time.Location) *RecommendationService {
+	return &RecommendationService{db: db, cognitive: cognitive, emotions: emotions, reports: reports, loc: loc}
+}
+
+// Location returns the default time zone for time of day
+func (s *RecommendationService) Location() *time.Location {
+	return s.loc
+}
+
+// Recommend gathers the current state and returns every applicable
+// recommendation, most urgent first, along with the state it was based on.
+// label is a just-tagged emotion to take into account; loc overrides the
+// default time zone when set.
+func (s *RecommendationService) Recommend(now time.Time, loc *time.Location, label string) ([]models.Recommendation, models.RecommendationContext, error) {
+	if loc == nil {
+		loc = s.loc
+	}
+	in := RecommendationInput{Label: label, Now: now}
+
+	decompression, err := s.reports.Decompression(now)
+	if err != nil {
+		return nil, in.Context, err
+	}
+	in.Methods = decompression.Methods
+
+	status, err := s.cognitive.GetDashboardStatus()
+	if err != nil {
+		return nil, in.Context, err
+	}
+	score, level, err := s.emotions.EmotionalLoad(now)
+	if err != nil {
+		return nil, in.Context, err
+	}
+	active, err := s.db.GetActiveDecompressSession()
+	if err != nil {
+		return nil, in.Context, err
+	}
+	if in.Predictions, err = s.db.GetRunningPredictions(); err != nil {
+		return nil, in.Context, err
+	}
+	if in.Loops, err = s.db.GetOpenLoops(); err != nil {
+		return nil, in.Context, err
+	}
+
+	hour := now.In(loc).Hour()
+	in.Context = models.RecommendationContext{
+		EmotionalLoad:     level,
+		LoadScore:         score,
+		EnergyLevel:       status.EnergyLevel,
+		OpenLoops:         status.OpenLoopsEstimate,
+		ActivePredictions: status.ActivePredictions,
+		RuminatingTopics:  status.RuminatingTopics,
+		Decompressing:     active != nil,
+		TimeOfDay:         TimeOfDay(hour),
+		Hour:              hour,
+	}
+	return BuildRecommendations(in), in.Context, nil
+}
+
+// MethodInsight describes how a decompression method has worked before and
+// names a method that has worked better, if there is one
+func (s *RecommendationService) MethodInsight(method string, now time.Time) (string, error) {
+	report, err := s.reports.Decompression(now)
+	if err != nil {
+		return "", err
+	}
+	return DescribeMethod(method, report.Methods), nil
+}
+
+// BuildRecommendations scores every applicable next action and returns
+// them most urgent first. There is always at least one recommendation.
+func BuildRecommendations(in RecommendationInput) []models.Recommendation {
+	ctx := in.Context
+	night := ctx.TimeOfDay == "night"
+	loadAtLeastMedium := ctx.EmotionalLoad == models.LoadLevelMedium || ctx.EmotionalLoad == models.LoadLevelHigh
+	var recs []models.Recommendation
+
+	// Stop a prediction: ruminating topics first, then the oldest running one
+	if len(ctx.RuminatingTopics) > 0 {
+		topic := ctx.RuminatingTopics[0]
+		recs = append(recs, models.Recommendation{
+			Action:   models.ActionStopPrediction,
+			Title:    fmt.Sprintf("Stop simulating %q", topic),
+			Reason:   "You stopped this topic before and keep coming back to it. That is rumination, not planning.",
+			Endpoint: "DELETE /api/v1/predict/stop",
+			Params:   map[string]string{"topic": topic},
+			Score:    90,
+		})
+	} else if len(in.Predictions) > 0 && loadAtLeastMedium {
+		oldest := in.Predictions[0]
+		topic := oldest.Topic
+		if topic == "" {
+			topic = oldest.Scenario
+		}
+		score := 60
+		if ctx.EmotionalLoad == models.LoadLevelHigh {
+			score = 70
+		}
+		recs = append(recs, models.Recommendation{
+			Action: models.ActionStopPrediction,
+			Title:  fmt.Sprintf("Stop simulating %q", topic),
+			Reason: fmt.Sprintf("%d prediction(s) still running while emotional load is %s; this one has been running for %s.",
+				len(in.Predictions), ctx.EmotionalLoad, humanDuration(in.Now.Sub(oldest.CreatedAt))),
+			Endpoint: "DELETE /api/v1/predict/stop",
+			Params:   map[string]string{"topic": topic},
+			Score:    score,
+		})
+	}
+
+	// Decompress, using the method that has reduced load the most
+	if loadAtLeastMedium && !ctx.Decompressing {
+		score, duration := 55, "10m"
+		if ctx.EmotionalLoad == models.LoadLevelHigh {
+			score, duration = 80, "20m"
+		}
+		if night {
+			duration = "10m"
+		}
+		method, reason := defaultDecompressMethods[ctx.TimeOfDay], "No measured sessions yet - check in before and after to learn what works for you."
+		for _, m := range in.Methods {
+			if m.AvgReduction != nil && *m.AvgReduction > 0 {
+				method = m.Method
+				reason = fmt.Sprintf("%s has reduced your load by %.2f on average over %d session(s).", m.Method, *m.AvgReduction, m.Measured)
+				break
+			}
+		}
+		recs = append(recs, models.Recommendation{
+			Action:   models.ActionDecompress,
+			Title:    fmt.Sprintf("Decompress: %s for %s", method, duration),
+			Reason:   fmt.Sprintf("Emotional load is %s. %s", ctx.EmotionalLoad, reason),
+			Endpoint: "POST /api/v1/emotion/decompress",
+			Params:   map[string]string{"method": method, "duration": duration},
+			Score:    score,
+		})
+	}
+
+	// Soft reset when load and depletion compound each other
+	if ctx.EnergyLevel == models.LoadLevelLow && (ctx.EmotionalLoad == models.LoadLevelHigh || ctx.OpenLoops > 2*busyLoopCount) {
+		score := 65
+		if ctx.EmotionalLoad == models.LoadLevelHigh {
+			score = 75
+		}
+		recs = append(recs, models.Recommendation{
+			Action: models.ActionSoftReset,
+			Title:  "Soft reset",
+			Reason: fmt.Sprintf("Energy is low with %d open loops and %s emotional load. Clear the active state and start again from the archive.",
+				ctx.OpenLoops, ctx.EmotionalLoad),
+			Endpoint: "POST /api/v1/mode/reset-soft",
+			Score:    score,
+		})
+	}
+
+	// Loop cleanup, starting with the oldest loop that matters least
+	if ctx.OpenLoops > busyLoopCount || (in.Label != "" && labelActions[in.Label] == models.ActionLoopCleanup && len(in.Loops) > 0) {
+		score := 40 + 2*(ctx.OpenLoops-busyLoopCount)
+		if score > 75 {
+			score = 75
+		}
+		if score < 40 {
+			score = 40
+		}
+		rec := models.Recommendation{
+			Action:   models.ActionLoopCleanup,
+			Title:    fmt.Sprintf("Close or drop one of %d open loops", ctx.OpenLoops),
+			Reason:   "Every open loop holds a little attention. Closing the least important ones frees it.",
+			Endpoint: "POST /api/v1/loop/close",
+			Score:    score,
+		}
+		if loop := cleanupCandidate(in.Loops); loop != nil {
+			rec.Title = fmt.Sprintf("Close or drop %q", loop.Description)
+			rec.Params = map[string]string{"loop_id": loop.ID, "closure_type": string(models.ClosureAbandoned)}
+		}
+		recs = append(recs, rec)
+	}
+
+	// Rest at night, or when drained
+	if (night && (loadAtLeastMedium || ctx.EnergyLevel == models.LoadLevelLow)) ||
+		ctx.EnergyLevel == models.LoadLevelLow || in.Label == "tired" {
+		score, title := 45, "Take a real break"
+		if night {
+			score, title = 70, "Stop for the day"
+		}
+		recs = append(recs, models.Recommendation{
+			Action: models.ActionRest,
+			Title:  title,
+			Reason: fmt.Sprintf("It's %s, energy is %s and emotional load is %s. Rest is the highest-leverage move.",
+				ctx.TimeOfDay, ctx.EnergyLevel, ctx.EmotionalLoad),
+			Score: score,
+		})
+	}
+
+	// Focus when there is capacity for it
+	if ctx.EmotionalLoad == models.LoadLevelLow && ctx.EnergyLevel != models.LoadLevelLow && !night {
+		duration := "25m"
+		if ctx.TimeOfDay == "morning" && ctx.EnergyLevel == models.LoadLevelHigh {
+			duration = "50m"
+		}
+		recs = append(recs, models.Recommendation{
+			Action:   models.ActionFocus,
+			Title:    fmt.Sprintf("Start a %s focus block", duration),
+			Reason:   fmt.Sprintf("Emotional load is low and energy is %s - a good time for deep work.", ctx.EnergyLevel),
+			Endpoint: "POST /api/v1/focus/set",
+			Params:   map[string]string{"duration": duration},
+			Score:    30,
+		})
+	}
+
+	if len(recs) == 0 {
+		recs = append(recs, models.Recommendation{
+			Action: models.ActionRest,
+			Title:  "Wind down",
+			Reason: fmt.Sprintf("It's %s. Nothing needs attention right now.", ctx.TimeOfDay),
+			Score:  20,
+		})
+	}
+
+	if action, ok := labelActions[in.Label]; ok {
+		for i := range recs {
+			if recs[i].Action == action {
+				recs[i].Score += labelBoost
+				recs[i].Reason += fmt.Sprintf(" You just tagged %s.", in.Label)
+			}
+		}
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].Score > recs[j].Score })
+	return recs
+}
+
+// DescribeMethod summarizes a decompression method's track record and points
+// to a method that has reduced load more, if any
+func DescribeMethod(method string, methods []models.DecompressMethodStats) string {
+	var own, best *models.DecompressMethodStats
+	for i := range methods {
+		m := &methods[i]
+		if m.Method == method {
+			own = m
+		}
+		if best == nil && m.AvgReduction != nil && *m.AvgReduction > 0 {
+			best = m
+		}
+	}
+
+	if own == nil || own.AvgReduction == nil {
+		return "No measured " + method + " sessions yet. Check in before and after to learn whether it works for you."
+	}
+	var insight string
+	if *own.AvgReduction > 0 {
+		insight = fmt.Sprintf("Past %s sessions reduced your load by %.2f on average (%d measured).", method, *own.AvgReduction, own.Measured)
+	} else {
+		insight = fmt.Sprintf("Past %s sessions have not reduced your load on average (%d measured).", method, own.Measured)
+	}
+	if best != nil && best.Method != method && *best.AvgReduction > *own.AvgReduction {
+		insight += fmt.Sprintf(" %s has worked better (%.2f average reduction).", best.Method, *best.AvgReduction)
+	}
+	return insight
+}
+
+// TimeOfDay buckets an hour (0-23) into morning, afternoon, evening or night
+func TimeOfDay(hour int) string {
+	switch {
+	case hour >= 5 && hour < 12:
+		return "morning"
+	case hour >= 12 && hour < 17:
+		return "afternoon"
+	case hour >= 17 && hour < 22:
+		return "evening"
+	default:
+		return "night"
+	}
+}
+
+// cleanupCandidate picks the loop most worth dropping: backburner before
+// reference before action, then lowest priority, then oldest
+func cleanupCandidate(loops []models.Loop) *models.Loop {
+	queueRank := map[models.QueueType]int{models.QueueBackburner: 0, models.QueueReference: 1, models.QueueAction: 2}
+	priorityRank := map[models.Priority]int{models.PriorityLow: 0, models.PriorityMedium: 1, models.PriorityHigh: 2}
+
+	var best *models.Loop
+	for i := range loops {
+		loop := &loops[i]
+		if best == nil {
+			best = loop
+			continue
+		}
+		switch {
+		case queueRank[loop.Queue] != queueRank[best.Queue]:
+			if queueRank[loop.Queue] < queueRank[best.Queue] {
+				best = loop
+			}
+		case priorityRank[loop.Priority] != priorityRank[best.Priority]:
+			if priorityRank[loop.Priority] < priorityRank[best.Priority] {
+				best = loop
+			}
+		case loop.CreatedAt.Before(best.CreatedAt):
+			best = loop
+		}
+	}
+	return best
+}
+
+// humanDuration renders a duration in the largest whole unit
+func humanDuration(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	}
+}
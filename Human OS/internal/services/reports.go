@@ -0,0 +1,283 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// ReportService assembles periodic reports from existing cognitive data
+type ReportService struct {
+	db       *database.DB
+	emotions *EmotionService
+}
+
+// NewReportService creates a new report service
+func NewReportService(db *database.DB, emotions *EmotionService) *ReportService {
+	return &ReportService{db: db, emotions: emotions}
+}
+
+// ReportWindow returns the [start, end) range covered by a period ending at now
+func ReportWindow(period models.ReportPeriod, now time.Time) (time.Time, time.Time, error) {
+	switch period {
+	case models.ReportPeriodWeek:
+		return now.AddDate(0, 0, -7), now, nil
+	case models.ReportPeriodMonth:
+		return now.AddDate(0, -1, 0), now, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q (use week or month)", period)
+	}
+}
+
+// Retro builds a retrospective for the period ending at now
+func (s *ReportService) Retro(period models.ReportPeriod, now time.Time) (*models.RetroReport, error) {
+	start, end, err := ReportWindow(period, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.RetroReport{
+		Period:      period,
+		Start:       start,
+		End:         end,
+		GeneratedAt: now,
+	}
+
+	// Loops opened vs closed
+	if report.Loops.Opened, err = s.db.CountLoopsOpened(start, end); err != nil {
+		return nil, err
+	}
+	if report.Loops.ClosedByType, err = s.db.CountLoopsClosedByType(start, end); err != nil {
+		return nil, err
+	}
+	for _, count := range report.Loops.ClosedByType {
+		report.Loops.Closed += count
+	}
+	if report.Loops.StillOpen, err = s.db.CountOpenLoops(); err != nil {
+		return nil, err
+	}
+
+	// Focus time
+	sessions, err := s.db.GetFocusSessions(start, end)
+	if err != nil {
+		return nil, err
+	}
+	report.Focus.Sessions = len(sessions)
+	report.Focus.SessionsList = make([]models.FocusState, 0, len(sessions))
+	for _, session := range sessions {
+		if session.Status == "completed" {
+			report.Focus.Completed++
+		}
+		report.Focus.TotalMinutes += int(FocusTimeSpent(&session, now).Minutes())
+		report.Focus.SessionsList = append(report.Focus.SessionsList, session)
+	}
+
+	// Emotional tags
+	if report.Emotions, err = s.db.CountEmotionLabels(start, end); err != nil {
+		return nil, err
+	}
+	weights, err := s.emotions.LoadWeights()
+	if err != nil {
+		return nil, err
+	}
+	report.EmotionalLoad = periodEmotionalLoad(report.Emotions, weights)
+
+	// Archived lessons
+	if report.Lessons, err = s.db.GetArchivedLessons(start, end); err != nil {
+		return nil, err
+	}
+	if report.Lessons == nil {
+		report.Lessons = []models.Archive{}
+	}
+
+	// Predictions
+	if report.Predictions.Started, err = s.db.CountPredictionsStarted(start, end); err != nil {
+		return nil, err
+	}
+	if report.Predictions.Abandoned, err = s.db.GetStoppedPredictions(start, end); err != nil {
+		return nil, err
+	}
+	if report.Predictions.Abandoned == nil {
+		report.Predictions.Abandoned = []models.Prediction{}
+	}
+
+	// AI offloads
+	offloads, err := s.db.GetAIOffloadsBetween(start, end)
+	if err != nil {
+		return nil, err
+	}
+	report.AIOffloads.Total = len(offloads)
+	report.AIOffloads.ByType = make(map[string]int)
+	report.AIOffloads.ByStatus = make(map[string]int)
+	for _, offload := range offloads {
+		report.AIOffloads.ByType[offload.TaskType]++
+		report.AIOffloads.ByStatus[offload.Status]++
+	}
+
+	return report, nil
+}
+
+// FocusTimeSpent estimates how long a focus session actually ran: until it
+// was completed, otherwise until its planned end (or now, if still running)
+func FocusTimeSpent(focus *models.FocusState, now time.Time) time.Duration {
+	end := focus.EndsAt
+	if focus.CompletedAt != nil {
+		end = *focus.CompletedAt
+	} else if end.After(now) {
+		end = now
+	}
+	if end.Before(focus.StartedAt) {
+		return 0
+	}
+	return end.Sub(focus.StartedAt)
+}
+
+// RenderRetroMarkdown renders a retrospective using the Daily Ops Check
+// layout from the DevOps spec, so daily and periodic reviews read the same way.
+func RenderRetroMarkdown(r *models.RetroReport) string {
+	var b strings.Builder
+	title := "Weekly"
+	if r.Period == models.ReportPeriodMonth {
+		title = "Monthly"
+	}
+	fmt.Fprintf(&b, "## %s Retro — %s to %s\n\n", title,
+		r.Start.Format("2006-01-02"), r.End.Format("2006-01-02"))
+
+	b.WriteString("### System Status\n")
+	fmt.Fprintf(&b, "- Emotional Load: %s\n", emotionalLoadCheckbox(r.EmotionalLoad))
+	fmt.Fprintf(&b, "- Focus Time: %s across %d sessions (%d completed)\n\n",
+		formatMinutes(r.Focus.TotalMinutes), r.Focus.Sessions, r.Focus.Completed)
+
+	b.WriteString("### Open Loops (Memory Pressure)\n")
+	fmt.Fprintf(&b, "- Opened: %d\n", r.Loops.Opened)
+	fmt.Fprintf(&b, "- Closed: %d (done %d, paused %d, abandoned %d)\n",
+		r.Loops.Closed,
+		r.Loops.ClosedByType[models.ClosureDone],
+		r.Loops.ClosedByType[models.ClosurePaused],
+		r.Loops.ClosedByType[models.ClosureAbandoned])
+	fmt.Fprintf(&b, "- Still open: %d\n\n", r.Loops.StillOpen)
+
+	b.WriteString("### Definition of Done\n")
+	wrote := false
+	for _, session := range r.Focus.SessionsList {
+		if session.Status != "completed" {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s: Done when %s\n", session.TaskName, session.SuccessCriteria)
+		wrote = true
+	}
+	if !wrote {
+		b.WriteString("- No focus sessions completed\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("### Load Management\n")
+	fmt.Fprintf(&b, "- %s Offloaded to AI/tools: %d%s\n",
+		checkbox(r.AIOffloads.Total > 0), r.AIOffloads.Total, formatCounts(r.AIOffloads.ByType))
+	fmt.Fprintf(&b, "- %s Abandoned predictions: %d\n",
+		checkbox(len(r.Predictions.Abandoned) > 0), len(r.Predictions.Abandoned))
+	for _, pred := range r.Predictions.Abandoned {
+		fmt.Fprintf(&b, "  - %s (%s, %s)\n", pred.Scenario, pred.Depth, pred.TimeHorizon)
+	}
+	fmt.Fprintf(&b, "- %s Loops paused for later: %d\n\n",
+		checkbox(r.Loops.ClosedByType[models.ClosurePaused] > 0),
+		r.Loops.ClosedByType[models.ClosurePaused])
+
+	b.WriteString("### Emotional Tags\n")
+	if len(r.Emotions) == 0 {
+		b.WriteString("- None tagged\n")
+	}
+	for _, label := range sortedByCount(r.Emotions) {
+		fmt.Fprintf(&b, "- %s: %d\n", label, r.Emotions[label])
+	}
+	b.WriteString("\n")
+
+	b.WriteString("### Notes\n")
+	if len(r.Lessons) == 0 {
+		b.WriteString("- No lessons archived\n")
+	}
+	for _, lesson := range r.Lessons {
+		fmt.Fprintf(&b, "- **%s**: %s\n", lesson.Object, lesson.Lesson)
+	}
+
+	return b.String()
+}
+
+// periodEmotionalLoad classifies a period's load from the share of tags
+// whose taxonomy weight makes them high or medium load
+func periodEmotionalLoad(emotions map[string]int, weights map[string]float64) models.LoadLevel {
+	high, medium, total := 0, 0, 0
+	for label, count := range emotions {
+		total += count
+		switch EmotionLoadClass(weights[label]) {
+		case models.LoadLevelHigh:
+			high += count
+		case models.LoadLevelMedium:
+			medium += count
+		}
+	}
+	if total > 0 {
+		switch {
+		case high*3 >= total:
+			return models.LoadLevelHigh
+		case (high+medium)*3 >= total:
+			return models.LoadLevelMedium
+		}
+	}
+	return models.LoadLevelLow
+}
+
+// emotionalLoadCheckbox marks the load level on the Daily Ops Check scale
+func emotionalLoadCheckbox(level models.LoadLevel) string {
+	return fmt.Sprintf("%s Low %s Medium %s High",
+		checkbox(level == models.LoadLevelLow),
+		checkbox(level == models.LoadLevelMedium),
+		checkbox(level == models.LoadLevelHigh))
+}
+
+// checkbox renders a Markdown task-list box
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// formatMinutes renders minutes as "3h 20m"
+func formatMinutes(minutes int) string {
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
+
+// formatCounts renders a breakdown like " (plan 2, draft 1)"
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(counts))
+	for _, key := range sortedByCount(counts) {
+		parts = append(parts, fmt.Sprintf("%s %d", key, counts[key]))
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// sortedByCount returns map keys ordered by descending count, then name
+func sortedByCount(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
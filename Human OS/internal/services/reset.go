@@ -0,0 +1,196 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// hardResetConfirmWindow is how long a hard reset confirmation token is valid
+const hardResetConfirmWindow = 5 * time.Minute
+
+// ResetService plans and runs resets. A soft reset is always planned first:
+// the plan lists exactly what will change, and is either returned as a dry
+// run or applied as listed. Every reset and restore snapshots all tables in
+// the same transaction, so it can be undone, and a hard reset only runs with
+// a confirmation token handed out by an earlier request. The AI offload
+// queue is not snapshotted, so a restore never disturbs running jobs.
+type ResetService struct {
+	db            *database.DB
+	keepSnapshots int
+
+	mu            sync.Mutex
+	confirmTokens map[string]time.Time // token -> when it expires
+}
+
+// NewResetService creates a new reset service keeping the newest
+// keepSnapshots snapshots
+func NewResetService(db *database.DB, keepSnapshots int) *ResetService {
+	return &ResetService{db: db, keepSnapshots: keepSnapshots, confirmTokens: make(map[string]time.Time)}
+}
+
+// PlanSoftReset lists what a soft reset scoped by req would change, without
+// changing anything
+func (s *ResetService) PlanSoftReset(req models.SoftResetRequest) (*models.SoftResetChanges, error) {
+	changes := &models.SoftResetChanges{
+		Focus:          []models.FocusState{},
+		Loops:          []models.Loop{},
+		Threads:        []models.Thread{},
+		Predictions:    []models.Prediction{},
+		ProtectedLoops: []models.Loop{},
+	}
+
+	if inScope(req.Domains, models.ResetDomainFocus) {
+		focuses, err := s.db.GetActiveFocuses()
+		if err != nil {
+			return nil, err
+		}
+		changes.Focus = append(changes.Focus, focuses...)
+	}
+
+	if inScope(req.Domains, models.ResetDomainLoops) {
+		loops, err := s.db.GetOpenLoops()
+		if err != nil {
+			return nil, err
+		}
+		for _, loop := range loops {
+			switch {
+			case !inScope(req.LoopQueues, loop.Queue):
+			case req.ProtectHighPriority && loop.Priority == models.PriorityHigh:
+				changes.ProtectedLoops = append(changes.ProtectedLoops, loop)
+			default:
+				changes.Loops = append(changes.Loops, loop)
+			}
+		}
+	}
+
+	if inScope(req.Domains, models.ResetDomainThreads) {
+		threads, err := s.db.GetActiveThreads()
+		if err != nil {
+			return nil, err
+		}
+		for _, thread := range threads {
+			if inScope(req.ThreadModes, thread.Mode) {
+				changes.Threads = append(changes.Threads, thread)
+			}
+		}
+	}
+
+	if inScope(req.Domains, models.ResetDomainPredictions) {
+		predictions, err := s.db.GetRunningPredictions()
+		if err != nil {
+			return nil, err
+		}
+		changes.Predictions = append(changes.Predictions, predictions...)
+	}
+
+	return changes, nil
+}
+
+// SoftReset plans a soft reset scoped by req and, unless it is a dry run,
+// snapshots every table and applies it. The changes returned are the ones
+// listed by the plan; the snapshot is nil for a dry run.
+func (s *ResetService) SoftReset(req models.SoftResetRequest, now time.Time) (*models.SoftResetChanges, *models.StateSnapshot, error) {
+	changes, err := s.PlanSoftReset(req)
+	if err != nil || req.DryRun {
+		return changes, nil, err
+	}
+	snapshot, err := s.db.SoftReset(changes, uuid.New().String(), now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return changes, snapshot, s.prune()
+}
+
+// RequestHardReset hands out a single-use token that confirms a hard reset
+// until the time returned
+func (s *ResetService) RequestHardReset(now time.Time) (string, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token, expires := range s.confirmTokens {
+		if !now.Before(expires) {
+			delete(s.confirmTokens, token)
+		}
+	}
+	token, expires := uuid.New().String(), now.Add(hardResetConfirmWindow)
+	s.confirmTokens[token] = expires
+	return token, expires
+}
+
+// HardReset snapshots every table and wipes all data, if token is an
+// unexpired token from RequestHardReset. It reports false, changing
+// nothing, if the token is not valid. A token is used up either way.
+func (s *ResetService) HardReset(token string, now time.Time) (*models.StateSnapshot, bool, error) {
+	s.mu.Lock()
+	expires, ok := s.confirmTokens[token]
+	delete(s.confirmTokens, token)
+	s.mu.Unlock()
+	if !ok || !now.Before(expires) {
+		return nil, false, nil
+	}
+
+	snapshot, err := s.db.HardReset(uuid.New().String(), now)
+	if err != nil {
+		return nil, true, err
+	}
+	return snapshot, true, s.prune()
+}
+
+// Restore puts every table back as it was when a snapshot was taken,
+// snapshotting the current state first so the restore can be undone too.
+// It returns that undo snapshot. AI offloads are left as they are.
+func (s *ResetService) Restore(id string, now time.Time) (*models.StateSnapshot, error) {
+	undo, err := s.db.RestoreSnapshot(id, uuid.New().String(), now)
+	if err != nil {
+		return nil, err
+	}
+	return undo, s.prune()
+}
+
+// prune deletes snapshots beyond the retention limit, oldest first
+func (s *ResetService) prune() error {
+	_, err := s.db.PruneSnapshots(s.keepSnapshots)
+	return err
+}
+
+// inScope reports whether a soft reset scope covers a domain, queue or
+// mode. An empty scope covers everything.
+func inScope[T comparable](scope []T, value T) bool {
+	return len(scope) == 0 || slices.Contains(scope, value)
+}
+
+// DescribeSoftReset summarizes a soft reset's changes in a sentence, e.g.
+// "focus cleared, 3 loop(s) abandoned, 1 high-priority loop(s) kept open"
+func DescribeSoftReset(changes *models.SoftResetChanges) string {
+	var parts []string
+	switch n := len(changes.Focus); {
+	case n == 1:
+		parts = append(parts, "focus cleared")
+	case n > 1:
+		parts = append(parts, fmt.Sprintf("%d focus session(s) cleared", n))
+	}
+	if n := len(changes.Loops); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d loop(s) abandoned", n))
+	}
+	if n := len(changes.ProtectedLoops); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d high-priority loop(s) kept open", n))
+	}
+	if n := len(changes.Threads); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d thread(s) terminated", n))
+	}
+	if n := len(changes.Predictions); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d prediction(s) stopped", n))
+	}
+	if len(parts) == 0 {
+		return "nothing to reset"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// RuminationPolicy bounds how much mental simulation can run at once.
+// Zero caps and budgets mean unlimited.
+type RuminationPolicy struct {
+	MaxRunning       map[string]int           // concurrent running predictions per depth
+	Budgets          map[string]time.Duration // thinking time per depth before auto-stop
+	RestartThreshold int                      // restarts after a stop that flag a topic
+	Window           time.Duration            // how far back stops are considered
+}
+
+// RuminationGuard treats runaway prediction as the resource drain it is:
+// it caps concurrent simulations, gives each a time budget, and notices
+// topics that keep coming back after being deliberately stopped.
+type RuminationGuard struct {
+	db     *database.DB
+	policy RuminationPolicy
+}
+
+// NewRuminationGuard creates a new rumination guard
+func NewRuminationGuard(db *database.DB, policy RuminationPolicy) *RuminationGuard {
+	return &RuminationGuard{db: db, policy: policy}
+}
+
+// StartPrediction records a new running prediction if another may run at
+// its depth, with a reason when it may not. Predictions past their budget
+// are stopped first so they never hold a slot between scheduler runs. The
+// cap is checked in the same transaction as the insert, so concurrent
+// requests cannot both take the last slot.
+func (g *RuminationGuard) StartPrediction(prediction *models.Prediction) (bool, string, error) {
+	limit := g.policy.MaxRunning[prediction.Depth]
+	if limit > 0 {
+		if _, err := g.db.StopExpiredPredictions(time.Now().UTC()); err != nil {
+			return false, "", err
+		}
+	}
+	created, running, err := g.db.CreatePredictionWithinLimit(prediction, limit)
+	if err != nil || created {
+		return created, "", err
+	}
+	return false, fmt.Sprintf("%d of %d %s predictions already running - stop or resolve one first",
+		running, limit, prediction.Depth), nil
+}
+
+// BudgetEndsAt returns when a prediction started now should be auto-stopped.
+// overrideMinutes replaces the depth's default budget when positive.
+func (g *RuminationGuard) BudgetEndsAt(depth string, overrideMinutes int, now time.Time) *time.Time {
+	budget := g.policy.Budgets[depth]
+	if overrideMinutes > 0 {
+		budget = time.Duration(overrideMinutes) * time.Minute
+	}
+	if budget <= 0 {
+		return nil
+	}
+	ends := now.Add(budget)
+	return &ends
+}
+
+// RecordStop remembers that a topic was stopped so restarts can be detected
+func (g *RuminationGuard) RecordStop(topic string, stopped int64, now time.Time) error {
+	return g.db.RecordPredictionStop(uuid.New().String(), topic, stopped, now)
+}
+
+// Report lists topics stopped within the window, most restarted first,
+// flagging those restarted at least RestartThreshold times
+func (g *RuminationGuard) Report(now time.Time) (*models.RuminationReport, error) {
+	topics, err := g.db.GetRuminationTopics(now.Add(-g.policy.Window))
+	if err != nil {
+		return nil, err
+	}
+	if topics == nil {
+		topics = []models.RuminationTopic{}
+	}
+	for i := range topics {
+		topics[i].Flagged = g.policy.RestartThreshold > 0 && topics[i].Restarts >= g.policy.RestartThreshold
+	}
+	sort.SliceStable(topics, func(i, j int) bool {
+		return topics[i].Restarts > topics[j].Restarts
+	})
+
+	return &models.RuminationReport{
+		WindowDays: int(g.policy.Window.Hours() / 24),
+		Threshold:  g.policy.RestartThreshold,
+		Topics:     topics,
+		Timestamp:  now,
+	}, nil
+}
+
+// FlaggedTopics returns the topics currently flagged as rumination
+func (g *RuminationGuard) FlaggedTopics(now time.Time) ([]string, error) {
+	report, err := g.Report(now)
+	if err != nil {
+		return nil, err
+	}
+	var flagged []string
+	for _, topic := range report.Topics {
+		if topic.Flagged {
+			flagged = append(flagged, topic.Topic)
+		}
+	}
+	return flagged, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"humanos-api/internal/models"
+)
+
+// probabilityTolerance allows for rounding when sibling probabilities are summed
+const probabilityTolerance = 1e-6
+
+// BuildScenarioTree assembles flat branches into a tree, computes each
+// branch's path probability and the expected value across leaves, and warns
+// about sibling probabilities that do not add up to 1.
+func BuildScenarioTree(pred *models.Prediction, branches []models.ScenarioBranch) *models.ScenarioTree {
+	tree := &models.ScenarioTree{
+		PredictionID: pred.ID,
+		Scenario:     pred.Scenario,
+		Branches:     []models.ScenarioBranch{},
+		Leaves:       []models.ScenarioLeaf{},
+	}
+
+	children := make(map[string][]models.ScenarioBranch)
+	for _, b := range branches {
+		children[b.ParentID] = append(children[b.ParentID], b)
+	}
+
+	var build func(parentID string, parentProb float64, path []string) []models.ScenarioBranch
+	build = func(parentID string, parentProb float64, path []string) []models.ScenarioBranch {
+		siblings := children[parentID]
+		if len(siblings) == 0 {
+			return nil
+		}
+
+		sum := 0.0
+		nodes := make([]models.ScenarioBranch, len(siblings))
+		for i, b := range siblings {
+			sum += b.Probability
+			b.PathProbability = parentProb * b.Probability
+			branchPath := append(append([]string{}, path...), b.Outcome)
+			b.Children = build(b.ID, b.PathProbability, branchPath)
+			if len(b.Children) == 0 {
+				tree.Leaves = append(tree.Leaves, models.ScenarioLeaf{
+					BranchID:    b.ID,
+					Path:        branchPath,
+					Probability: b.PathProbability,
+					Value:       b.Value,
+				})
+			}
+			nodes[i] = b
+		}
+
+		if math.Abs(sum-1) > probabilityTolerance {
+			where := "top-level branches"
+			if len(path) > 0 {
+				where = fmt.Sprintf("branches under %q", path[len(path)-1])
+			}
+			tree.Warnings = append(tree.Warnings,
+				fmt.Sprintf("Probabilities of %s sum to %.2f, not 1", where, sum))
+		}
+		return nodes
+	}
+	if roots := build("", 1, nil); roots != nil {
+		tree.Branches = roots
+	}
+
+	if len(tree.Leaves) == 0 {
+		return tree
+	}
+	ev := 0.0
+	for _, leaf := range tree.Leaves {
+		if leaf.Value == nil {
+			tree.Warnings = append(tree.Warnings,
+				fmt.Sprintf("Leaf %q has no value; expected value not computed", strings.Join(leaf.Path, " > ")))
+			return tree
+		}
+		ev += leaf.Probability * *leaf.Value
+	}
+	tree.ExpectedValue = &ev
+	return tree
+}
+
+// RenderScenarioDOT renders a scenario tree as a Graphviz digraph. Edges carry
+// the conditional probability; nodes show the outcome, the planned response
+// and, for leaves, the value.
+func RenderScenarioDOT(tree *models.ScenarioTree) string {
+	var b strings.Builder
+	b.WriteString("digraph scenario {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+
+	rootLabel := tree.Scenario
+	if tree.ExpectedValue != nil {
+		rootLabel += fmt.Sprintf("\nEV: %.2f", *tree.ExpectedValue)
+	}
+	fmt.Fprintf(&b, "  root [label=%s, shape=ellipse];\n", dotQuote(rootLabel))
+
+	var write func(parent string, branches []models.ScenarioBranch)
+	write = func(parent string, branches []models.ScenarioBranch) {
+		for _, branch := range branches {
+			label := branch.Outcome
+			if branch.Response != "" {
+				label += "\nthen: " + branch.Response
+			}
+			if len(branch.Children) == 0 && branch.Value != nil {
+				label += fmt.Sprintf("\nvalue: %g", *branch.Value)
+			}
+			fmt.Fprintf(&b, "  %s [label=%s];\n", dotQuote(branch.ID), dotQuote(label))
+			fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", parent, dotQuote(branch.ID),
+				dotQuote(fmt.Sprintf("%s (%.0f%%)", branch.Condition, branch.Probability*100)))
+			write(dotQuote(branch.ID), branch.Children)
+		}
+	}
+	write("root", tree.Branches)
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote quotes a string as a DOT ID, escaping quotes and newlines
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
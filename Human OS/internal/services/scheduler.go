@@ -0,0 +1,91 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"humanos-api/internal/database"
+)
+
+// PredictionScheduler periodically checks for predictions whose time horizon
+// has passed. It logs a reminder for those awaiting resolution and stops "low"
+// depth predictions left unresolved past a grace period - a quick scan that
+// nobody came back to is not worth keeping in the background. It also stops
+// predictions that have used up their thinking-time budget.
+type PredictionScheduler struct {
+	db            *database.DB
+	interval      time.Duration
+	lowDepthGrace time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewPredictionScheduler creates a new prediction scheduler
+func NewPredictionScheduler(db *database.DB, interval, lowDepthGrace time.Duration) *PredictionScheduler {
+	return &PredictionScheduler{
+		db:            db,
+		interval:      interval,
+		lowDepthGrace: lowDepthGrace,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in the background until Stop is called.
+// A non-positive interval disables it.
+func (s *PredictionScheduler) Start() {
+	if s.interval <= 0 {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.RunOnce(time.Now().UTC())
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the scheduler and waits for an in-flight check to finish
+func (s *PredictionScheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// RunOnce performs a single check as of now. It returns how many predictions
+// are due for resolution and how many were auto-stopped.
+func (s *PredictionScheduler) RunOnce(now time.Time) (int, int64) {
+	expired, err := s.db.StopExpiredPredictions(now)
+	if err != nil {
+		log.Printf("Prediction scheduler: failed to stop expired predictions: %v", err)
+	} else if expired > 0 {
+		log.Printf("Prediction scheduler: auto-stopped %d prediction(s) over their time budget", expired)
+	}
+
+	stopped, err := s.db.StopOverduePredictions("low", now.Add(-s.lowDepthGrace))
+	if err != nil {
+		log.Printf("Prediction scheduler: failed to stop overdue predictions: %v", err)
+	} else if stopped > 0 {
+		log.Printf("Prediction scheduler: auto-stopped %d unresolved low-depth prediction(s)", stopped)
+	}
+
+	due, err := s.db.GetDuePredictions(now)
+	if err != nil {
+		log.Printf("Prediction scheduler: failed to list due predictions: %v", err)
+		return 0, expired + stopped
+	}
+	if len(due) > 0 {
+		log.Printf("Prediction scheduler: %d prediction(s) past their horizon awaiting resolution (GET /api/v1/predictions/due)", len(due))
+	}
+	return len(due), expired + stopped
+}
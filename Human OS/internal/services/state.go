@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"humanos-api/internal/ai"
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// statePollInterval is how often a state summary job is polled
+const statePollInterval = 100 * time.Millisecond
+
+// StateSummary is the outcome of a state summary
+type StateSummary struct {
+	Package      models.StatePackage
+	Briefing     models.StateBriefing
+	Unstructured bool // the provider's reply was not a briefing; it is kept as the summary
+}
+
+// StateSummaryService asks the AI provider for a briefing on everything that
+// is open: loops, pending tasks, captured ideas and active threads. Holding
+// all of it in your head is the load; a briefing lets you put it down.
+type StateSummaryService struct {
+	db       *database.DB
+	provider ai.Provider
+	timeout  time.Duration
+}
+
+// NewStateSummaryService creates a new state summary service. timeout
+// bounds each summary (0 = no limit).
+func NewStateSummaryService(db *database.DB, provider ai.Provider, timeout time.Duration) *StateSummaryService {
+	return &StateSummaryService{db: db, provider: provider, timeout: timeout}
+}
+
+// ProviderName returns the name of the provider summaries run on
+func (s *StateSummaryService) ProviderName() string {
+	return s.provider.Name()
+}
+
+// Package gathers the open work into the context package sent to the
+// provider
+func (s *StateSummaryService) Package(now time.Time) (models.StatePackage, error) {
+	pkg := models.StatePackage{Items: []models.StateItem{}}
+	age := func(t time.Time) int {
+		return int(now.Sub(t).Hours() / 24)
+	}
+
+	loops, err := s.db.GetOpenLoops()
+	if err != nil {
+		return pkg, err
+	}
+	for _, loop := range loops {
+		pkg.Items = append(pkg.Items, models.StateItem{
+			ID: loop.ID, Kind: "loop", Text: loop.Description, Priority: string(loop.Priority),
+			Queue: string(loop.Queue), Detail: loop.NextStep, AgeDays: age(loop.CreatedAt),
+		})
+	}
+
+	tasks, err := s.db.GetPendingTasks()
+	if err != nil {
+		return pkg, err
+	}
+	for _, task := range tasks {
+		pkg.Items = append(pkg.Items, models.StateItem{
+			ID: task.ID, Kind: "task", Text: task.Description,
+			Priority: string(task.Urgency) + "/" + string(task.Importance),
+			Queue:    string(task.Queue), Detail: task.Category, AgeDays: age(task.CreatedAt),
+		})
+	}
+
+	ideas, err := s.db.GetCapturedIdeas()
+	if err != nil {
+		return pkg, err
+	}
+	for _, idea := range ideas {
+		detail := idea.Storage
+		if idea.ActionNow {
+			detail = "act now"
+		}
+		pkg.Items = append(pkg.Items, models.StateItem{
+			ID: idea.ID, Kind: "idea", Text: idea.Summary, Detail: detail, AgeDays: age(idea.CreatedAt),
+		})
+	}
+
+	threads, err := s.db.GetActiveThreads()
+	if err != nil {
+		return pkg, err
+	}
+	for _, thread := range threads {
+		pkg.Items = append(pkg.Items, models.StateItem{
+			ID: thread.ID, Kind: "thread", Text: thread.Name, Detail: string(thread.Mode),
+			AgeDays: age(thread.CreatedAt),
+		})
+	}
+	return pkg, nil
+}
+
+// Summarize sends the context package to the provider and returns its
+// briefing. Priorities and closures naming items that are not in the
+// package are dropped, and each kept entry takes its text from the package.
+func (s *StateSummaryService) Summarize(ctx context.Context, now time.Time) (*StateSummary, error) {
+	pkg, err := s.Package(now)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := json.Marshal(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	result, err := ai.Run(ctx, s.provider, ai.Request{
+		TaskType: ai.TaskSummarizeState,
+		System:   ai.SummarizeStateInstructions,
+		Prompt:   string(prompt),
+	}, statePollInterval)
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != ai.StatusCompleted {
+		return nil, fmt.Errorf("summary %s: %s", result.Status, result.Error)
+	}
+
+	summary := &StateSummary{Package: pkg}
+	briefing, err := ai.ParseBriefing(result.Output)
+	if err != nil {
+		summary.Unstructured = true
+		summary.Briefing = models.StateBriefing{
+			Summary:    result.Output,
+			Priorities: []models.BriefingItem{},
+			Closures:   []models.ClosureSuggestion{},
+		}
+		return summary, nil
+	}
+
+	items := make(map[string]models.StateItem, len(pkg.Items))
+	for _, item := range pkg.Items {
+		items[item.ID] = item
+	}
+	summary.Briefing = models.StateBriefing{
+		Summary:    briefing.Summary,
+		Priorities: []models.BriefingItem{},
+		Closures:   []models.ClosureSuggestion{},
+	}
+	for _, p := range briefing.Priorities {
+		if item, ok := items[p.ID]; ok {
+			p.Kind, p.Text = item.Kind, item.Text
+			summary.Briefing.Priorities = append(summary.Briefing.Priorities, p)
+		}
+	}
+	for _, c := range briefing.Closures {
+		if item, ok := items[c.ID]; ok {
+			c.Kind, c.Text = item.Kind, item.Text
+			summary.Briefing.Closures = append(summary.Briefing.Closures, c)
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"time"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+	"humanos-api/internal/telos"
+)
+
+// TelosService keeps the imported Telos personal context: the problems,
+// mission, goals and the rest of the chain that work is linked to, so any
+// loop, thread, focus session or task can be traced back to why it matters.
+type TelosService struct {
+	db *database.DB
+}
+
+// NewTelosService creates a new Telos service
+func NewTelosService(db *database.DB) *TelosService {
+	return &TelosService{db: db}
+}
+
+// Import replaces the stored context with the items of a parsed Telos file
+func (s *TelosService) Import(doc *telos.Document, now time.Time) error {
+	items := make([]models.TelosItem, len(doc.Items))
+	for i, item := range doc.Items {
+		items[i] = models.TelosItem{ID: item.ID, Section: item.Section, Text: item.Text, Refs: item.Refs}
+	}
+	return s.db.ImportTelosItems(items, now)
+}
+
+// Trace returns an item and the chain above it, up to the problems it
+// serves, nearest first. The item is nil if no item has the ID.
+func (s *TelosService) Trace(id string) (*models.TelosItem, []models.TelosItem, error) {
+	stored, err := s.db.GetTelosItems("")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc := &telos.Document{}
+	byID := make(map[string]models.TelosItem, len(stored))
+	for _, item := range stored {
+		doc.Items = append(doc.Items, telos.Item{ID: item.ID, Section: item.Section, Text: item.Text, Refs: item.Refs})
+		byID[item.ID] = item
+	}
+	found := doc.Item(id)
+	if found == nil {
+		return nil, nil, nil
+	}
+
+	item := byID[found.ID]
+	trace := []models.TelosItem{}
+	for _, parent := range doc.Trace(found.ID) {
+		trace = append(trace, byID[parent.ID])
+	}
+	return &item, trace, nil
+}
+
+// SectionCounts counts a document's items per section
+func SectionCounts(doc *telos.Document) map[string]int {
+	counts := make(map[string]int)
+	for _, item := range doc.Items {
+		counts[item.Section]++
+	}
+	return counts
+}
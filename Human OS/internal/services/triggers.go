@@ -0,0 +1,483 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"humanos-api/internal/database"
+	"humanos-api/internal/models"
+)
+
+// sourceSeparator splits a source guess into the separate things it blames.
+// The separator is kept so a part that follows "with" can be read as a person.
+var sourceSeparator = regexp.MustCompile(`(?i)\s*(,|;|&|/|\band\b|\bwith\b|\babout\b)\s*`)
+
+// triggerFillerWords are dropped from the start of a trigger name, so
+// "my boss" and "the boss" both name the trigger "boss"
+var triggerFillerWords = map[string]bool{
+	"a": true, "an": true, "the": true, "my": true, "our": true,
+	"his": true, "her": true, "their": true, "your": true,
+}
+
+// personWords mark a source guess part as a person
+var personWords = map[string]bool{
+	"boss": true, "manager": true, "colleague": true, "coworker": true, "client": true,
+	"customer": true, "partner": true, "wife": true, "husband": true, "mom": true,
+	"mother": true, "dad": true, "father": true, "sister": true, "brother": true,
+	"friend": true, "son": true, "daughter": true, "kids": true, "neighbor": true,
+	"landlord": true,
+}
+
+// TriggerService turns free-text source guesses into named triggers and
+// relates them to the loops and threads they mention
+type TriggerService struct {
+	db       *database.DB
+	emotions *EmotionService
+}
+
+// NewTriggerService creates a new trigger service
+func NewTriggerService(db *database.DB, emotions *EmotionService) *TriggerService {
+	return &TriggerService{db: db, emotions: emotions}
+}
+
+// Detect splits a source guess into parts and resolves each part to a
+// trigger, matching existing names and aliases first. Unmatched parts become
+// new triggers whose kind is guessed: a person if the part follows "with",
+// is an @handle or names a relationship; a project if an open loop or active
+// thread mentions it; otherwise a context.
+func (s *TriggerService) Detect(source string, now time.Time) ([]models.Trigger, error) {
+	triggers, err := s.db.GetTriggers()
+	if err != nil {
+		return nil, err
+	}
+
+	var detected []models.Trigger
+	seen := make(map[string]bool)
+	for _, part := range splitSource(source) {
+		words := TriggerWords(part.text)
+		matched := false
+		for _, trigger := range triggers {
+			if TriggerMatches(trigger, words) {
+				matched = true
+				if !seen[trigger.ID] {
+					seen[trigger.ID] = true
+					detected = append(detected, trigger)
+				}
+			}
+		}
+		if matched {
+			continue
+		}
+
+		name := NormalizeTriggerName(part.text)
+		if name == "" {
+			continue
+		}
+		kind, err := s.guessKind(part, name)
+		if err != nil {
+			return nil, err
+		}
+		trigger, err := s.db.GetOrCreateTrigger(&models.Trigger{
+			ID:        uuid.New().String(),
+			Name:      name,
+			Kind:      kind,
+			Aliases:   []string{},
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !seen[trigger.ID] {
+			seen[trigger.ID] = true
+			detected = append(detected, *trigger)
+		}
+	}
+	return detected, nil
+}
+
+// TagState detects the triggers in an emotional state's source guess and
+// links them to the state
+func (s *TriggerService) TagState(state *models.EmotionalState) ([]models.Trigger, error) {
+	triggers, err := s.Detect(state.SourceGuess, state.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(triggers))
+	for i, trigger := range triggers {
+		ids[i] = trigger.ID
+	}
+	if err := s.db.LinkEmotionTriggers(state.ID, ids); err != nil {
+		return nil, err
+	}
+	return triggers, nil
+}
+
+// Backfill detects triggers for emotional states tagged within [since, until)
+// that have none yet, such as states tagged before triggers existed or whose
+// trigger was deleted. It returns how many states were linked.
+func (s *TriggerService) Backfill(since, until time.Time) (int, error) {
+	states, err := s.db.GetUnlinkedEmotionalStates(since, until)
+	if err != nil {
+		return 0, err
+	}
+	for i := range states {
+		if _, err := s.TagState(&states[i]); err != nil {
+			return 0, err
+		}
+	}
+	return len(states), nil
+}
+
+// Save validates and stores a trigger, creating it when create is set.
+// Naming another trigger as an alias merges that trigger into this one; the
+// merged names are returned. A name or alias already used as another
+// trigger's alias is rejected.
+func (s *TriggerService) Save(trigger *models.Trigger, create bool) ([]string, error) {
+	triggers, err := s.db.GetTriggers()
+	if err != nil {
+		return nil, err
+	}
+
+	var merge []models.Trigger
+	for _, other := range triggers {
+		if other.ID == trigger.ID {
+			continue
+		}
+		if other.Name == trigger.Name {
+			return nil, fmt.Errorf("trigger %q already exists; add it as an alias to merge", other.Name)
+		}
+		for _, alias := range other.Aliases {
+			if alias == trigger.Name || containsString(trigger.Aliases, alias) {
+				return nil, fmt.Errorf("%q is already an alias of trigger %q", alias, other.Name)
+			}
+		}
+		if containsString(trigger.Aliases, other.Name) {
+			merge = append(merge, other)
+		}
+	}
+
+	// A merged trigger's aliases keep resolving to the surviving trigger
+	for _, other := range merge {
+		for _, alias := range other.Aliases {
+			if alias != trigger.Name && !containsString(trigger.Aliases, alias) {
+				trigger.Aliases = append(trigger.Aliases, alias)
+			}
+		}
+	}
+
+	mergeIDs := make([]string, len(merge))
+	merged := make([]string, len(merge))
+	for i, other := range merge {
+		mergeIDs[i] = other.ID
+		merged[i] = other.Name
+	}
+	saved, err := s.db.SaveTrigger(trigger, create, mergeIDs)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return nil, fmt.Errorf("trigger %s no longer exists", trigger.ID)
+	}
+	*trigger = *saved
+	return merged, nil
+}
+
+// Report counts the emotions blamed on each trigger within [from, to) and
+// ranks the loops and threads those triggers mention by how many high-load
+// emotions they preceded. A loop or thread precedes an emotion when it was
+// created before the emotion was tagged and still open at the time.
+func (s *TriggerService) Report(from, to time.Time) (*models.TriggerReport, error) {
+	states, err := s.db.GetEmotionalStates(from, to, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.db.GetEmotionTriggerLinks(from, to)
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := s.db.GetTriggers()
+	if err != nil {
+		return nil, err
+	}
+	weights, err := s.emotions.LoadWeights()
+	if err != nil {
+		return nil, err
+	}
+	loops, err := s.db.GetLoopsOpenDuring(from, to)
+	if err != nil {
+		return nil, err
+	}
+	threads, err := s.db.GetThreadsActiveDuring(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.Trigger, len(triggers))
+	for _, trigger := range triggers {
+		byID[trigger.ID] = trigger
+	}
+
+	// Which loops and threads each trigger mentions
+	loopsFor := make(map[string][]int)
+	threadsFor := make(map[string][]int)
+	for _, trigger := range triggers {
+		for i, loop := range loops {
+			if TriggerMatches(trigger, TriggerWords(loop.Description)) {
+				loopsFor[trigger.ID] = append(loopsFor[trigger.ID], i)
+			}
+		}
+		for i, thread := range threads {
+			if TriggerMatches(trigger, TriggerWords(thread.Name+" "+thread.Goal)) {
+				threadsFor[trigger.ID] = append(threadsFor[trigger.ID], i)
+			}
+		}
+	}
+
+	report := &models.TriggerReport{
+		From:     from,
+		To:       to,
+		Emotions: len(states),
+		Triggers: []models.TriggerStats{},
+		Loops:    []models.TriggerLink{},
+		Threads:  []models.TriggerLink{},
+	}
+	stats := make(map[string]*models.TriggerStats)
+	loopLinks := make(map[int]*models.TriggerLink)
+	threadLinks := make(map[int]*models.TriggerLink)
+
+	for _, state := range states {
+		high := EmotionLoadClass(weights[state.Label]) == models.LoadLevelHigh
+		if high {
+			report.HighLoad++
+		}
+		loopsSeen := make(map[int]bool)
+		threadsSeen := make(map[int]bool)
+		for _, triggerID := range links[state.ID] {
+			trigger, ok := byID[triggerID]
+			if !ok {
+				continue
+			}
+			st, ok := stats[triggerID]
+			if !ok {
+				st = &models.TriggerStats{TriggerID: trigger.ID, Name: trigger.Name, Kind: trigger.Kind}
+				stats[triggerID] = st
+			}
+			st.Tags++
+			if st.LastTaggedAt == nil || state.CreatedAt.After(*st.LastTaggedAt) {
+				at := state.CreatedAt
+				st.LastTaggedAt = &at
+			}
+			if !high {
+				continue
+			}
+			st.HighLoadTags++
+
+			for _, i := range loopsFor[triggerID] {
+				loop := loops[i]
+				if loop.CreatedAt.After(state.CreatedAt) || (loop.ClosedAt != nil && loop.ClosedAt.Before(state.CreatedAt)) {
+					continue
+				}
+				link, ok := loopLinks[i]
+				if !ok {
+					link = &models.TriggerLink{ID: loop.ID, Text: loop.Description, Status: loop.Status, Triggers: []string{}}
+					loopLinks[i] = link
+				}
+				if !loopsSeen[i] {
+					loopsSeen[i] = true
+					link.HighLoadEmotions++
+				}
+				if !containsString(link.Triggers, trigger.Name) {
+					link.Triggers = append(link.Triggers, trigger.Name)
+				}
+			}
+			for _, i := range threadsFor[triggerID] {
+				thread := threads[i]
+				if thread.CreatedAt.After(state.CreatedAt) || (thread.Status != "active" && thread.UpdatedAt.Before(state.CreatedAt)) {
+					continue
+				}
+				link, ok := threadLinks[i]
+				if !ok {
+					link = &models.TriggerLink{ID: thread.ID, Text: thread.Name, Status: thread.Status, Triggers: []string{}}
+					threadLinks[i] = link
+				}
+				if !threadsSeen[i] {
+					threadsSeen[i] = true
+					link.HighLoadEmotions++
+				}
+				if !containsString(link.Triggers, trigger.Name) {
+					link.Triggers = append(link.Triggers, trigger.Name)
+				}
+			}
+		}
+	}
+
+	for _, st := range stats {
+		report.Triggers = append(report.Triggers, *st)
+	}
+	sort.Slice(report.Triggers, func(i, j int) bool {
+		a, b := report.Triggers[i], report.Triggers[j]
+		if a.HighLoadTags != b.HighLoadTags {
+			return a.HighLoadTags > b.HighLoadTags
+		}
+		if a.Tags != b.Tags {
+			return a.Tags > b.Tags
+		}
+		return a.Name < b.Name
+	})
+	report.Loops = sortTriggerLinks(loopLinks)
+	report.Threads = sortTriggerLinks(threadLinks)
+	return report, nil
+}
+
+// sourcePart is one blamed thing within a source guess
+type sourcePart struct {
+	text      string
+	afterWith bool
+}
+
+// splitSource splits a source guess on commas, "and", "with", "about" and
+// similar separators
+func splitSource(source string) []sourcePart {
+	var parts []sourcePart
+	afterWith := false
+	last := 0
+	for _, m := range sourceSeparator.FindAllStringSubmatchIndex(source, -1) {
+		if text := strings.TrimSpace(source[last:m[0]]); text != "" {
+			parts = append(parts, sourcePart{text: text, afterWith: afterWith})
+		}
+		afterWith = strings.EqualFold(source[m[2]:m[3]], "with")
+		last = m[1]
+	}
+	if text := strings.TrimSpace(source[last:]); text != "" {
+		parts = append(parts, sourcePart{text: text, afterWith: afterWith})
+	}
+	return parts
+}
+
+// guessKind classifies a new trigger from its source guess part
+func (s *TriggerService) guessKind(part sourcePart, name string) (models.TriggerKind, error) {
+	if part.afterWith || strings.HasPrefix(strings.TrimSpace(part.text), "@") {
+		return models.TriggerKindPerson, nil
+	}
+	words := TriggerWords(name)
+	for _, word := range words {
+		if personWords[word] {
+			return models.TriggerKindPerson, nil
+		}
+	}
+
+	candidate := models.Trigger{Name: name}
+	loops, err := s.db.GetOpenLoops()
+	if err != nil {
+		return "", err
+	}
+	for _, loop := range loops {
+		if TriggerMatches(candidate, TriggerWords(loop.Description)) {
+			return models.TriggerKindProject, nil
+		}
+	}
+	threads, err := s.db.GetActiveThreads()
+	if err != nil {
+		return "", err
+	}
+	for _, thread := range threads {
+		if TriggerMatches(candidate, TriggerWords(thread.Name+" "+thread.Goal)) {
+			return models.TriggerKindProject, nil
+		}
+	}
+	return models.TriggerKindContext, nil
+}
+
+// TriggerWords lowercases text and splits it into words, dropping punctuation
+func TriggerWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NormalizeTriggerName reduces a source guess part to a trigger name:
+// lower case, no punctuation and no leading articles or possessives
+func NormalizeTriggerName(text string) string {
+	words := TriggerWords(text)
+	for len(words) > 0 && triggerFillerWords[words[0]] {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// NormalizeTriggerAliases normalizes each alias, dropping empty ones,
+// duplicates and any that match the trigger's name
+func NormalizeTriggerAliases(name string, aliases []string) []string {
+	normalized := []string{}
+	for _, alias := range aliases {
+		alias = NormalizeTriggerName(alias)
+		if alias != "" && alias != name && !containsString(normalized, alias) {
+			normalized = append(normalized, alias)
+		}
+	}
+	return normalized
+}
+
+// TriggerMatches reports whether the trigger's name or one of its aliases
+// appears as a whole phrase within words
+func TriggerMatches(trigger models.Trigger, words []string) bool {
+	for _, name := range append([]string{trigger.Name}, trigger.Aliases...) {
+		if containsPhrase(words, TriggerWords(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPhrase reports whether phrase occurs as consecutive words in words
+func containsPhrase(words, phrase []string) bool {
+	if len(phrase) == 0 {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, word := range phrase {
+			if words[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sortTriggerLinks orders linked loops or threads by how many high-load
+// emotions they preceded
+func sortTriggerLinks(links map[int]*models.TriggerLink) []models.TriggerLink {
+	sorted := make([]models.TriggerLink, 0, len(links))
+	for _, link := range links {
+		sorted = append(sorted, *link)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].HighLoadEmotions != sorted[j].HighLoadEmotions {
+			return sorted[i].HighLoadEmotions > sorted[j].HighLoadEmotions
+		}
+		return sorted[i].Text < sorted[j].Text
+	})
+	return sorted
+}
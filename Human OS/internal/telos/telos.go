@@ -0,0 +1,163 @@
+// Package telos reads Telos personal context files. A Telos file is markdown
+// with one "## SECTION" heading per part of the Problems -> Mission -> Goals
+// -> Challenges -> Strategies -> Projects chain, each listing items such as
+// "- G1: Ship the beta by July". Items are identified by their prefix so work
+// can be traced back up the chain.
+package telos
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// telosItem matches a list item with an ID: "- G1: text"
+var telosItem = regexp.MustCompile(`^[-*]\s+([A-Za-z]+\d+)\s*:\s*(.+)$`)
+
+// telosRef matches an ID mentioned in an item's text, e.g. "(M1)"
+var telosRef = regexp.MustCompile(`\b[A-Z]+\d+\b`)
+
+// Chain is the order of the core sections, from why to what. Work on an
+// item serves the items in the sections above it.
+var Chain = []string{"PROBLEMS", "MISSION", "GOALS", "CHALLENGES", "STRATEGIES", "PROJECTS"}
+
+// Item is one identified entry in a Telos file
+type Item struct {
+	ID      string // e.g. "G1"
+	Section string // upper-case heading the item appears under, e.g. "GOALS"
+	Text    string
+	Refs    []string // IDs of other items in the file that the text names
+}
+
+// Document is a parsed Telos file. Items are kept in file order; list
+// entries without an ID, such as HISTORY, are skipped.
+type Document struct {
+	Items []Item
+}
+
+// Parse reads a Telos document. YAML front matter and prose are ignored. An
+// ID used by more than one item is an error naming both lines.
+func Parse(r io.Reader) (*Document, error) {
+	doc := &Document{}
+	section := ""
+	inFrontMatter := false
+	seen := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 0; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "---" && (lineNo == 0 || inFrontMatter) {
+			inFrontMatter = !inFrontMatter
+			continue
+		}
+		if inFrontMatter {
+			continue
+		}
+
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			section = strings.ToUpper(strings.TrimSpace(heading))
+			continue
+		}
+		if match := telosItem.FindStringSubmatch(line); match != nil && section != "" {
+			id := strings.ToUpper(match[1])
+			if first, ok := seen[id]; ok {
+				return nil, fmt.Errorf("line %d: %s is already used on line %d", lineNo+1, id, first)
+			}
+			seen[id] = lineNo + 1
+			doc.Items = append(doc.Items, Item{
+				ID:      id,
+				Section: section,
+				Text:    strings.TrimSpace(match[2]),
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// References can point forward, so they are resolved once every ID is known
+	known := make(map[string]bool, len(doc.Items))
+	for _, item := range doc.Items {
+		known[item.ID] = true
+	}
+	for i := range doc.Items {
+		for _, ref := range telosRef.FindAllString(doc.Items[i].Text, -1) {
+			if known[ref] && ref != doc.Items[i].ID && !slices.Contains(doc.Items[i].Refs, ref) {
+				doc.Items[i].Refs = append(doc.Items[i].Refs, ref)
+			}
+		}
+	}
+	return doc, nil
+}
+
+// ParseFile reads the Telos document at path
+func ParseFile(path string) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Item returns the item with an ID, or nil
+func (d *Document) Item(id string) *Item {
+	for i := range d.Items {
+		if d.Items[i].ID == strings.ToUpper(id) {
+			return &d.Items[i]
+		}
+	}
+	return nil
+}
+
+// Trace walks an item up the chain to the problems it ultimately serves,
+// returning the items above it, nearest section first. At each step the
+// parents are the items in the section above that it names, or every item
+// in that section if it names none - most files have a single mission that
+// every goal serves. Items outside the chain have no trace.
+func (d *Document) Trace(id string) []Item {
+	item := d.Item(id)
+	if item == nil {
+		return nil
+	}
+	level := slices.Index(Chain, item.Section)
+	if level < 0 {
+		return nil
+	}
+
+	var trace []Item
+	current := []Item{*item}
+	for level--; level >= 0; level-- {
+		section := d.Section(Chain[level])
+		var parents []Item
+		for _, parent := range section {
+			for _, child := range current {
+				if slices.Contains(child.Refs, parent.ID) {
+					parents = append(parents, parent)
+					break
+				}
+			}
+		}
+		if len(parents) == 0 {
+			parents = section
+		}
+		trace = append(trace, parents...)
+		current = parents
+	}
+	return trace
+}
+
+// Section returns the items under a heading, in file order
+func (d *Document) Section(name string) []Item {
+	var items []Item
+	for _, item := range d.Items {
+		if item.Section == strings.ToUpper(name) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
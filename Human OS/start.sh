@@ -109,7 +109,7 @@ echo ""
 # Start backend
 echo -e "${BLUE}Starting backend server...${NC}"
 cd "$SCRIPT_DIR"
-go run cmd/api/main.go &
+go run -tags sqlite_fts5 cmd/api/main.go &
 BACKEND_PID=$!
 echo -e "${GREEN}✓ Backend started (PID: $BACKEND_PID) on http://localhost:8080${NC}"
 